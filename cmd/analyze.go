@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/git"
+	"auto-pr/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Generate a PR description from a diff",
+	Long: `Generate a PR/MR title and description from a unified diff without
+touching a live repository. The diff can be piped in or read from a file:
+
+  git diff main | auto-pr analyze --stdin
+  auto-pr analyze --file changes.diff`,
+	RunE: runAnalyze,
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+
+	analyzeCmd.Flags().Bool("stdin", false, "Read the diff from standard input")
+	analyzeCmd.Flags().String("file", "", "Read the diff from a file")
+	analyzeCmd.Flags().String("preview-format", service.PreviewFormatPlain, "Preview format: plain or markdown")
+}
+
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	useStdin, _ := cmd.Flags().GetBool("stdin")
+	diffFile, _ := cmd.Flags().GetString("file")
+	previewFormat, _ := cmd.Flags().GetString("preview-format")
+	if err := service.ValidatePreviewFormat(previewFormat); err != nil {
+		return err
+	}
+
+	var diff []byte
+	var err error
+	switch {
+	case useStdin:
+		diff, err = io.ReadAll(os.Stdin)
+	case diffFile != "":
+		diff, err = os.ReadFile(diffFile)
+	default:
+		return fmt.Errorf("no diff provided. Use --stdin or --file")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read diff: %w", err)
+	}
+
+	fileChanges := git.ParseUnifiedDiff(string(diff))
+	if len(fileChanges) == 0 {
+		return fmt.Errorf("no file changes found in diff")
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Create AI client
+	aiClient, err := ai.NewClient(cfg.AI)
+	if err != nil {
+		return fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	additions, deletions := 0, 0
+	for _, fc := range fileChanges {
+		additions += fc.Additions
+		deletions += fc.Deletions
+	}
+
+	aiContext := &ai.AIContext{
+		DiffSummary: fmt.Sprintf("%d files changed, %d additions, %d deletions",
+			len(fileChanges), additions, deletions),
+		DiffContent: git.TruncateDiff(string(diff), cfg.Git.MaxDiffSize),
+		FileChanges: fileChanges,
+	}
+
+	prompt := "Generate a comprehensive pull request title and description based on the provided diff."
+	aiResponse, err := aiClient.GenerateContent(aiContext, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to generate AI content: %w", err)
+	}
+
+	return service.PrintPRPreview(os.Stdout, aiResponse, previewFormat)
+}
@@ -2,13 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
-	"strings"
 
-	"auto-pr/internal/ai"
-	"auto-pr/internal/config"
-	"auto-pr/internal/git"
-	"auto-pr/pkg/types"
+	"auto-pr/internal/service"
 
 	"github.com/spf13/cobra"
 )
@@ -26,202 +21,99 @@ func init() {
 	
 	commitCmd.Flags().BoolP("all", "a", false, "Stage all changes before committing")
 	commitCmd.Flags().StringP("message", "m", "", "Custom commit message (skips AI generation)")
-	commitCmd.Flags().Bool("amend", false, "Amend the last commit")
-	commitCmd.Flags().Bool("push", false, "Push after committing")
+	commitCmd.Flags().Bool("amend", false, "Amend the last commit, refining its message with AI unless -m is given")
+	commitCmd.Flags().Bool("keep-subject", false, "With --amend, keep the subject and regenerate only the body")
+	commitCmd.Flags().Bool("push", false, "Push after committing (default from git.auto_push, otherwise off)")
+	commitCmd.Flags().Bool("no-push", false, "Don't push, even when git.auto_push is true")
+	commitCmd.Flags().Bool("force", false, "Allow committing and pushing on a protected branch (git.protected_branches)")
+	commitCmd.Flags().StringArray("co-author", []string{}, "Add a Co-authored-by trailer (\"Name <email>\"), repeatable")
+	commitCmd.Flags().Bool("detect-co-authors", false, "Add co-authors who recently changed the staged files")
+	commitCmd.Flags().BoolP("interactive", "i", false, "Pick co-authors among the people who changed the staged files in the last two weeks")
+	commitCmd.Flags().Bool("detailed", false, "Generate a commit body explaining why, not just a subject")
+	commitCmd.Flags().Bool("no-stat", false, "Skip the per-file line counts, for speed in very large repositories")
+	commitCmd.Flags().String("type", "", "Conventional commit type for the generated message (feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert)")
+	commitCmd.Flags().BoolP("edit", "e", false, "Open the commit message in $EDITOR before committing")
+	commitCmd.Flags().Bool("wip", false, "Stage all and commit a timestamped \"wip: checkpoint\" without AI, to fold in later with --amend")
+	commitCmd.Flags().String("hook", "", "Write the message to this file instead of committing, for a prepare-commit-msg hook")
+	commitCmd.Flags().Bool("per-file", false, "Make one commit per changed file, each with its own generated message")
+	commitCmd.Flags().Bool("group-by-dir", false, "Make one commit per top-level directory, each with its own generated message")
+	commitCmd.Flags().BoolP("quiet", "q", false, "Print only the commit hash")
 }
 
 func runCommit(cmd *cobra.Command, args []string) error {
-	// Initialize git analyzer
-	gitAnalyzer, err := git.NewAnalyzer(".")
-	if err != nil {
-		return fmt.Errorf("failed to initialize git analyzer: %w", err)
-	}
-
-	if !gitAnalyzer.IsGitRepository() {
-		return fmt.Errorf("not in a git repository")
-	}
-
 	// Get flags
 	stageAll, _ := cmd.Flags().GetBool("all")
 	customMessage, _ := cmd.Flags().GetString("message")
 	amend, _ := cmd.Flags().GetBool("amend")
+	keepSubject, _ := cmd.Flags().GetBool("keep-subject")
 	pushAfter, _ := cmd.Flags().GetBool("push")
+	noPush, _ := cmd.Flags().GetBool("no-push")
+	force, _ := cmd.Flags().GetBool("force")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-
-	// Get repository status first
-	status, err := gitAnalyzer.GetStatus()
+	coAuthors, _ := cmd.Flags().GetStringArray("co-author")
+	detectCoAuthors, _ := cmd.Flags().GetBool("detect-co-authors")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	detailed, _ := cmd.Flags().GetBool("detailed")
+	commitType, _ := cmd.Flags().GetString("type")
+	noStat, _ := cmd.Flags().GetBool("no-stat")
+	edit, _ := cmd.Flags().GetBool("edit")
+	hookFile, _ := cmd.Flags().GetString("hook")
+	wip, _ := cmd.Flags().GetBool("wip")
+	perFile, _ := cmd.Flags().GetBool("per-file")
+	groupByDir, _ := cmd.Flags().GetBool("group-by-dir")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	group := ""
+	switch {
+	case perFile && groupByDir:
+		return fmt.Errorf("--per-file can't be combined with --group-by-dir")
+	case perFile:
+		group = service.CommitGroupFile
+	case groupByDir:
+		group = service.CommitGroupDir
+	}
+
+	if quiet && interactive {
+		return fmt.Errorf("--quiet can't be combined with --interactive")
+	}
+
+	out, err := quietOutput(quiet, dryRun)
+	if err != nil {
+		return err
+	}
+
+	result, err := service.Commit(service.CommitOptions{
+		StageAll:        stageAll,
+		Message:         customMessage,
+		Amend:           amend,
+		KeepSubject:     keepSubject,
+		Push:            pushAfter,
+		NoPush:          noPush,
+		Force:           force,
+		CoAuthors:       coAuthors,
+		DetectCoAuthors: detectCoAuthors,
+		Interactive:     interactive,
+		Detailed:        detailed,
+		Type:            commitType,
+		NoStat:          noStat,
+		Edit:            edit,
+		HookFile:        hookFile,
+		WIP:             wip,
+		Group:           group,
+		DryRun:          dryRun,
+		Out:             out,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get repository status: %w", err)
+		return err
 	}
-
-	// Stage files if requested
-	if stageAll {
-		if dryRun {
-			fmt.Printf("🔄 Would stage %d unstaged and %d untracked files\n", 
-				len(status.UnstagedFiles), len(status.UntrackedFiles))
-		} else {
-			fmt.Println("🔄 Staging all changes...")
-			if err := stageAllChanges(); err != nil {
-				return fmt.Errorf("failed to stage changes: %w", err)
+	if quiet && hookFile == "" {
+		if len(result.Commits) > 0 {
+			for _, commit := range result.Commits {
+				fmt.Println(commit.Hash)
 			}
-			// Refresh status after staging
-			status, err = gitAnalyzer.GetStatus()
-			if err != nil {
-				return fmt.Errorf("failed to get updated repository status: %w", err)
-			}
-			fmt.Println("✅ Changes staged")
-		}
-	}
-
-	if len(status.StagedFiles) == 0 && !amend && !stageAll {
-		return fmt.Errorf("no changes staged for commit. Use --all to stage all changes")
-	}
-
-	var commitMessage string
-	
-	if customMessage != "" {
-		commitMessage = customMessage
-	} else {
-		fmt.Println("🤖 Generating commit message with AI...")
-		
-		// Generate AI commit message
-		commitMessage, err = generateCommitMessage(gitAnalyzer, status)
-		if err != nil {
-			return fmt.Errorf("failed to generate commit message: %w", err)
-		}
-	}
-
-	fmt.Printf("📝 Commit message:\n%s\n\n", commitMessage)
-
-	if dryRun {
-		fmt.Println("🔍 Dry run - would commit with above message")
-		return nil
-	}
-
-	// Create the commit
-	fmt.Println("💾 Creating commit...")
-	if err := createCommit(commitMessage, amend); err != nil {
-		return fmt.Errorf("failed to create commit: %w", err)
-	}
-
-	fmt.Println("✅ Commit created successfully!")
-
-	// Push if requested
-	if pushAfter {
-		fmt.Println("🚀 Pushing to remote...")
-		if err := pushChanges(); err != nil {
-			return fmt.Errorf("failed to push: %w", err)
+		} else {
+			fmt.Println(result.Hash)
 		}
-		fmt.Println("✅ Changes pushed!")
 	}
-
 	return nil
 }
-
-func stageAllChanges() error {
-	cmd := exec.Command("git", "add", ".")
-	return cmd.Run()
-}
-
-func createCommit(message string, amend bool) error {
-	args := []string{"commit", "-m", message}
-	if amend {
-		args = []string{"commit", "--amend", "-m", message}
-	}
-	
-	cmd := exec.Command("git", args...)
-	return cmd.Run()
-}
-
-func pushChanges() error {
-	// First try regular push
-	cmd := exec.Command("git", "push")
-	if err := cmd.Run(); err != nil {
-		// If that fails, try push with set-upstream for new branches
-		cmd = exec.Command("git", "push", "--set-upstream", "origin", "HEAD")
-		return cmd.Run()
-	}
-	return nil
-}
-
-func generateCommitMessage(gitAnalyzer *git.Analyzer, status *types.GitStatus) (string, error) {
-	// Load configuration
-	cfg, err := config.LoadConfigWithViper()
-	if err != nil {
-		return "", fmt.Errorf("failed to load config: %w", err)
-	}
-
-	// Create AI client
-	client, err := ai.NewClient(cfg.AI)
-	if err != nil {
-		return "", fmt.Errorf("failed to create AI client: %w", err)
-	}
-
-	// Get diff for staged files
-	diffSummary, err := getStagedDiff()
-	if err != nil {
-		return "", fmt.Errorf("failed to get diff: %w", err)
-	}
-
-	// Build AI context
-	context := &ai.AIContext{
-		DiffSummary: diffSummary,
-		FileChanges: buildFileChanges(status),
-		BranchInfo: types.BranchInfo{
-			Name:       status.CurrentBranch,
-			BaseBranch: status.BaseBranch,
-		},
-	}
-
-	// Generate commit message
-	prompt := `Generate a concise, clear commit message for these changes.
-
-Rules:
-- Use conventional commit format (feat:, fix:, docs:, refactor:, etc.)
-- First line should be 50 characters or less
-- Be specific about what changed
-- Don't include explanations, just the action
-
-Example formats:
-- feat: add user authentication
-- fix: resolve memory leak in parser
-- docs: update API documentation
-- refactor: simplify error handling
-
-Focus on WHAT changed, not HOW or WHY.`
-
-	response, err := client.GenerateContent(context, prompt)
-	if err != nil {
-		return "", fmt.Errorf("AI generation failed: %w", err)
-	}
-
-	// Extract just the commit message (first line of the response)
-	lines := strings.Split(strings.TrimSpace(response.Title), "\n")
-	if len(lines) > 0 {
-		return strings.TrimSpace(lines[0]), nil
-	}
-
-	return response.Title, nil
-}
-
-func getStagedDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--stat")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return string(output), nil
-}
-
-func buildFileChanges(status *types.GitStatus) []types.FileChange {
-	var changes []types.FileChange
-	
-	for _, file := range status.StagedFiles {
-		changes = append(changes, types.FileChange{
-			Path:   file,
-			Status: types.StatusModified, // Simplified for now
-		})
-	}
-	
-	return changes
-}
\ No newline at end of file
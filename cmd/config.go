@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"auto-pr/internal/config"
+	"auto-pr/internal/ui"
 	"auto-pr/pkg/types"
 
 	"github.com/spf13/cobra"
@@ -56,11 +57,21 @@ var configValidateCmd = &cobra.Command{
 	RunE:  runConfigValidate,
 }
 
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade configuration to the current format",
+	Long: `Rewrite the configuration file in the current format: replace removed
+providers, drop settings that are no longer used and fill in new defaults.
+The original file is kept with a .bak suffix.`,
+	RunE: runConfigMigrate,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
-	configCmd.AddCommand(configInitCmd, configSetCmd, configGetCmd, configListCmd, configValidateCmd)
+	configCmd.AddCommand(configInitCmd, configSetCmd, configGetCmd, configListCmd, configValidateCmd, configMigrateCmd)
 
 	configInitCmd.Flags().Bool("force", false, "Overwrite existing configuration")
+	configValidateCmd.Flags().Bool("strict", false, "Fail on unknown configuration keys")
 }
 
 func runConfigInit(cmd *cobra.Command, args []string) error {
@@ -172,7 +183,44 @@ func runConfigValidate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Println("Configuration is valid ✓")
+	// Misspelled keys are ignored by the loader, so point them out here
+	unknown, err := config.FindUnknownKeys(getConfigPath())
+	if err != nil {
+		return err
+	}
+	for _, key := range unknown {
+		fmt.Printf("%s Unknown configuration key: %s\n", ui.Warning, key)
+	}
+	if strict, _ := cmd.Flags().GetBool("strict"); strict && len(unknown) > 0 {
+		return fmt.Errorf("configuration has %d unknown key(s)", len(unknown))
+	}
+
+	fmt.Printf("Configuration is valid %s\n", ui.Check)
+	return nil
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	configPath := getConfigPath()
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return fmt.Errorf("configuration file not found. Run 'auto-pr config init' to create it")
+	}
+
+	result, err := config.MigrateConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to migrate configuration: %w", err)
+	}
+
+	if result.BackupPath == "" {
+		fmt.Printf("Configuration is already up to date (version %d) %s\n", result.ToVersion, ui.Check)
+		return nil
+	}
+
+	fmt.Printf("%s Migrated %s from version %d to %d\n", ui.Success, configPath, result.FromVersion, result.ToVersion)
+	for _, note := range result.Notes {
+		fmt.Printf("   - %s\n", note)
+	}
+	fmt.Printf("%s Original saved as %s\n", ui.Note, result.BackupPath)
 	return nil
 }
 
@@ -182,12 +230,12 @@ func getConfigPath() string {
 		return cfgFile
 	}
 
-	home, err := os.UserHomeDir()
+	configPath, err := config.DefaultConfigPath()
 	if err != nil {
 		return ".auto-pr/config.yaml"
 	}
 
-	return filepath.Join(home, ".auto-pr", "config.yaml")
+	return configPath
 }
 
 // loadConfig loads the configuration file
@@ -205,10 +253,12 @@ func loadConfig() error {
 // getDefaultConfig returns default configuration
 func getDefaultConfig() *types.Config {
 	return &types.Config{
+		Version: config.CurrentConfigVersion,
 		AI: types.AIConfig{
-			Provider:    types.AIProviderClaude,
-			MaxTokens:   4096,
-			Temperature: 0.7,
+			Provider:         types.AIProviderClaude,
+			MaxTokens:        4096,
+			Temperature:      0.7,
+			RefineIterations: 1,
 			Claude: types.ClaudeConfig{
 				CLIPath:    "claude",
 				Model:      "claude-3-5-sonnet-20241022",
@@ -229,6 +279,14 @@ func getDefaultConfig() *types.Config {
 				MergeWhenPipelineSucceeds: false,
 				RemoveSourceBranch:        true,
 			},
+			Labels: types.LabelConfig{
+				SizeThresholds: []types.SizeThreshold{
+					{Label: "size/S", Below: 50},
+					{Label: "size/M", Below: 250},
+					{Label: "size/L"},
+				},
+			},
+			TitleMaxLength: 72,
 		},
 		Templates: types.TemplateConfig{
 			Feature:           "feature-template",
@@ -236,10 +294,13 @@ func getDefaultConfig() *types.Config {
 			CustomTemplateDir: "~/.auto-pr/templates",
 		},
 		Git: types.GitConfig{
-			CommitLimit:    10,
-			DiffContext:    3,
-			IgnorePatterns: []string{"*.log", "node_modules/", "*.tmp"},
-			MaxDiffSize:    10000,
+			CommitLimit:            10,
+			DiffContext:            3,
+			IgnorePatterns:         []string{"*.log", "node_modules/", "*.tmp"},
+			MaxDiffSize:            10000,
+			ProtectedBranches:      []string{"main", "master", "release/*"},
+			BaseBranchCandidates:   []string{"main", "master", "develop"},
+			ContextExcludePatterns: []string{"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock", "Gemfile.lock", "poetry.lock", "composer.lock", "Pipfile.lock"},
 		},
 	}
 }
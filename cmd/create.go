@@ -3,13 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
-	"auto-pr/internal/ai"
-	"auto-pr/internal/config"
-	"auto-pr/internal/git"
-	"auto-pr/internal/platforms"
-	"auto-pr/internal/templates"
-	"auto-pr/pkg/types"
+	"auto-pr/internal/service"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,14 +23,46 @@ with AI-generated title, description, and appropriate metadata.`,
 func init() {
 	rootCmd.AddCommand(createCmd)
 
-	createCmd.Flags().Bool("interactive", false, "Interactive mode with confirmation")
+	createCmd.Flags().Bool("interactive", false, "Pick labels and reviewers from the repository and confirm before creating")
 	createCmd.Flags().String("template", "", "Use specific template")
+	createCmd.Flags().String("title", "", "Use this title instead of the AI-generated one (the body is still generated)")
+	createCmd.Flags().String("title-prefix", "", "Prefix the title, e.g. with a ticket key like [JIRA-123]")
+	createCmd.Flags().String("issue", "", "Issue the PR/MR addresses (123, #123 or its URL); its description is given to the AI as requirements")
+	createCmd.Flags().Bool("use-repo-template", false, "Fill the repository's own PR/MR template (e.g. .github/PULL_REQUEST_TEMPLATE.md)")
 	createCmd.Flags().StringSlice("reviewer", []string{}, "Override default reviewers")
 	createCmd.Flags().Bool("draft", false, "Create as draft")
 	createCmd.Flags().Bool("auto-merge", false, "Enable auto-merge")
 	createCmd.Flags().Bool("force", false, "Skip validations")
 	createCmd.Flags().String("commit-range", "", "Specific commit range")
 	createCmd.Flags().String("ai-context", "", "Additional context file")
+	createCmd.Flags().Bool("require-passing-ci", false, "Refuse to create a GitLab MR when the branch pipeline is failing")
+	createCmd.Flags().Bool("require-passing-checks", false, "Refuse to create a GitHub PR when the head commit has failing checks")
+	createCmd.Flags().Int("max-commits", 0, "Maximum number of recent commits fed to the AI (0 means unlimited, default from git.commit_limit)")
+	createCmd.Flags().String("head", "", "Local branch to describe and open the PR/MR from instead of the checked-out one, or owner:branch for a fork")
+	createCmd.Flags().String("upstream", "", "Remote whose repository the PR/MR targets (e.g. upstream)")
+	createCmd.Flags().Bool("push", false, "Push the branch and retry if it hasn't been pushed yet, without asking")
+	createCmd.Flags().Bool("auto-login", false, "Offer to run gh/glab auth login when not authenticated, then retry")
+	createCmd.Flags().Bool("no-stat", false, "Skip the per-file line counts, for speed in very large repositories")
+	createCmd.Flags().Bool("include-generated", false, "Keep files marked linguist-generated in .gitattributes in the AI context")
+	createCmd.Flags().StringArray("path", []string{}, "Limit the diff and commits analyzed to this path, repeatable (e.g. a monorepo subproject)")
+	createCmd.Flags().Bool("suggest-reviewers", false, "Suggest reviewers from CODEOWNERS or recent authors of the changed files instead of the AI")
+	createCmd.Flags().Bool("reviewers-from-codeowners", false, "Add the CODEOWNERS owners of the changed files as reviewers (default from platforms.<platform>.use_codeowners)")
+	createCmd.Flags().String("preview-format", service.PreviewFormatPlain, "Dry-run preview format: plain or markdown")
+	createCmd.Flags().Bool("amend-pr", false, "Append a summary of new commits to the existing PR/MR description")
+	createCmd.Flags().Bool("post-details", false, "Post the full file list, and any part of a too-long description, as the first comment")
+	createCmd.Flags().String("since-tag", "", "Describe everything since the latest tag, optionally matching a pattern (e.g. --since-tag='v*'), as release notes")
+	// A bare --since-tag takes the latest tag of any name
+	createCmd.Flags().Lookup("since-tag").NoOptDefVal = "*"
+	createCmd.Flags().Bool("split", false, "Suggest how to split the branch into smaller PRs/MRs instead of creating one")
+	createCmd.Flags().Bool("no-ai", false, "Describe the changes from the commit messages and changed files without calling the AI")
+	createCmd.Flags().Bool("fill", false, "Use the first commit's subject as the title and the commit messages as the body, like gh pr create --fill")
+	createCmd.Flags().Bool("refine", false, "Have the AI critique and improve its first draft, up to ai.refine_iterations times (slower)")
+	createCmd.Flags().Bool("sync-metadata", false, "When a PR/MR already exists, add any missing labels and reviewers to it")
+	createCmd.Flags().Bool("recreate", false, "When a PR/MR already exists, close it and create a new one (its comment thread is left behind)")
+	createCmd.Flags().BoolP("yes", "y", false, "Recreate without asking for confirmation")
+	createCmd.Flags().Bool("stacked", false, "Target the branch this one is stacked on, found from its fork point, instead of the base branch")
+	createCmd.Flags().Bool("chain", false, "Also create PRs/MRs for the branches below this one in the stack, bottom first (implies --stacked)")
+	createCmd.Flags().BoolP("quiet", "q", false, "Print only the PR/MR URL")
 
 	if err := viper.BindPFlags(createCmd.Flags()); err != nil {
 		fmt.Fprintf(os.Stderr, "error: failed to bind create flags: %v\n", err)
@@ -43,241 +71,78 @@ func init() {
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
-	verbose := viper.GetBool("verbose")
-	dryRun := viper.GetBool("dry-run")
-
-	if verbose {
-		fmt.Println("Starting Auto PR creation...")
-	}
-
-	// Initialize git analyzer
-	gitAnalyzer, err := git.NewAnalyzer(".")
-	if err != nil {
-		return fmt.Errorf("failed to initialize git analyzer: %w", err)
-	}
-
-	// Check if we're in a git repository
-	if !gitAnalyzer.IsGitRepository() {
-		return fmt.Errorf("not in a git repository")
-	}
-
-	// Detect platform (GitHub/GitLab)
-	platform, err := platforms.DetectPlatform(gitAnalyzer.GetRemoteURL())
-	if err != nil {
-		return fmt.Errorf("failed to detect platform: %w", err)
-	}
-
-	if verbose {
-		fmt.Printf("Detected platform: %s\n", platform)
-	}
-
-	// Get repository status
-	status, err := gitAnalyzer.GetStatus()
-	if err != nil {
-		return fmt.Errorf("failed to get repository status: %w", err)
-	}
-
-	if verbose {
-		fmt.Printf("Repository status: %+v\n", status)
-	}
-
-	// Load configuration
-	cfg, err := config.LoadConfigWithViper()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	// Create AI client
-	aiClient, err := ai.NewClient(cfg.AI)
-	if err != nil {
-		return fmt.Errorf("failed to create AI client: %w", err)
-	}
-
-	if verbose {
-		fmt.Printf("Using AI provider: %s\n", aiClient.GetProvider())
-	}
-
-	// Get commit history and changes for AI context
-	commits, err := gitAnalyzer.GetCommitsSinceBase(status.BaseBranch)
-	if err != nil {
-		return fmt.Errorf("failed to get commit history: %w", err)
-	}
-
-	// Get diff summary
-	diffSummary, err := gitAnalyzer.GetBranchDiff(status.BaseBranch)
-	if err != nil {
-		return fmt.Errorf("failed to get diff summary: %w", err)
-	}
-
-	// Build AI context
-	aiContext := &ai.AIContext{
-		CommitHistory: commits,
-		DiffSummary: fmt.Sprintf("%d files changed, %d additions, %d deletions",
-			diffSummary.TotalFiles, diffSummary.Additions, diffSummary.Deletions),
-		FileChanges: diffSummary.FileChanges,
-		BranchInfo: types.BranchInfo{
-			Name:         status.CurrentBranch,
-			BaseBranch:   status.BaseBranch,
-			CommitsAhead: status.CommitsAhead,
-		},
-		Platform: platform,
-	}
-
-	if verbose {
-		fmt.Printf("AI Context: %d commits, %d file changes\n",
-			len(commits), len(diffSummary.FileChanges))
-	}
-
-	// Generate PR content using AI
-	prompt := "Generate a comprehensive pull request title and description based on the provided git changes and commit history."
-	aiResponse, err := aiClient.GenerateContent(aiContext, prompt)
+	// Splitting is advice only, so nothing below applies
+	if viper.GetBool("split") {
+		_, err := service.SuggestSplit(service.SplitOptions{
+			Paths:   viper.GetStringSlice("path"),
+			Verbose: viper.GetBool("verbose"),
+		})
+		return err
+	}
+
+	opts := service.CreatePROptions{
+		Template:             viper.GetString("template"),
+		UseRepoTemplate:      viper.GetBool("use-repo-template"),
+		Title:                viper.GetString("title"),
+		TitlePrefix:          viper.GetString("title-prefix"),
+		Issue:                viper.GetString("issue"),
+		Reviewers:            viper.GetStringSlice("reviewer"),
+		SuggestReviewers:     viper.GetBool("suggest-reviewers"),
+		CodeownerReviewers:   viper.GetBool("reviewers-from-codeowners"),
+		Draft:                viper.GetBool("draft"),
+		AutoMerge:            viper.GetBool("auto-merge"),
+		Head:                 viper.GetString("head"),
+		Upstream:             viper.GetString("upstream"),
+		Paths:                viper.GetStringSlice("path"),
+		IncludeGenerated:     viper.GetBool("include-generated"),
+		NoStat:               viper.GetBool("no-stat"),
+		AutoLogin:            viper.GetBool("auto-login"),
+		AmendPR:              viper.GetBool("amend-pr"),
+		Recreate:             viper.GetBool("recreate"),
+		Yes:                  viper.GetBool("yes"),
+		SyncMetadata:         viper.GetBool("sync-metadata"),
+		Stacked:              viper.GetBool("stacked"),
+		Chain:                viper.GetBool("chain"),
+		Interactive:          viper.GetBool("interactive"),
+		Push:                 viper.GetBool("push"),
+		Refine:               viper.GetBool("refine"),
+		PostDetails:          viper.GetBool("post-details"),
+		NoAI:                 viper.GetBool("no-ai"),
+		Fill:                 viper.GetBool("fill"),
+		SinceTag:             viper.GetString("since-tag"),
+		RequirePassingCI:     viper.GetBool("require-passing-ci"),
+		RequirePassingChecks: viper.GetBool("require-passing-checks"),
+		DryRun:               viper.GetBool("dry-run"),
+		PreviewFormat:        viper.GetString("preview-format"),
+		Verbose:              viper.GetBool("verbose"),
+	}
+
+	// --max-commits overrides git.commit_limit only when given (0 means unlimited)
+	if viper.IsSet("max-commits") {
+		maxCommits := viper.GetInt("max-commits")
+		opts.MaxCommits = &maxCommits
+	}
+
+	quiet := viper.GetBool("quiet")
+	if quiet && opts.Interactive {
+		return fmt.Errorf("--quiet can't be combined with --interactive")
+	}
+	out, err := quietOutput(quiet, opts.DryRun)
 	if err != nil {
-		return fmt.Errorf("failed to generate AI content: %w", err)
+		return err
 	}
-
-	if verbose {
-		fmt.Printf("AI generated content (confidence: %.2f)\n", aiResponse.Confidence)
+	opts.Out = out
+	if quiet {
+		// A prompt nobody sees would hang, so quiet runs decline to push
+		opts.In = strings.NewReader("")
 	}
 
-	// Apply template if specified
-	templateName := viper.GetString("template")
-	if templateName != "" {
-		templateManager := templates.NewManager()
-		enhanced, err := templates.EnhanceWithTemplate(templateManager, templateName, aiContext, aiResponse)
-		if err != nil {
-			if verbose {
-				fmt.Printf("Warning: failed to apply template '%s': %v\n", templateName, err)
-			}
-		} else {
-			aiResponse = enhanced
-			if verbose {
-				fmt.Printf("Applied template: %s\n", templateName)
-			}
-		}
-	} else {
-		// Auto-select template based on context
-		templateManager := templates.NewManager()
-		autoTemplate := templates.SelectTemplateByContext(aiContext)
-		if autoTemplate != "" {
-			enhanced, err := templates.EnhanceWithTemplate(templateManager, autoTemplate, aiContext, aiResponse)
-			if err == nil {
-				aiResponse = enhanced
-				if verbose {
-					fmt.Printf("Auto-selected template: %s\n", autoTemplate)
-				}
-			}
-		}
-	}
-
-	if dryRun {
-		fmt.Println("🔍 Dry Run - PR/MR Preview")
-		fmt.Println("==========================")
-		fmt.Printf("📝 Title: %s\n", aiResponse.Title)
-		fmt.Printf("📋 Body:\n%s\n", aiResponse.Body)
-		if len(aiResponse.Labels) > 0 {
-			fmt.Printf("🏷️  Labels: %v\n", aiResponse.Labels)
-		}
-		if len(aiResponse.Reviewers) > 0 {
-			fmt.Printf("👥 Suggested reviewers: %v\n", aiResponse.Reviewers)
-		}
-		fmt.Printf("⚡ Priority: %s\n", aiResponse.Priority)
-		fmt.Printf("🤖 Generated by: %s\n", aiResponse.Provider)
-		return nil
-	}
-
-	// Create platform client
-	var platformClient platforms.PlatformClient
-	switch platform {
-	case types.PlatformGitHub:
-		platformClient, err = platforms.NewGitHubClient(status.RemoteURL)
-	case types.PlatformGitLab:
-		platformClient, err = platforms.NewGitLabClient(status.RemoteURL)
-	default:
-		return fmt.Errorf("unsupported platform: %s", platform)
-	}
-
-	if err != nil {
-		return fmt.Errorf("failed to create platform client: %w", err)
-	}
-
-	// Check for existing PR/MR
-	existingPR, err := platformClient.GetExistingPR(status.CurrentBranch)
+	result, err := service.CreatePR(opts)
 	if err != nil {
-		if verbose {
-			fmt.Printf("Warning: failed to check for existing PR: %s\n", err)
-		}
+		return err
 	}
-
-	if existingPR != nil {
-		fmt.Printf("⚠️  A PR/MR already exists for branch '%s': %s\n",
-			status.CurrentBranch, existingPR.URL)
-		return nil
-	}
-
-	// Filter AI-suggested labels to only those that exist in the repository,
-	// so we don't attempt to apply a label that hasn't been created yet.
-	labels, err := platforms.FilterExistingLabels(platformClient, aiResponse.Labels)
-	if err != nil {
-		if verbose {
-			fmt.Printf("Warning: failed to verify labels, skipping: %v\n", err)
-		}
-		labels = []string{}
+	if quiet && result.PullRequest != nil {
+		fmt.Println(result.PullRequest.URL)
 	}
-
-	reviewers := aiResponse.Reviewers
-	if len(cfg.Platforms.GitHub.DefaultReviewers) > 0 && platform == types.PlatformGitHub {
-		reviewers = append(reviewers, cfg.Platforms.GitHub.DefaultReviewers...)
-	}
-
-	// Create PR request
-	prRequest := &types.PullRequestRequest{
-		Title:      aiResponse.Title,
-		Body:       aiResponse.Body,
-		HeadBranch: status.CurrentBranch,
-		BaseBranch: status.BaseBranch,
-		Draft:      viper.GetBool("draft"),
-		Labels:     removeDuplicates(labels),
-		Reviewers:  removeDuplicates(reviewers),
-		AutoMerge:  viper.GetBool("auto-merge"),
-	}
-
-	// Create the PR/MR
-	fmt.Println("🚀 Creating PR/MR...")
-	createdPR, err := platformClient.CreatePullRequest(prRequest)
-	if err != nil {
-		return fmt.Errorf("failed to create PR/MR: %w", err)
-	}
-
-	fmt.Printf("✅ Successfully created %s: %s\n",
-		getEntityName(platform), createdPR.URL)
-	fmt.Printf("📝 Title: %s\n", createdPR.Title)
-	if createdPR.Draft {
-		fmt.Println("📋 Status: Draft")
-	}
-
 	return nil
 }
-
-// Helper functions
-func removeDuplicates(slice []string) []string {
-	keys := make(map[string]bool)
-	var result []string
-	for _, item := range slice {
-		if !keys[item] {
-			keys[item] = true
-			result = append(result, item)
-		}
-	}
-	return result
-}
-
-func getEntityName(platform types.PlatformType) string {
-	switch platform {
-	case types.PlatformGitHub:
-		return "Pull Request"
-	case types.PlatformGitLab:
-		return "Merge Request"
-	default:
-		return "PR/MR"
-	}
-}
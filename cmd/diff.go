@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"auto-pr/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:     "diff",
+	Aliases: []string{"context"},
+	Short:   "Show the context the AI would receive",
+	Long: `Assemble the commits, file changes and diff summary that create sends to the
+AI and print them without calling any AI provider. Useful for finding out why a
+description is off and for tuning max_diff_size and ignore_patterns.`,
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().Bool("json", false, "Print the raw context as JSON")
+	diffCmd.Flags().Int("max-commits", 0, "Maximum number of recent commits included (0 means unlimited, default from git.commit_limit)")
+	diffCmd.Flags().StringArray("path", []string{}, "Limit the diff and commits to this path, repeatable")
+	diffCmd.Flags().Bool("include-generated", false, "Keep files marked linguist-generated in .gitattributes")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	paths, _ := cmd.Flags().GetStringArray("path")
+	includeGenerated, _ := cmd.Flags().GetBool("include-generated")
+
+	opts := service.ContextOptions{Paths: paths, IncludeGenerated: includeGenerated}
+	if cmd.Flags().Changed("max-commits") {
+		maxCommits, _ := cmd.Flags().GetInt("max-commits")
+		opts.MaxCommits = &maxCommits
+	}
+
+	aiContext, err := service.PRContext(opts)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(aiContext); err != nil {
+			return fmt.Errorf("failed to encode context: %w", err)
+		}
+		return nil
+	}
+
+	service.PrintAIContext(os.Stdout, aiContext)
+	return nil
+}
@@ -1,12 +1,32 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"auto-pr/internal/ai"
 	"auto-pr/internal/git"
+	"auto-pr/internal/platforms"
+	"auto-pr/internal/service"
+	"auto-pr/internal/templates"
+	"auto-pr/pkg/types"
 
 	"github.com/spf13/cobra"
 )
@@ -35,6 +55,11 @@ type MCPToolList struct {
 	Tools []MCPTool `json:"tools"`
 }
 
+type MCPToolCallParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
 type MCPTool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
@@ -51,48 +76,391 @@ var mcpCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(mcpCmd)
+
+	mcpCmd.Flags().String("transport", "stdio", "Transport to serve on (stdio|sse)")
+	mcpCmd.Flags().String("addr", "127.0.0.1:8080", "Listen address for the sse transport")
+	mcpCmd.Flags().String("token", "", "Bearer token sse clients must send, required off loopback (default $AUTO_PR_MCP_TOKEN)")
 }
 
 func runMCPServer(cmd *cobra.Command, args []string) error {
+	transport, _ := cmd.Flags().GetString("transport")
+	addr, _ := cmd.Flags().GetString("addr")
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("AUTO_PR_MCP_TOKEN")
+	}
+
 	// Initialize git analyzer
 	gitAnalyzer, err := git.NewAnalyzer(".")
 	if err != nil {
 		gitAnalyzer = nil // Allow MCP to work in non-git directories
 	}
+	repo := newMCPRepo(gitAnalyzer)
 
-	return runMCPLoop(gitAnalyzer)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch transport {
+	case "stdio":
+		return runMCPLoop(ctx, os.Stdin, os.Stdout, repo)
+	case "sse":
+		return runMCPSSEServer(ctx, addr, token, repo)
+	default:
+		return fmt.Errorf("unsupported MCP transport: %s (expected stdio or sse)", transport)
+	}
 }
 
-func runMCPLoop(gitAnalyzer *git.Analyzer) error {
-	decoder := json.NewDecoder(os.Stdin)
-	encoder := json.NewEncoder(os.Stdout)
+// mcpRepo is what the MCP tools work on: the repository the server runs in
+// and a client for the platform its remote is on. Outside a repository the
+// analyzer is nil; without a supported, reachable platform the client is nil
+// and platformErr says why.
+type mcpRepo struct {
+	gitAnalyzer    *git.Analyzer
+	remoteURL      string
+	platform       types.PlatformType
+	platformClient platforms.PlatformClient
+	platformErr    error
+}
 
+// newMCPRepo detects the platform of the repository's remote and creates its
+// client. A missing repository, remote or platform CLI isn't fatal: only the
+// tools that need the platform fail, with platformErr.
+func newMCPRepo(gitAnalyzer *git.Analyzer) *mcpRepo {
+	repo := &mcpRepo{gitAnalyzer: gitAnalyzer}
+	if gitAnalyzer == nil {
+		repo.platformErr = errors.New("not in a git repository")
+		return repo
+	}
+	repo.remoteURL = gitAnalyzer.GetRemoteURL()
+	if repo.remoteURL == "" {
+		repo.platformErr = errors.New("no remote repository configured")
+		return repo
+	}
+
+	platform, err := platforms.DetectPlatform(repo.remoteURL)
+	if err != nil {
+		repo.platformErr = err
+		return repo
+	}
+	repo.platform = platform
+
+	var client platforms.PlatformClient
+	switch platform {
+	case types.PlatformGitHub:
+		if client, err = platforms.NewGitHubClient(repo.remoteURL); err != nil {
+			client = nil
+		}
+	case types.PlatformGitLab:
+		if client, err = platforms.NewGitLabClient(repo.remoteURL); err != nil {
+			client = nil
+		}
+	default:
+		err = fmt.Errorf("unsupported platform: %s", platform)
+	}
+	repo.platformClient, repo.platformErr = client, err
+	return repo
+}
+
+// requirePlatform returns the platform client, or the error a tool that
+// needs one reports when there is none
+func (r *mcpRepo) requirePlatform(tool string) (platforms.PlatformClient, error) {
+	if r.platformClient == nil {
+		return nil, fmt.Errorf("%s can't reach GitHub or GitLab: %w", tool, r.platformErr)
+	}
+	return r.platformClient, nil
+}
+
+// runMCPLoop serves MCP over stdio: newline-delimited JSON-RPC messages on
+// in, responses on out. A malformed message gets a parse error instead of
+// stopping the server, which runs until in is closed or ctx is cancelled.
+func runMCPLoop(ctx context.Context, in io.Reader, out io.Writer, repo *mcpRepo) error {
+	// Reading blocks, so it happens apart from the loop that watches ctx
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	encoder := json.NewEncoder(out)
 	for {
-		var request MCPRequest
-		if err := decoder.Decode(&request); err != nil {
-			if err == io.EOF {
-				break
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read request: %w", err)
+		case line := <-lines:
+			response, ok := handleMCPMessage(line, repo)
+			if !ok {
+				continue
+			}
+			if err := encoder.Encode(response); err != nil {
+				return fmt.Errorf("failed to encode response: %w", err)
 			}
-			return fmt.Errorf("failed to decode request: %w", err)
 		}
+	}
+}
 
-		response := handleMCPRequest(request, gitAnalyzer)
-		if err := encoder.Encode(response); err != nil {
-			return fmt.Errorf("failed to encode response: %w", err)
+// handleMCPMessage answers one raw JSON-RPC message. It reports false for
+// notifications, which get no response.
+func handleMCPMessage(data []byte, repo *mcpRepo) (MCPResponse, bool) {
+	var request MCPRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return MCPResponse{
+			JsonRPC: "2.0",
+			Error: &MCPError{
+				Code:    -32700,
+				Message: fmt.Sprintf("Parse error: %v", err),
+			},
+		}, true
+	}
+
+	// Notifications such as notifications/initialized carry no id
+	if request.ID == nil {
+		return MCPResponse{}, false
+	}
+	return handleMCPRequest(request, repo), true
+}
+
+// mcpSSEServer serves MCP over HTTP using the SSE transport: clients open an
+// event stream on /sse, which announces a per-session endpoint that accepts
+// JSON-RPC requests via POST. Responses are delivered on the event stream.
+type mcpSSEServer struct {
+	repo     *mcpRepo
+	token    string // Bearer token every request must carry; empty on loopback only
+	mu       sync.Mutex
+	sessions map[string]chan MCPResponse
+}
+
+// runMCPSSEServer serves MCP over HTTP/SSE until ctx is cancelled. The tools
+// commit and open PRs/MRs in the user's name, so listening anywhere but on
+// loopback takes a token.
+func runMCPSSEServer(ctx context.Context, addr, token string, repo *mcpRepo) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %s: %w", addr, err)
+	}
+	if !isLoopbackHost(host) && token == "" {
+		return fmt.Errorf("refusing to listen on %s without a token; pass --token or set AUTO_PR_MCP_TOKEN, or listen on 127.0.0.1", addr)
+	}
+
+	sseServer := &mcpSSEServer{
+		repo:     repo,
+		token:    token,
+		sessions: make(map[string]chan MCPResponse),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", sseServer.authorize(sseServer.handleStream))
+	mux.HandleFunc("/message", sseServer.authorize(sseServer.handleMessage))
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+		// Derive request contexts from ctx so open event streams end on shutdown
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "MCP server listening on %s (SSE endpoint: /sse)\n", addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
 		}
+		return fmt.Errorf("MCP server failed: %w", err)
+	case <-ctx.Done():
 	}
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down MCP server: %w", err)
+	}
 	return nil
 }
 
-func handleMCPRequest(request MCPRequest, gitAnalyzer *git.Analyzer) MCPResponse {
+// authorize turns away requests from web pages, which browsers mark with
+// their Origin, and, without a token, any addressed to a non-local Host, as
+// DNS rebinding would; with a token it also requires it as a bearer token
+func (s *mcpSSEServer) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			parsed, err := url.Parse(origin)
+			if err != nil || !isLoopbackHost(parsed.Hostname()) {
+				http.Error(w, "forbidden origin", http.StatusForbidden)
+				return
+			}
+		}
+		if s.token == "" {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			if !isLoopbackHost(host) {
+				http.Error(w, "forbidden host", http.StatusForbidden)
+				return
+			}
+		} else {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// isLoopbackHost reports whether host, a name or IP address without a port,
+// only reaches this machine. An empty host means every interface.
+func isLoopbackHost(host string) bool {
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleStream opens the SSE stream for a new session
+func (s *mcpSSEServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sessionID, err := newMCPSessionID()
+	if err != nil {
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	messages := make(chan MCPResponse, 16)
+	s.mu.Lock()
+	s.sessions[sessionID] = messages
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, sessionID)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	fmt.Fprintf(w, "event: endpoint\ndata: /message?sessionId=%s\n\n", sessionID)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case response := <-messages:
+			data, err := json.Marshal(response)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleMessage accepts a JSON-RPC request for a session and queues the response
+func (s *mcpSSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	messages, ok := s.sessions[r.URL.Query().Get("sessionId")]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response, ok := handleMCPMessage(data, s.repo)
+	if !ok {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	select {
+	case messages <- response:
+		w.WriteHeader(http.StatusAccepted)
+	case <-r.Context().Done():
+	}
+}
+
+// newMCPSessionID returns a random session identifier
+func newMCPSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func handleMCPRequest(request MCPRequest, repo *mcpRepo) MCPResponse {
 	switch request.Method {
+	case "initialize":
+		return MCPResponse{
+			JsonRPC: "2.0",
+			ID:      request.ID,
+			Result:  mcpInitializeResult(request.Params),
+		}
+
+	case "ping":
+		return MCPResponse{
+			JsonRPC: "2.0",
+			ID:      request.ID,
+			Result:  map[string]interface{}{},
+		}
+
 	case "tools/list":
 		return MCPResponse{
 			JsonRPC: "2.0",
 			ID:      request.ID,
 			Result: MCPToolList{
 				Tools: []MCPTool{
+					{
+						Name:        "server_info",
+						Description: "Report the auto-pr version, detected platform, available AI providers and platform authentication, with the problems that would stop other tools from working",
+						InputSchema: map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{},
+						},
+					},
 					{
 						Name:        "repo_status",
 						Description: "Get repository status and branch information",
@@ -103,24 +471,68 @@ func handleMCPRequest(request MCPRequest, gitAnalyzer *git.Analyzer) MCPResponse
 					},
 					{
 						Name:        "analyze_changes",
-						Description: "Analyze git changes and generate PR content",
+						Description: "Analyze the branch's commits and changes, with the change type, labels and base branch to use for create_pr",
+						InputSchema: map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"paths": map[string]interface{}{
+									"type":        "array",
+									"description": "Limit the analysis to these paths (e.g. a monorepo subproject)",
+									"items":       map[string]interface{}{"type": "string"},
+								},
+							},
+						},
+					},
+					{
+						Name:        "commit_changes",
+						Description: "Commit changes with the given or an AI-generated message",
+						InputSchema: map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"message": map[string]interface{}{
+									"type":        "string",
+									"description": "Commit message (generated with AI when omitted)",
+								},
+								"stage_all": map[string]interface{}{
+									"type":        "boolean",
+									"description": "Stage all changes before committing",
+								},
+							},
+						},
+					},
+					{
+						Name:        "list_templates",
+						Description: "List the built-in and custom PR/MR templates",
+						InputSchema: map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{},
+						},
+					},
+					{
+						Name:        "render_template",
+						Description: "Render a PR/MR template with a title and description, filled in with the branch's commits and changes, to preview the body before create_pr",
 						InputSchema: map[string]interface{}{
 							"type": "object",
 							"properties": map[string]interface{}{
-								"commit_range": map[string]interface{}{
+								"name": map[string]interface{}{
 									"type":        "string",
-									"description": "Specific commit range to analyze",
+									"description": "Template name, as returned by list_templates",
 								},
-								"context_file": map[string]interface{}{
+								"title": map[string]interface{}{
 									"type":        "string",
-									"description": "Additional context file path",
+									"description": "Pull request title",
+								},
+								"body": map[string]interface{}{
+									"type":        "string",
+									"description": "Description whose summary, changes and test plan fill the template's sections",
 								},
 							},
+							"required": []string{"name"},
 						},
 					},
 					{
 						Name:        "create_pr",
-						Description: "Create a pull request with AI-generated content",
+						Description: "Open a pull request from the current branch with the given title and body",
 						InputSchema: map[string]interface{}{
 							"type": "object",
 							"properties": map[string]interface{}{
@@ -132,6 +544,10 @@ func handleMCPRequest(request MCPRequest, gitAnalyzer *git.Analyzer) MCPResponse
 									"type":        "string",
 									"description": "Pull request body/description",
 								},
+								"base": map[string]interface{}{
+									"type":        "string",
+									"description": "Branch to merge into (defaults to the base_branch analyze_changes reports)",
+								},
 								"draft": map[string]interface{}{
 									"type":        "boolean",
 									"description": "Create as draft",
@@ -155,7 +571,7 @@ func handleMCPRequest(request MCPRequest, gitAnalyzer *git.Analyzer) MCPResponse
 		}
 
 	case "tools/call":
-		return handleToolCall(request, gitAnalyzer)
+		return handleToolCall(request, repo)
 
 	default:
 		return MCPResponse{
@@ -169,19 +585,344 @@ func handleMCPRequest(request MCPRequest, gitAnalyzer *git.Analyzer) MCPResponse
 	}
 }
 
-func handleToolCall(request MCPRequest, gitAnalyzer *git.Analyzer) MCPResponse {
-	// This is a simplified implementation
-	// In a full implementation, you would parse the params and call the appropriate tool
-	return MCPResponse{
-		JsonRPC: "2.0",
-		ID:      request.ID,
-		Result: map[string]interface{}{
-			"content": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": "MCP tool implementation is a work in progress. Use the regular CLI commands for now.",
-				},
+func handleToolCall(request MCPRequest, repo *mcpRepo) MCPResponse {
+	var params MCPToolCallParams
+	if err := decodeMCPParams(request.Params, &params); err != nil {
+		return MCPResponse{
+			JsonRPC: "2.0",
+			ID:      request.ID,
+			Error: &MCPError{
+				Code:    -32602,
+				Message: fmt.Sprintf("Invalid params: %v", err),
+			},
+		}
+	}
+
+	switch params.Name {
+	case "server_info":
+		text, err := callServerInfo(repo)
+		return mcpToolResult(request.ID, text, err)
+	case "analyze_changes":
+		text, err := callAnalyzeChanges(params.Arguments, repo.gitAnalyzer)
+		return mcpToolResult(request.ID, text, err)
+	case "commit_changes":
+		text, err := callCommitChanges(params.Arguments, repo.gitAnalyzer)
+		return mcpToolResult(request.ID, text, err)
+	case "list_templates":
+		text, err := callListTemplates()
+		return mcpToolResult(request.ID, text, err)
+	case "render_template":
+		text, err := callRenderTemplate(params.Arguments, repo.gitAnalyzer)
+		return mcpToolResult(request.ID, text, err)
+	case "create_pr":
+		text, err := callCreatePR(params.Arguments, repo)
+		return mcpToolResult(request.ID, text, err)
+	default:
+		return mcpToolResult(request.ID,
+			"MCP tool implementation is a work in progress. Use the regular CLI commands for now.", nil)
+	}
+}
+
+// mcpProtocolVersions are the MCP protocol versions the server speaks, newest
+// first
+var mcpProtocolVersions = []string{"2025-03-26", "2024-11-05"}
+
+// mcpInitializeResult answers the initialize handshake, agreeing to the
+// client's protocol version when it is supported and offering the newest
+// one otherwise
+func mcpInitializeResult(params interface{}) map[string]interface{} {
+	var initialize struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = decodeMCPParams(params, &initialize)
+
+	version := mcpProtocolVersions[0]
+	for _, supported := range mcpProtocolVersions {
+		if initialize.ProtocolVersion == supported {
+			version = supported
+		}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    "auto-pr",
+			"version": rootCmd.Version,
+		},
+	}
+}
+
+// decodeMCPParams converts generic JSON-RPC params into the given struct
+func decodeMCPParams(params interface{}, target interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// mcpToolResult wraps tool output, or a tool error, in an MCP tool result
+func mcpToolResult(id interface{}, text string, err error) MCPResponse {
+	if err != nil {
+		text = err.Error()
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": text,
 			},
 		},
 	}
+	if err != nil {
+		result["isError"] = true
+	}
+
+	return MCPResponse{
+		JsonRPC: "2.0",
+		ID:      id,
+		Result:  result,
+	}
+}
+
+// callCommitChanges stages (optionally) and commits changes, generating the
+// message with AI when none is given, and returns the new commit hash
+func callCommitChanges(arguments map[string]interface{}, gitAnalyzer *git.Analyzer) (string, error) {
+	if gitAnalyzer == nil {
+		return "", fmt.Errorf("not in a git repository")
+	}
+
+	message, _ := arguments["message"].(string)
+	stageAll, _ := arguments["stage_all"].(bool)
+
+	// Progress output would corrupt the stdio transport, so it is discarded
+	result, err := service.Commit(service.CommitOptions{
+		RepoPath: gitAnalyzer.RepoPath(),
+		StageAll: stageAll,
+		Message:  message,
+		Out:      io.Discard,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Created commit %s: %s", result.Hash, result.Message), nil
+}
+
+// callAnalyzeChanges describes the branch's commits and file changes as JSON,
+// along with the change type, suggested labels and base branch that create
+// would use, so an assistant can fill in create_pr without guessing them
+func callAnalyzeChanges(arguments map[string]interface{}, gitAnalyzer *git.Analyzer) (string, error) {
+	if gitAnalyzer == nil {
+		return "", fmt.Errorf("not in a git repository")
+	}
+
+	paths := mcpStringList(arguments["paths"])
+	analysis, err := service.AnalyzeChanges(service.ContextOptions{RepoPath: gitAnalyzer.RepoPath(), Paths: paths})
+	if err != nil {
+		return "", err
+	}
+	aiContext := analysis.Context
+
+	commits := make([]map[string]interface{}, 0, len(aiContext.CommitHistory))
+	for _, commit := range aiContext.CommitHistory {
+		commits = append(commits, map[string]interface{}{
+			"hash":    commit.Hash,
+			"message": commit.Message,
+			"author":  commit.Author,
+		})
+	}
+	fileChanges := make([]map[string]interface{}, 0, len(aiContext.FileChanges))
+	for _, change := range aiContext.FileChanges {
+		fileChanges = append(fileChanges, map[string]interface{}{
+			"path":      change.Path,
+			"status":    change.Status,
+			"additions": change.Additions,
+			"deletions": change.Deletions,
+		})
+	}
+	labels := analysis.SuggestedLabels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"branch":               aiContext.BranchInfo.Name,
+		"base_branch":          aiContext.BranchInfo.BaseBranch,
+		"base_branch_inferred": analysis.BaseBranchInferred,
+		"commits_ahead":        aiContext.BranchInfo.CommitsAhead,
+		"diff_summary":         aiContext.DiffSummary,
+		"commits":              commits,
+		"file_changes":         fileChanges,
+		"change_type":          analysis.ChangeType,
+		"suggested_labels":     labels,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// callListTemplates lists the templates render_template and create_pr accept
+// as JSON
+func callListTemplates() (string, error) {
+	manager, err := templates.NewManager()
+	if err != nil {
+		return "", err
+	}
+	custom, err := manager.ListCustomTemplates()
+	if err != nil {
+		return "", fmt.Errorf("failed to list custom templates: %w", err)
+	}
+
+	list := make([]map[string]interface{}, 0)
+	for _, tmpl := range append(manager.ListBuiltInTemplates(), custom...) {
+		list = append(list, map[string]interface{}{
+			"name":        tmpl.Name,
+			"type":        tmpl.Type,
+			"description": tmpl.Description,
+			"built_in":    tmpl.IsBuiltIn,
+		})
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// callRenderTemplate renders a template the way create applies it, from the
+// given title and body plus the branch's commits and changes when the server
+// runs in a repository, and returns the rendered body
+func callRenderTemplate(arguments map[string]interface{}, gitAnalyzer *git.Analyzer) (string, error) {
+	name, _ := arguments["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	title, _ := arguments["title"].(string)
+	body, _ := arguments["body"].(string)
+
+	manager, err := templates.NewManager()
+	if err != nil {
+		return "", err
+	}
+
+	// Without a branch to describe, the template still renders the title and body
+	aiContext := &ai.AIContext{}
+	if gitAnalyzer != nil {
+		if branchContext, err := service.PRContext(service.ContextOptions{RepoPath: gitAnalyzer.RepoPath()}); err == nil {
+			aiContext = branchContext
+		}
+	}
+
+	rendered, err := templates.EnhanceWithTemplate(manager, name, aiContext, &ai.AIResponse{Title: title, Body: body})
+	if err != nil {
+		return "", err
+	}
+	return rendered.Body, nil
+}
+
+// callServerInfo reports, as JSON, what the other tools depend on: the
+// repository and its platform, whether the platform CLI is logged in and
+// which AI providers are installed. Anything missing is listed in problems
+// so the assistant can tell the user what to fix.
+func callServerInfo(repo *mcpRepo) (string, error) {
+	problems := []string{}
+	providers := ai.GetAvailableProviders()
+	if len(providers) == 0 {
+		providers = []types.AIProvider{}
+		problems = append(problems, "no AI provider available (install Claude Code)")
+	}
+
+	info := map[string]interface{}{
+		"version":       rootCmd.Version,
+		"git_repo":      repo.gitAnalyzer != nil,
+		"ai_providers":  providers,
+		"authenticated": false,
+	}
+
+	if problem := describePlatform(info, repo); problem != "" {
+		problems = append(problems, problem)
+	}
+	info["problems"] = problems
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// describePlatform adds the remote, platform and authentication status of
+// the repository to info, returning what stops PRs/MRs from being opened
+func describePlatform(info map[string]interface{}, repo *mcpRepo) string {
+	if repo.remoteURL != "" {
+		info["remote_url"] = repo.remoteURL
+	}
+	if repo.platform != "" {
+		info["platform"] = repo.platform
+	}
+	if repo.platformClient == nil {
+		return repo.platformErr.Error()
+	}
+
+	if !repo.platformClient.IsAuthenticated() {
+		login := "gh auth login"
+		if repo.platform == types.PlatformGitLab {
+			login = "glab auth login"
+		}
+		return fmt.Sprintf("not authenticated with %s (run: %s)", repo.platform, login)
+	}
+	info["authenticated"] = true
+	return ""
+}
+
+// callCreatePR opens a PR/MR from the current branch with the given title
+// and body on the repository's platform, returning its URL. It targets the
+// base branch analyze_changes reports unless base names another one.
+func callCreatePR(arguments map[string]interface{}, repo *mcpRepo) (string, error) {
+	client, err := repo.requirePlatform("create_pr")
+	if err != nil {
+		return "", err
+	}
+
+	title, _ := arguments["title"].(string)
+	body, _ := arguments["body"].(string)
+	base, _ := arguments["base"].(string)
+	draft, _ := arguments["draft"].(bool)
+
+	result, err := service.OpenPR(service.OpenPROptions{
+		RepoPath:  repo.gitAnalyzer.RepoPath(),
+		Title:     title,
+		Body:      body,
+		Base:      base,
+		Draft:     draft,
+		Reviewers: mcpStringList(arguments["reviewers"]),
+		Labels:    mcpStringList(arguments["labels"]),
+		Client:    client,
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Existing {
+		return fmt.Sprintf("A PR/MR is already open for %s: %s", result.PR.HeadBranch, result.PR.URL), nil
+	}
+	return fmt.Sprintf("Created #%d against %s: %s", result.PR.Number, result.PR.BaseBranch, result.PR.URL), nil
+}
+
+// mcpStringList returns the non-empty strings of a JSON array argument
+func mcpStringList(value interface{}) []string {
+	var list []string
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		if text, ok := item.(string); ok && text != "" {
+			list = append(list, text)
+		}
+	}
+	return list
 }
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"auto-pr/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var retargetCmd = &cobra.Command{
+	Use:   "retarget",
+	Short: "Change the base branch of the current branch's PR/MR",
+	Long: `Point the current branch's open PR/MR at another base branch, for when it
+was opened against the wrong one, without closing it or losing its reviews
+and discussion.
+
+The new base must exist locally or on origin and can't be the branch itself.
+The updated PR/MR URL is printed.`,
+	Args: cobra.NoArgs,
+	RunE: runRetarget,
+}
+
+func init() {
+	rootCmd.AddCommand(retargetCmd)
+
+	retargetCmd.Flags().String("base", "", "Branch the PR/MR should merge into (required)")
+	retargetCmd.Flags().Bool("auto-login", false, "Offer to run gh/glab auth login when not authenticated, then retry")
+}
+
+func runRetarget(cmd *cobra.Command, args []string) error {
+	base, _ := cmd.Flags().GetString("base")
+	autoLogin, _ := cmd.Flags().GetBool("auto-login")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	_, err := service.Retarget(service.RetargetOptions{
+		Base:      base,
+		AutoLogin: autoLogin,
+		DryRun:    dryRun,
+	})
+	return err
+}
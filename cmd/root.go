@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/ui"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,6 +24,11 @@ generate comprehensive pull requests and merge requests for GitHub and GitLab.
 It analyzes your commits, code changes, and repository context to create
 meaningful PR/MR titles, descriptions, and metadata automatically.`,
 	Version: "0.1.0",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// NO_COLOR (https://no-color.org) signals a terminal that wants plain output
+		ui.SetPlain(viper.GetBool("no_emoji") || os.Getenv("NO_COLOR") != "")
+		return resolveProviderFlag(cmd)
+	},
 }
 
 func Execute() error {
@@ -28,9 +38,11 @@ func Execute() error {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.auto-pr/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/auto-pr/config.yaml or $HOME/.auto-pr/config.yaml)")
 	rootCmd.PersistentFlags().Bool("verbose", false, "verbose output")
 	rootCmd.PersistentFlags().Bool("dry-run", false, "preview changes without executing")
+	rootCmd.PersistentFlags().String("provider", "", "AI provider for this run (claude|gemini|openai|auto)")
+	rootCmd.PersistentFlags().Bool("no-emoji", false, "use plain ASCII markers instead of emoji in output")
 
 	if err := viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")); err != nil {
 		fmt.Fprintf(os.Stderr, "error: failed to bind verbose flag: %v\n", err)
@@ -40,16 +52,54 @@ func init() {
 		fmt.Fprintf(os.Stderr, "error: failed to bind dry-run flag: %v\n", err)
 		os.Exit(1)
 	}
+	if err := viper.BindPFlag("ai.provider", rootCmd.PersistentFlags().Lookup("provider")); err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to bind provider flag: %v\n", err)
+		os.Exit(1)
+	}
+	if err := viper.BindPFlag("no_emoji", rootCmd.PersistentFlags().Lookup("no-emoji")); err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to bind no-emoji flag: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// quietOutput returns where a command's progress goes: nowhere with --quiet,
+// which prints only the result, otherwise the default standard output. A dry
+// run has no result to print, so the two can't be combined.
+func quietOutput(quiet, dryRun bool) (io.Writer, error) {
+	if !quiet {
+		return nil, nil
+	}
+	if dryRun {
+		return nil, fmt.Errorf("--quiet can't be combined with --dry-run, which has no result to print")
+	}
+	return io.Discard, nil
+}
+
+// resolveProviderFlag validates an explicitly requested --provider so the run
+// fails clearly instead of silently using another provider
+func resolveProviderFlag(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("provider")
+	if flag == nil || !flag.Changed {
+		return nil
+	}
+
+	provider, err := ai.ResolveProvider(flag.Value.String())
+	if err != nil {
+		return err
+	}
+
+	viper.Set("ai.provider", string(provider))
+	return nil
 }
 
 func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
-		home, err := os.UserHomeDir()
+		configDir, err := config.ConfigDir()
 		cobra.CheckErr(err)
 
-		viper.AddConfigPath(home + "/.auto-pr")
+		viper.AddConfigPath(configDir)
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("config")
 	}
@@ -63,6 +113,10 @@ func initConfig() {
 	_ = viper.BindEnv("ai.model", "AUTO_PR_AI_MODEL")
 	_ = viper.BindEnv("ai.max_tokens", "AUTO_PR_AI_MAX_TOKENS")
 	_ = viper.BindEnv("ai.temperature", "AUTO_PR_AI_TEMPERATURE")
+	_ = viper.BindEnv("ai.refine_iterations", "AUTO_PR_AI_REFINE_ITERATIONS")
+
+	// Output
+	_ = viper.BindEnv("no_emoji", "AUTO_PR_NO_EMOJI")
 
 	// Claude specific
 	_ = viper.BindEnv("ai.claude.cli_path", "AUTO_PR_CLAUDE_CLI_PATH")
@@ -74,16 +128,29 @@ func initConfig() {
 	_ = viper.BindEnv("platforms.github.draft", "AUTO_PR_GITHUB_DRAFT")
 	_ = viper.BindEnv("platforms.github.auto_merge", "AUTO_PR_GITHUB_AUTO_MERGE")
 	_ = viper.BindEnv("platforms.github.delete_branch", "AUTO_PR_GITHUB_DELETE_BRANCH")
+	_ = viper.BindEnv("platforms.github.use_codeowners", "AUTO_PR_GITHUB_USE_CODEOWNERS")
 
 	// GitLab configuration
 	_ = viper.BindEnv("platforms.gitlab.merge_when_pipeline_succeeds", "AUTO_PR_GITLAB_AUTO_MERGE")
 	_ = viper.BindEnv("platforms.gitlab.remove_source_branch", "AUTO_PR_GITLAB_REMOVE_SOURCE_BRANCH")
 	_ = viper.BindEnv("platforms.gitlab.default_assignee", "AUTO_PR_GITLAB_DEFAULT_ASSIGNEE")
+	_ = viper.BindEnv("platforms.gitlab.use_codeowners", "AUTO_PR_GITLAB_USE_CODEOWNERS")
+	_ = viper.BindEnv("platforms.title_max_length", "AUTO_PR_TITLE_MAX_LENGTH")
 
 	// Git configuration
 	_ = viper.BindEnv("git.commit_limit", "AUTO_PR_GIT_COMMIT_LIMIT")
 	_ = viper.BindEnv("git.diff_context", "AUTO_PR_GIT_DIFF_CONTEXT")
 	_ = viper.BindEnv("git.max_diff_size", "AUTO_PR_GIT_MAX_DIFF_SIZE")
+	_ = viper.BindEnv("git.detailed_commits", "AUTO_PR_GIT_DETAILED_COMMITS")
+	_ = viper.BindEnv("git.codeowners_path", "AUTO_PR_GIT_CODEOWNERS_PATH")
+	_ = viper.BindEnv("git.protected_branches", "AUTO_PR_GIT_PROTECTED_BRANCHES")
+	_ = viper.BindEnv("git.context_exclude_patterns", "AUTO_PR_GIT_CONTEXT_EXCLUDE_PATTERNS")
+	_ = viper.BindEnv("git.base_branch_candidates", "AUTO_PR_GIT_BASE_BRANCH_CANDIDATES")
+	_ = viper.BindEnv("git.test_command", "AUTO_PR_GIT_TEST_COMMAND")
+	_ = viper.BindEnv("git.exclude_commit_authors", "AUTO_PR_GIT_EXCLUDE_COMMIT_AUTHORS")
+	_ = viper.BindEnv("git.include_generated", "AUTO_PR_GIT_INCLUDE_GENERATED")
+	_ = viper.BindEnv("git.commit_prompt_template", "AUTO_PR_GIT_COMMIT_PROMPT_TEMPLATE")
+	_ = viper.BindEnv("git.auto_push", "AUTO_PR_GIT_AUTO_PUSH")
 
 	// Template configuration
 	_ = viper.BindEnv("templates.custom_templates_dir", "AUTO_PR_TEMPLATES_DIR")
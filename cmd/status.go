@@ -7,6 +7,7 @@ import (
 	"auto-pr/internal/ai"
 	"auto-pr/internal/git"
 	"auto-pr/internal/platforms"
+	"auto-pr/internal/ui"
 	"auto-pr/pkg/types"
 
 	"github.com/spf13/cobra"
@@ -24,7 +25,7 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
-	fmt.Println("🔍 Auto PR Status Check")
+	fmt.Printf("%s Auto PR Status Check\n", ui.Search)
 	fmt.Println("========================")
 
 	// Initialize git analyzer
@@ -34,105 +35,112 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check git repository
-	fmt.Println("\n📁 Repository Information:")
+	fmt.Printf("\n%s Repository Information:\n", ui.Folder)
 	if !gitAnalyzer.IsGitRepository() {
-		fmt.Println("   ❌ Not a git repository")
+		fmt.Printf("   %s Not a git repository\n", ui.Failure)
 		return nil
 	}
-	fmt.Println("   ✅ Git repository detected")
+	fmt.Printf("   %s Git repository detected\n", ui.Success)
 
 	// Get repository status
 	status, err := gitAnalyzer.GetStatus()
 	if err != nil {
-		fmt.Printf("   ❌ Failed to get repository status: %s\n", err)
+		fmt.Printf("   %s Failed to get repository status: %s\n", ui.Failure, err)
 		return nil
 	}
 
-	fmt.Printf("   📋 Current branch: %s\n", status.CurrentBranch)
-	fmt.Printf("   📋 Base branch: %s\n", status.BaseBranch)
+	if status.DetachedHead {
+		fmt.Printf("   %s Detached HEAD at %s (checkout a branch to create a PR/MR)\n", ui.Warning, git.ShortHash(status.HeadCommit))
+	} else {
+		fmt.Printf("   %s Current branch: %s\n", ui.Clipboard, status.CurrentBranch)
+	}
+	fmt.Printf("   %s Base branch: %s\n", ui.Clipboard, status.BaseBranch)
 
 	if status.RemoteURL != "" {
-		fmt.Printf("   🔗 Remote URL: %s\n", status.RemoteURL)
+		fmt.Printf("   %s Remote URL: %s\n", ui.Link, status.RemoteURL)
 
 		// Detect platform
 		platform, err := platforms.DetectPlatform(status.RemoteURL)
 		if err != nil {
-			fmt.Printf("   ❓ Platform: Unknown (%s)\n", err)
+			fmt.Printf("   %s Platform: Unknown (%s)\n", ui.Unknown, err)
 		} else {
-			fmt.Printf("   🌐 Platform: %s\n", platform)
+			fmt.Printf("   %s Platform: %s\n", ui.Globe, platform)
 
 			// Check platform authentication
 			switch platform {
 			case types.PlatformGitHub:
 				client, err := platforms.NewGitHubClient(status.RemoteURL)
 				if err == nil && client.IsAuthenticated() {
-					fmt.Println("   ✅ GitHub CLI authenticated")
+					fmt.Printf("   %s GitHub CLI authenticated\n", ui.Success)
 				} else {
-					fmt.Println("   ❌ GitHub CLI not authenticated (run: gh auth login)")
+					fmt.Printf("   %s GitHub CLI not authenticated (run: gh auth login)\n", ui.Failure)
 				}
 			case types.PlatformGitLab:
 				client, err := platforms.NewGitLabClient(status.RemoteURL)
 				if err == nil && client.IsAuthenticated() {
-					fmt.Println("   ✅ GitLab CLI authenticated")
+					fmt.Printf("   %s GitLab CLI authenticated\n", ui.Success)
 				} else {
-					fmt.Println("   ❌ GitLab CLI not authenticated (run: glab auth login)")
+					fmt.Printf("   %s GitLab CLI not authenticated (run: glab auth login)\n", ui.Failure)
 				}
 			}
 		}
 	} else {
-		fmt.Println("   ⚠️  No remote repository configured")
+		fmt.Printf("   %s No remote repository configured\n", ui.Warning)
 	}
 
 	// Show changes
-	fmt.Println("\n📝 Working Directory Status:")
+	fmt.Printf("\n%s Working Directory Status:\n", ui.Note)
 	if status.HasChanges {
 		if len(status.StagedFiles) > 0 {
-			fmt.Printf("   📦 Staged files: %d\n", len(status.StagedFiles))
+			fmt.Printf("   %s Staged files: %d\n", ui.Package, len(status.StagedFiles))
 		}
 		if len(status.UnstagedFiles) > 0 {
-			fmt.Printf("   📄 Unstaged files: %d\n", len(status.UnstagedFiles))
+			fmt.Printf("   %s Unstaged files: %d\n", ui.File, len(status.UnstagedFiles))
 		}
 		if len(status.UntrackedFiles) > 0 {
-			fmt.Printf("   ❓ Untracked files: %d\n", len(status.UntrackedFiles))
+			fmt.Printf("   %s Untracked files: %d\n", ui.Unknown, len(status.UntrackedFiles))
+		}
+		if len(status.UnmergedFiles) > 0 {
+			fmt.Printf("   %s Unmerged files: %d (resolve the conflicts before committing)\n", ui.Failure, len(status.UnmergedFiles))
 		}
 	} else {
-		fmt.Println("   ✅ Working directory clean")
+		fmt.Printf("   %s Working directory clean\n", ui.Success)
 	}
 
 	// Show commit status
 	if status.CommitsAhead > 0 || status.CommitsBehind > 0 {
-		fmt.Printf("   📊 Branch status: %d ahead, %d behind %s\n",
+		fmt.Printf("   %s Branch status: %d ahead, %d behind %s\n", ui.Chart,
 			status.CommitsAhead, status.CommitsBehind, status.BaseBranch)
 	}
 
 	// Check AI providers
-	fmt.Println("\n🤖 AI Provider Status:")
+	fmt.Printf("\n%s AI Provider Status:\n", ui.Robot)
 
 	// Check Claude CLI
 	if isClaudeAvailable() {
-		fmt.Println("   ✅ Claude Code available")
+		fmt.Printf("   %s Claude Code available\n", ui.Success)
 	} else {
-		fmt.Println("   ❌ Claude Code not found - Please install and configure Claude Code")
+		fmt.Printf("   %s Claude Code not found - Please install and configure Claude Code\n", ui.Failure)
 	}
 
 	// Check configuration
-	fmt.Println("\n⚙️  Configuration Status:")
+	fmt.Printf("\n%s Configuration Status:\n", ui.Gear)
 
 	// Try to load config
 	configExists := checkConfigExists()
 	if configExists {
-		fmt.Println("   ✅ Configuration file found")
+		fmt.Printf("   %s Configuration file found\n", ui.Success)
 	} else {
-		fmt.Println("   ❌ Configuration file not found (run: auto-pr config init)")
+		fmt.Printf("   %s Configuration file not found (run: auto-pr config init)\n", ui.Failure)
 	}
 
 	// Show commit history if available
 	if status.RemoteURL != "" {
-		fmt.Println("\n📚 Recent Commits:")
+		fmt.Printf("\n%s Recent Commits:\n", ui.Books)
 		commits, err := gitAnalyzer.GetCommitHistory(5)
 		if err == nil && len(commits) > 0 {
 			for _, commit := range commits {
-				fmt.Printf("   • %s %s\n", commit.Hash[:8], commit.Message)
+				fmt.Printf("   • %s %s\n", git.ShortHash(commit.Hash), commit.Message)
 			}
 		} else {
 			fmt.Println("   No commits found")
@@ -140,17 +148,19 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// PR readiness check
-	fmt.Println("\n🚀 PR Creation Readiness:")
+	fmt.Printf("\n%s PR Creation Readiness:\n", ui.Rocket)
 	if !gitAnalyzer.IsGitRepository() {
-		fmt.Println("   ❌ Not ready: Not a git repository")
+		fmt.Printf("   %s Not ready: Not a git repository\n", ui.Failure)
+	} else if status.DetachedHead {
+		fmt.Printf("   %s Not ready: Detached HEAD, checkout a branch first\n", ui.Failure)
 	} else if status.RemoteURL == "" {
-		fmt.Println("   ❌ Not ready: No remote repository")
+		fmt.Printf("   %s Not ready: No remote repository\n", ui.Failure)
 	} else if !status.HasChanges && status.CommitsAhead == 0 {
-		fmt.Println("   ⚠️  No changes to create PR from")
+		fmt.Printf("   %s No changes to create PR from\n", ui.Warning)
 	} else if !configExists {
-		fmt.Println("   ❌ Not ready: Configuration not initialized")
+		fmt.Printf("   %s Not ready: Configuration not initialized\n", ui.Failure)
 	} else {
-		fmt.Println("   ✅ Ready to create PR/MR!")
+		fmt.Printf("   %s Ready to create PR/MR!\n", ui.Success)
 	}
 
 	return nil
@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
+	"auto-pr/internal/editor"
 	"auto-pr/internal/templates"
+	"auto-pr/internal/ui"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -67,7 +67,10 @@ func init() {
 }
 
 func runTemplateList(cmd *cobra.Command, args []string) error {
-	manager := templates.NewManager()
+	manager, err := templates.NewManager()
+	if err != nil {
+		return err
+	}
 
 	// Get built-in templates
 	builtIn := manager.ListBuiltInTemplates()
@@ -109,7 +112,10 @@ func runTemplateCreate(cmd *cobra.Command, args []string) error {
 	fromTemplate, _ := cmd.Flags().GetString("from")
 	shouldEdit, _ := cmd.Flags().GetBool("edit")
 
-	manager := templates.NewManager()
+	manager, err := templates.NewManager()
+	if err != nil {
+		return err
+	}
 
 	// Create template
 	tmpl, err := manager.CreateTemplate(name, templateType, fromTemplate)
@@ -117,21 +123,11 @@ func runTemplateCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create template: %w", err)
 	}
 
-	fmt.Printf("✅ Created template '%s' at %s\n", name, tmpl.Path)
+	fmt.Printf("%s Created template '%s' at %s\n", ui.Success, name, tmpl.Path)
 
 	// Open editor if requested
 	if shouldEdit {
-		editor := os.Getenv("EDITOR")
-		if editor == "" {
-			editor = "vi" // Default to vi
-		}
-
-		editorCmd := exec.Command(editor, tmpl.Path)
-		editorCmd.Stdin = os.Stdin
-		editorCmd.Stdout = os.Stdout
-		editorCmd.Stderr = os.Stderr
-
-		if err := editorCmd.Run(); err != nil {
+		if err := editor.Open(tmpl.Path); err != nil {
 			fmt.Printf("Warning: Failed to open editor: %v\n", err)
 			fmt.Printf("You can edit the template manually at: %s\n", tmpl.Path)
 		}
@@ -143,35 +139,31 @@ func runTemplateCreate(cmd *cobra.Command, args []string) error {
 func runTemplateEdit(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
-	manager := templates.NewManager()
+	manager, err := templates.NewManager()
+	if err != nil {
+		return err
+	}
 	tmpl, err := manager.GetTemplate(name)
 	if err != nil {
 		return fmt.Errorf("failed to get template: %w", err)
 	}
 
 	// Open editor
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vi"
-	}
-
-	editorCmd := exec.Command(editor, tmpl.Path)
-	editorCmd.Stdin = os.Stdin
-	editorCmd.Stdout = os.Stdout
-	editorCmd.Stderr = os.Stderr
-
-	if err := editorCmd.Run(); err != nil {
+	if err := editor.Open(tmpl.Path); err != nil {
 		return fmt.Errorf("failed to open editor: %w", err)
 	}
 
-	fmt.Printf("✅ Template '%s' updated\n", name)
+	fmt.Printf("%s Template '%s' updated\n", ui.Success, name)
 	return nil
 }
 
 func runTemplateDelete(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
-	manager := templates.NewManager()
+	manager, err := templates.NewManager()
+	if err != nil {
+		return err
+	}
 
 	// Check if it's a built-in template
 	if manager.IsBuiltInTemplate(name) {
@@ -193,14 +185,17 @@ func runTemplateDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to delete template: %w", err)
 	}
 
-	fmt.Printf("✅ Deleted template '%s'\n", name)
+	fmt.Printf("%s Deleted template '%s'\n", ui.Success, name)
 	return nil
 }
 
 func runTemplateShow(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
-	manager := templates.NewManager()
+	manager, err := templates.NewManager()
+	if err != nil {
+		return err
+	}
 	tmpl, err := manager.GetTemplate(name)
 	if err != nil {
 		return fmt.Errorf("failed to get template: %w", err)
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"auto-pr/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove auto-pr's configuration, templates and data",
+	Long: `Remove the auto-pr directories in your home directory: ~/.auto-pr and
+the auto-pr directories under $XDG_CONFIG_HOME and $XDG_DATA_HOME, with the
+configuration, custom templates and anything else auto-pr keeps there.
+
+The directories and their contents are listed first, and nothing is removed
+without confirmation or --yes. Nothing outside them is ever deleted; the
+steps left to do by hand, such as removing the binary, are printed at the end.`,
+	Args: cobra.NoArgs,
+	RunE: runUninstall,
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+
+	uninstallCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	_, err := service.Uninstall(service.UninstallOptions{
+		Yes:    yes,
+		DryRun: dryRun,
+	})
+	return err
+}
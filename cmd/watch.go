@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"auto-pr/internal/service"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep the current branch's draft PR/MR description up to date",
+	Long: `Watch the working directory and, once it has been quiet for a while,
+regenerate the description of the current branch's draft PR/MR when new
+commits have landed on the branch.
+
+Watch only reads the repository: it never commits or pushes, so push your
+commits as usual and the description follows. It stops when the PR/MR is
+no longer an open draft or the branch is switched.`,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().Duration("debounce", service.DefaultWatchDebounce, "Quiet period after the last change before regenerating")
+	watchCmd.Flags().Duration("min-interval", service.DefaultWatchMinInterval, "Minimum time between two regenerations")
+	watchCmd.Flags().Bool("once", false, "Regenerate once if needed and exit instead of watching")
+	watchCmd.Flags().Bool("auto-login", false, "Offer to run gh/glab auth login when not authenticated, then retry")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	minInterval, _ := cmd.Flags().GetDuration("min-interval")
+	once, _ := cmd.Flags().GetBool("once")
+	autoLogin, _ := cmd.Flags().GetBool("auto-login")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return service.Watch(ctx, service.WatchOptions{
+		Debounce:    debounce,
+		MinInterval: minInterval,
+		Once:        once,
+		AutoLogin:   autoLogin,
+		DryRun:      dryRun,
+		Verbose:     viper.GetBool("verbose"),
+	})
+}
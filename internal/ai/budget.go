@@ -0,0 +1,168 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// promptBudgetFactor is how many times the response's max tokens a prompt may
+// use; with the 4096 default that keeps prompts well inside a 200k window
+const promptBudgetFactor = 40
+
+// promptOverheadTokens covers the fixed instructions and output format that
+// every prompt carries besides its context sections
+const promptOverheadTokens = 500
+
+// contextLineOverhead approximates the markup around each commit and file
+// line, such as the bullet, hash and line counts
+const contextLineOverhead = 16
+
+// estimateTokens approximates the token count of text at about four
+// characters per token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// promptBudget returns how many tokens the context sections of a prompt may
+// use for a response of maxTokens, or 0 for no limit. It isn't specific to a
+// provider so every client trims its prompts the same way.
+func promptBudget(maxTokens int, basePrompt string) int {
+	if maxTokens <= 0 {
+		return 0
+	}
+	budget := maxTokens*promptBudgetFactor - estimateTokens(basePrompt) - promptOverheadTokens
+	if budget < 1 {
+		return 1
+	}
+	return budget
+}
+
+// contextTokens estimates the tokens the commits, diff, file list and issue
+// of ctx take up in a prompt
+func contextTokens(ctx *AIContext) int {
+	chars := len(ctx.DiffSummary) + len(ctx.DiffContent)
+	if ctx.Issue != nil {
+		chars += len(ctx.Issue.Title) + len(ctx.Issue.Body)
+	}
+	for _, commit := range ctx.CommitHistory {
+		chars += len(commit.Message) + contextLineOverhead
+	}
+	for _, file := range ctx.FileChanges {
+		chars += len(file.Path) + len(file.Status) + contextLineOverhead
+	}
+	return (chars + 3) / 4
+}
+
+// fitToBudget returns a copy of ctx trimmed so its context sections fit in
+// budget tokens, along with notes on what was left out. It drops the least
+// useful parts first: the diffs of the largest files, then the oldest
+// commits, then the end of the file list, and only then cuts the diff and
+// summary short. ctx itself is never modified; a budget of 0 means no limit.
+func fitToBudget(ctx *AIContext, budget int) (*AIContext, []string) {
+	if budget <= 0 || contextTokens(ctx) <= budget {
+		return ctx, nil
+	}
+
+	trimmed := *ctx
+	var omitted []string
+	over := func() bool { return contextTokens(&trimmed) > budget }
+
+	// A large file's diff costs the most and is summarized by the file list
+	if files := splitFileDiffs(trimmed.DiffContent); len(files) > 1 {
+		dropped := 0
+		for over() {
+			largest := -1
+			for i, file := range files {
+				if file.header != "" && !file.omitted && (largest < 0 || len(file.text) > len(files[largest].text)) {
+					largest = i
+				}
+			}
+			if largest < 0 {
+				break
+			}
+			files[largest].omitted = true
+			files[largest].text = fmt.Sprintf("%s\n(diff omitted to fit the prompt)\n", files[largest].header)
+			trimmed.DiffContent = joinFileDiffs(files)
+			dropped++
+		}
+		if dropped > 0 {
+			omitted = append(omitted, fmt.Sprintf("the diffs of the %d largest files", dropped))
+		}
+	}
+
+	// Commits are newest first, so the oldest go from the end; keep the newest
+	commits := len(trimmed.CommitHistory)
+	for over() && len(trimmed.CommitHistory) > 1 {
+		trimmed.CommitHistory = trimmed.CommitHistory[:len(trimmed.CommitHistory)-1]
+	}
+	if dropped := commits - len(trimmed.CommitHistory); dropped > 0 {
+		omitted = append(omitted, fmt.Sprintf("the %d oldest commits", dropped))
+	}
+
+	files := len(trimmed.FileChanges)
+	for over() && len(trimmed.FileChanges) > 0 {
+		trimmed.FileChanges = trimmed.FileChanges[:len(trimmed.FileChanges)-1]
+	}
+	if dropped := files - len(trimmed.FileChanges); dropped > 0 {
+		omitted = append(omitted, fmt.Sprintf("%d files from the list of changed files", dropped))
+	}
+
+	if over() && trimmed.DiffContent != "" {
+		trimmed.DiffContent = truncateToTokens(trimmed.DiffContent, budget-(contextTokens(&trimmed)-estimateTokens(trimmed.DiffContent)))
+		omitted = append(omitted, "the end of the diff")
+	}
+	if over() && trimmed.DiffSummary != "" {
+		trimmed.DiffSummary = truncateToTokens(trimmed.DiffSummary, budget-(contextTokens(&trimmed)-estimateTokens(trimmed.DiffSummary)))
+		omitted = append(omitted, "the end of the changes summary")
+	}
+
+	return &trimmed, omitted
+}
+
+// fileDiff is the part of a unified diff for one file
+type fileDiff struct {
+	header  string // The "diff --git" line, empty for text before the first file
+	text    string
+	omitted bool
+}
+
+// splitFileDiffs splits a unified diff into its per-file parts, with any text
+// before the first file kept as a part of its own
+func splitFileDiffs(diff string) []fileDiff {
+	var files []fileDiff
+	for _, part := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(part, "diff --git ") {
+			files = append(files, fileDiff{header: strings.TrimRight(part, "\n")})
+		} else if len(files) == 0 {
+			files = append(files, fileDiff{})
+		}
+		files[len(files)-1].text += part
+	}
+	return files
+}
+
+// joinFileDiffs puts split file diffs back together
+func joinFileDiffs(files []fileDiff) string {
+	var diff strings.Builder
+	for _, file := range files {
+		diff.WriteString(file.text)
+	}
+	return diff.String()
+}
+
+// truncateToTokens cuts text at the last line break within the given number
+// of tokens
+func truncateToTokens(text string, tokens int) string {
+	maxChars := tokens * 4
+	if maxChars <= 0 {
+		return ""
+	}
+	if len(text) <= maxChars {
+		return text
+	}
+	text = text[:maxChars]
+	if idx := strings.LastIndex(text, "\n"); idx > 0 {
+		text = text[:idx+1]
+	}
+	return text
+}
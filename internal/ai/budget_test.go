@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestFitToBudget(t *testing.T) {
+	smallDiff := "diff --git a/small.go b/small.go\n+small\n"
+	largeDiff := "diff --git a/large.go b/large.go\n" + strings.Repeat("+large line\n", 400)
+
+	newContext := func() *AIContext {
+		return &AIContext{
+			CommitHistory: []types.CommitInfo{
+				{Hash: "c3", Message: "Newest commit"},
+				{Hash: "c2", Message: strings.Repeat("middle ", 100)},
+				{Hash: "c1", Message: strings.Repeat("oldest ", 100)},
+			},
+			DiffSummary: "2 files changed",
+			DiffContent: smallDiff + largeDiff,
+			FileChanges: []types.FileChange{
+				{Path: "small.go", Status: types.StatusModified},
+				{Path: "large.go", Status: types.StatusModified},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		budget      int
+		wantCommits int
+		wantDiff    []string
+		wantNoDiff  []string
+		wantOmitted int
+	}{
+		{
+			name:        "no limit",
+			budget:      0,
+			wantCommits: 3,
+			wantDiff:    []string{"+small", "+large line"},
+		},
+		{
+			name:        "fits",
+			budget:      100000,
+			wantCommits: 3,
+			wantDiff:    []string{"+small", "+large line"},
+		},
+		{
+			name:        "drops the largest file diff first",
+			budget:      500,
+			wantCommits: 3,
+			wantDiff:    []string{"+small", "diff --git a/large.go b/large.go\n(diff omitted"},
+			wantNoDiff:  []string{"+large line"},
+			wantOmitted: 1,
+		},
+		{
+			name:        "then the oldest commits",
+			budget:      300,
+			wantCommits: 2,
+			wantDiff:    []string{"diff --git a/small.go b/small.go\n(diff omitted"},
+			wantNoDiff:  []string{"+small", "+large line"},
+			wantOmitted: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newContext()
+			trimmed, omitted := fitToBudget(ctx, tt.budget)
+
+			if tt.budget > 0 && contextTokens(trimmed) > tt.budget {
+				t.Errorf("contextTokens() = %d, want at most %d", contextTokens(trimmed), tt.budget)
+			}
+			if len(trimmed.CommitHistory) != tt.wantCommits {
+				t.Errorf("len(CommitHistory) = %d, want %d", len(trimmed.CommitHistory), tt.wantCommits)
+			}
+			if trimmed.CommitHistory[0].Hash != "c3" {
+				t.Errorf("CommitHistory[0] = %s, want the newest commit kept", trimmed.CommitHistory[0].Hash)
+			}
+			for _, want := range tt.wantDiff {
+				if !strings.Contains(trimmed.DiffContent, want) {
+					t.Errorf("DiffContent missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.wantNoDiff {
+				if strings.Contains(trimmed.DiffContent, unwanted) {
+					t.Errorf("DiffContent still contains %q", unwanted)
+				}
+			}
+			if len(omitted) != tt.wantOmitted {
+				t.Errorf("omitted = %v, want %d notes", omitted, tt.wantOmitted)
+			}
+
+			// The caller's context is left as it was
+			if len(ctx.CommitHistory) != 3 || ctx.DiffContent != smallDiff+largeDiff {
+				t.Error("fitToBudget() modified the original context")
+			}
+		})
+	}
+}
+
+func TestClaudeBuildPromptTrimsToBudget(t *testing.T) {
+	client := &ClaudeClient{maxTokens: 100}
+
+	ctx := &AIContext{
+		CommitHistory: []types.CommitInfo{{Hash: "abc123", Message: "Add feature"}},
+		DiffContent:   "diff --git a/a.go b/a.go\n" + strings.Repeat("+line\n", 5000) + "diff --git a/b.go b/b.go\n+b\n",
+	}
+
+	prompt := client.buildPrompt(ctx, "Generate a PR")
+
+	if got, limit := estimateTokens(prompt), 100*promptBudgetFactor; got > limit {
+		t.Errorf("prompt is ~%d tokens, want at most %d", got, limit)
+	}
+	if !strings.Contains(prompt, "Left Out To Fit The Context Window") {
+		t.Error("Prompt missing the note on what was left out")
+	}
+	if !strings.Contains(prompt, "+b") {
+		t.Error("Prompt dropped the small file's diff")
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
+	"sort"
 	"strings"
 
+	"auto-pr/internal/git"
 	"auto-pr/pkg/types"
 )
 
@@ -119,15 +122,14 @@ func (c *ClaudeClient) buildPrompt(ctx *AIContext, basePrompt string) string {
 	prompt.WriteString("You are an expert software engineer helping to create a pull request. ")
 	prompt.WriteString("Analyze the provided git changes and generate an appropriate PR title and description.\n\n")
 
+	// Leave room in the context window for the instructions and the response
+	ctx, omitted := fitToBudget(ctx, promptBudget(c.maxTokens, basePrompt))
+
 	// Add context information
 	if len(ctx.CommitHistory) > 0 {
 		prompt.WriteString("## Recent Commits:\n")
 		for _, commit := range ctx.CommitHistory {
-			hashDisplay := commit.Hash
-			if len(hashDisplay) > 8 {
-				hashDisplay = hashDisplay[:8]
-			}
-			fmt.Fprintf(&prompt, "- %s: %s\n", hashDisplay, commit.Message)
+			fmt.Fprintf(&prompt, "- %s: %s\n", git.ShortHash(commit.Hash), commit.Message)
 		}
 		prompt.WriteString("\n")
 	}
@@ -138,6 +140,12 @@ func (c *ClaudeClient) buildPrompt(ctx *AIContext, basePrompt string) string {
 		prompt.WriteString("\n\n")
 	}
 
+	if ctx.DiffContent != "" {
+		prompt.WriteString("## Diff:\n```diff\n")
+		prompt.WriteString(ctx.DiffContent)
+		prompt.WriteString("\n```\n\n")
+	}
+
 	if len(ctx.FileChanges) > 0 {
 		prompt.WriteString("## Files Changed:\n")
 		for _, file := range ctx.FileChanges {
@@ -147,6 +155,25 @@ func (c *ClaudeClient) buildPrompt(ctx *AIContext, basePrompt string) string {
 		prompt.WriteString("\n")
 	}
 
+	if len(omitted) > 0 {
+		prompt.WriteString("## Left Out To Fit The Context Window:\n")
+		for _, note := range omitted {
+			fmt.Fprintf(&prompt, "- %s\n", note)
+		}
+		prompt.WriteString("\n")
+	}
+
+	// The issue states what the changes were meant to achieve
+	if ctx.Issue != nil {
+		fmt.Fprintf(&prompt, "## Issue #%d: %s\n", ctx.Issue.Number, ctx.Issue.Title)
+		if body := strings.TrimSpace(ctx.Issue.Body); body != "" {
+			prompt.WriteString(body)
+			prompt.WriteString("\n")
+		}
+		prompt.WriteString("\nThese changes address the issue above. Treat its requirements and acceptance criteria as ")
+		prompt.WriteString("additional context: in the description, confirm each criterion the changes meet and point out any they don't.\n\n")
+	}
+
 	// Add project context
 	if ctx.ProjectContext.Language != "" {
 		fmt.Fprintf(&prompt, "## Project Info:\n- Language: %s\n", ctx.ProjectContext.Language)
@@ -171,37 +198,34 @@ func (c *ClaudeClient) buildPrompt(ctx *AIContext, basePrompt string) string {
   "priority": "low|medium|high",
   "confidence": 0.85
 }`)
+	if len(ctx.ExtraFields) > 0 {
+		prompt.WriteString("\n\nAlso include these fields in the JSON object:")
+		names := make([]string, 0, len(ctx.ExtraFields))
+		for name := range ctx.ExtraFields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&prompt, "\n- %q: %s", name, ctx.ExtraFields[name])
+		}
+	}
 	prompt.WriteString("\n\nDo not include any text before or after the JSON object.")
 
 	return prompt.String()
 }
 
+// responseFields are the JSON keys parsed into AIResponse's own fields
+var responseFields = []string{"title", "body", "labels", "reviewers", "priority", "confidence"}
+
 // parseResponse parses the Claude CLI response
 func (c *ClaudeClient) parseResponse(output string) (*AIResponse, error) {
 	// Clean the output
 	output = strings.TrimSpace(output)
 
 	// First, try to parse as direct JSON
-	var parsed struct {
-		Title      string   `json:"title"`
-		Body       string   `json:"body"`
-		Labels     []string `json:"labels"`
-		Reviewers  []string `json:"reviewers"`
-		Priority   string   `json:"priority"`
-		Confidence float32  `json:"confidence"`
-	}
-
-	if err := json.Unmarshal([]byte(output), &parsed); err == nil {
-		// Direct JSON parsing succeeded
-		return &AIResponse{
-			Title:      parsed.Title,
-			Body:       parsed.Body,
-			Labels:     parsed.Labels,
-			Reviewers:  parsed.Reviewers,
-			Priority:   parsed.Priority,
-			Confidence: parsed.Confidence,
-			TokensUsed: len(output) / 4,
-		}, nil
+	if response, err := decodeResponse(output); err == nil {
+		response.TokensUsed = len(output) / 4
+		return response, nil
 	}
 
 	// If direct parsing fails, try to extract JSON from the output
@@ -209,39 +233,15 @@ func (c *ClaudeClient) parseResponse(output string) (*AIResponse, error) {
 	end := strings.LastIndex(output, "}")
 
 	if start != -1 && end != -1 && start < end {
-		jsonStr := output[start : end+1]
-
-		if err := json.Unmarshal([]byte(jsonStr), &parsed); err == nil {
-			return &AIResponse{
-				Title:      parsed.Title,
-				Body:       parsed.Body,
-				Labels:     parsed.Labels,
-				Reviewers:  parsed.Reviewers,
-				Priority:   parsed.Priority,
-				Confidence: parsed.Confidence,
-				TokensUsed: len(output) / 4,
-			}, nil
+		if response, err := decodeResponse(output[start : end+1]); err == nil {
+			response.TokensUsed = len(output) / 4
+			return response, nil
 		}
 	}
 
-	// Last resort: try to extract title and body from the output
-	lines := strings.Split(output, "\n")
-	title := "Auto-generated PR"
-	body := output
-
-	// Look for patterns like "title:" or "# Title"
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(strings.ToLower(line))
-		if strings.HasPrefix(trimmed, "title:") {
-			title = strings.TrimSpace(line[6:]) // Skip "title:"
-		} else if strings.HasPrefix(trimmed, "# ") {
-			title = strings.TrimSpace(line[2:]) // Skip "# "
-			if i+1 < len(lines) {
-				body = strings.Join(lines[i+1:], "\n")
-			}
-			break
-		}
-	}
+	// Last resort: the CLI often answers with a markdown PR despite the
+	// JSON instruction, so take the title and body from its structure
+	title, body := extractTitleAndBody(output)
 
 	return &AIResponse{
 		Title:      title,
@@ -253,3 +253,120 @@ func (c *ClaudeClient) parseResponse(output string) (*AIResponse, error) {
 		TokensUsed: len(output) / 4,
 	}, nil
 }
+
+var (
+	titleLabelPattern   = regexp.MustCompile(`(?i)^\s*[*_]*(?:pr |pull request |mr )?title[*_]*\s*:[*_]*\s*(.+)$`)
+	bodyLabelPattern    = regexp.MustCompile(`(?i)^\s*[*_]*(?:body|description)[*_]*\s*:[*_]*\s*$`)
+	titleHeadingPattern = regexp.MustCompile(`^#{1,2}\s+(.+?)\s*#*\s*$`)
+	conventionalPattern = regexp.MustCompile(`^(?:feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(?:\([^)]*\))?!?: \S`)
+)
+
+// sectionHeadings are the generic headings of a PR description's sections,
+// which never make a title
+var sectionHeadings = map[string]bool{
+	"summary": true, "description": true, "changes": true, "overview": true, "testing": true, "test plan": true,
+}
+
+// extractTitleAndBody finds the title and body of a PR written as plain text
+// or markdown: a "Title:" line, else the first # or ## heading that isn't a
+// generic section like "Summary" (or the line under a heading that just says
+// "Title"), else a conventional-commit first
+// line. Only unstructured text gets the generic title, with all of it as body.
+func extractTitleAndBody(output string) (string, string) {
+	output = strings.TrimSpace(output)
+	if strings.HasPrefix(output, "```") && strings.HasSuffix(output, "```") {
+		output = strings.TrimSpace(strings.TrimSuffix(output[strings.Index(output, "\n")+1:], "```"))
+	}
+	lines := strings.Split(output, "\n")
+
+	found := func(title string, rest []string) (string, string) {
+		var body []string
+		for _, line := range rest {
+			if !bodyLabelPattern.MatchString(line) {
+				body = append(body, line)
+			}
+		}
+		title = strings.Trim(strings.TrimSpace(title), "*_`\"'")
+		bodyText := strings.TrimSpace(strings.Join(body, "\n"))
+		if bodyText == "" {
+			bodyText = title
+		}
+		return title, bodyText
+	}
+
+	for i, line := range lines {
+		if match := titleLabelPattern.FindStringSubmatch(line); match != nil {
+			return found(match[1], append(append([]string{}, lines[:i]...), lines[i+1:]...))
+		}
+	}
+
+	for i, line := range lines {
+		match := titleHeadingPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		heading := strings.ToLower(strings.Trim(match[1], "*_: "))
+		if heading == "title" || heading == "pr title" || heading == "pull request title" {
+			for j := i + 1; j < len(lines); j++ {
+				if next := strings.TrimSpace(lines[j]); next != "" {
+					return found(next, lines[j+1:])
+				}
+			}
+			continue
+		}
+		if sectionHeadings[heading] {
+			continue
+		}
+		return found(match[1], lines[i+1:])
+	}
+
+	for i, line := range lines {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if conventionalPattern.MatchString(line) {
+			return found(line, lines[i+1:])
+		}
+		break
+	}
+
+	return "Auto-generated PR", output
+}
+
+// decodeResponse decodes a JSON response object, keeping any fields beyond
+// the known ones in Extra
+func decodeResponse(jsonStr string) (*AIResponse, error) {
+	var parsed struct {
+		Title      string   `json:"title"`
+		Body       string   `json:"body"`
+		Labels     []string `json:"labels"`
+		Reviewers  []string `json:"reviewers"`
+		Priority   string   `json:"priority"`
+		Confidence float32  `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
+		return nil, err
+	}
+
+	response := &AIResponse{
+		Title:      parsed.Title,
+		Body:       parsed.Body,
+		Labels:     parsed.Labels,
+		Reviewers:  parsed.Reviewers,
+		Priority:   parsed.Priority,
+		Confidence: parsed.Confidence,
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &fields); err != nil {
+		return nil, err
+	}
+	for _, known := range responseFields {
+		delete(fields, known)
+	}
+	if len(fields) > 0 {
+		response.Extra = fields
+	}
+
+	return response, nil
+}
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -15,6 +16,7 @@ func TestClaudeParseResponse(t *testing.T) {
 		output      string
 		wantTitle   string
 		wantHasBody bool
+		wantBody    string // Expected start of the body, when set
 		wantErr     bool
 	}{
 		{
@@ -60,6 +62,87 @@ func TestClaudeParseResponse(t *testing.T) {
 			wantHasBody: true,
 			wantErr:     false,
 		},
+		{
+			name: "Markdown with a top-level heading",
+			output: `# Add rate limiting to the API
+
+## Summary
+Requests are now limited per token.`,
+			wantTitle:   "Add rate limiting to the API",
+			wantHasBody: true,
+			wantBody:    "## Summary",
+		},
+		{
+			name: "Markdown after a preamble with a second-level heading",
+			output: `Here is the pull request:
+
+## **Fix crash on empty config**
+
+The loader no longer panics.`,
+			wantTitle:   "Fix crash on empty config",
+			wantHasBody: true,
+			wantBody:    "The loader no longer panics.",
+		},
+		{
+			name: "Markdown with a Title heading",
+			output: `## Title
+Improve error messages
+
+## Description
+Errors now include the CLI output.`,
+			wantTitle:   "Improve error messages",
+			wantHasBody: true,
+			wantBody:    "## Description",
+		},
+		{
+			name: "Markdown with a section heading before the title",
+			output: `## Summary
+
+# Retry failed uploads
+
+## Changes
+Uploads are retried on 5xx responses.`,
+			wantTitle:   "Retry failed uploads",
+			wantHasBody: true,
+			wantBody:    "## Changes",
+		},
+		{
+			name: "Markdown with only section headings",
+			output: `## Summary
+Uploads are retried on 5xx responses.
+
+## Testing
+Ran the upload tests.`,
+			wantTitle:   "Auto-generated PR",
+			wantHasBody: true,
+			wantBody:    "## Summary",
+		},
+		{
+			name: "Bold title label and description label",
+			output: `**Title:** Speed up diff parsing
+
+**Description:**
+Parsing no longer copies each hunk.`,
+			wantTitle:   "Speed up diff parsing",
+			wantHasBody: true,
+			wantBody:    "Parsing no longer copies each hunk.",
+		},
+		{
+			name: "Conventional commit first line",
+			output: `feat(cli): add --edit flag
+
+Opens the message in the editor.`,
+			wantTitle:   "feat(cli): add --edit flag",
+			wantHasBody: true,
+			wantBody:    "Opens the message in the editor.",
+		},
+		{
+			name:        "Markdown in a code fence",
+			output:      "```markdown\n# Bump dependencies\n\nUpdates cobra.\n```",
+			wantTitle:   "Bump dependencies",
+			wantHasBody: true,
+			wantBody:    "Updates cobra.",
+		},
 		{
 			name:        "Plain text without structure",
 			output:      "This is just some plain text response without any structure",
@@ -84,6 +167,10 @@ func TestClaudeParseResponse(t *testing.T) {
 			if tt.wantHasBody && resp.Body == "" {
 				t.Error("parseResponse() Body is empty, want non-empty")
 			}
+
+			if tt.wantBody != "" && !strings.HasPrefix(resp.Body, tt.wantBody) {
+				t.Errorf("parseResponse() Body = %q, want it to start with %q", resp.Body, tt.wantBody)
+			}
 		})
 	}
 }
@@ -97,6 +184,7 @@ func TestClaudeBuildPrompt(t *testing.T) {
 			{Hash: "def456", Message: "Add feature"},
 		},
 		DiffSummary: "2 files changed, 50 additions, 10 deletions",
+		DiffContent: "diff --git a/main.go b/main.go\n+func main() {}",
 		FileChanges: []types.FileChange{
 			{Path: "main.go", Status: types.StatusModified, Additions: 40, Deletions: 5},
 			{Path: "README.md", Status: types.StatusModified, Additions: 10, Deletions: 5},
@@ -116,6 +204,9 @@ func TestClaudeBuildPrompt(t *testing.T) {
 	if !strings.Contains(prompt, "Changes Summary:") {
 		t.Error("Prompt missing Changes Summary section")
 	}
+	if !strings.Contains(prompt, "## Diff:") {
+		t.Error("Prompt missing Diff section")
+	}
 	if !strings.Contains(prompt, "Files Changed:") {
 		t.Error("Prompt missing Files Changed section")
 	}
@@ -126,3 +217,75 @@ func TestClaudeBuildPrompt(t *testing.T) {
 		t.Error("Prompt missing JSON format instruction")
 	}
 }
+
+func TestClaudeParseResponseExtraFields(t *testing.T) {
+	client := &ClaudeClient{}
+
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]interface{}
+	}{
+		{
+			name:   "Unknown fields are kept",
+			output: `{"title": "Fix crash", "body": "Body", "risk": "low", "rollback_steps": "Revert the commit"}`,
+			want:   map[string]interface{}{"risk": "low", "rollback_steps": "Revert the commit"},
+		},
+		{
+			name:   "Embedded JSON keeps unknown fields",
+			output: "Sure:\n{\"title\": \"Fix crash\", \"body\": \"Body\", \"risk\": \"high\"}",
+			want:   map[string]interface{}{"risk": "high"},
+		},
+		{
+			name:   "Only known fields",
+			output: `{"title": "Fix crash", "body": "Body", "labels": ["bug"], "confidence": 0.9}`,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.parseResponse(tt.output)
+			if err != nil {
+				t.Fatalf("parseResponse() error = %v", err)
+			}
+			if resp.Title != "Fix crash" {
+				t.Errorf("parseResponse() Title = %v, want Fix crash", resp.Title)
+			}
+			if !reflect.DeepEqual(resp.Extra, tt.want) {
+				t.Errorf("parseResponse() Extra = %v, want %v", resp.Extra, tt.want)
+			}
+		})
+	}
+
+	prompt := client.buildPrompt(&AIContext{ExtraFields: map[string]string{"risk": "Risk of the change"}}, "Generate a PR")
+	if !strings.Contains(prompt, `- "risk": Risk of the change`) {
+		t.Error("Prompt missing requested extra field")
+	}
+}
+
+func TestClaudeBuildPromptIncludesIssue(t *testing.T) {
+	client := &ClaudeClient{}
+
+	ctx := &AIContext{
+		CommitHistory: []types.CommitInfo{{Hash: "abc123", Message: "Add CSV export"}},
+		Issue: &types.Issue{
+			Number: 42,
+			Title:  "Export reports as CSV",
+			Body:   "- [ ] Adds an export button\n- [ ] Streams large reports",
+		},
+	}
+
+	prompt := client.buildPrompt(ctx, "Generate a PR")
+	if !strings.Contains(prompt, "## Issue #42: Export reports as CSV") {
+		t.Error("Prompt missing the issue heading")
+	}
+	if !strings.Contains(prompt, "- [ ] Streams large reports") {
+		t.Error("Prompt missing the issue description")
+	}
+
+	ctx.Issue = nil
+	if prompt := client.buildPrompt(ctx, "Generate a PR"); strings.Contains(prompt, "## Issue") {
+		t.Error("Prompt has an issue section without an issue")
+	}
+}
@@ -7,8 +7,24 @@ import (
 	"auto-pr/pkg/types"
 )
 
+// clientFactory, when set, replaces the provider switch in NewClient
+var clientFactory func(config types.AIConfig) (AIClient, error)
+
+// SetClientFactory makes NewClient return clients from factory instead of
+// the configured provider, so tests can run whole commands against a
+// MockClient. It returns a function that restores the previous factory.
+func SetClientFactory(factory func(config types.AIConfig) (AIClient, error)) (restore func()) {
+	previous := clientFactory
+	clientFactory = factory
+	return func() { clientFactory = previous }
+}
+
 // NewClient creates a new AI client based on the configuration
 func NewClient(config types.AIConfig) (AIClient, error) {
+	if clientFactory != nil {
+		return clientFactory(config)
+	}
+
 	switch config.Provider {
 	case types.AIProviderClaude:
 		return NewClaudeClient(config.Claude)
@@ -35,6 +51,28 @@ func GetAvailableProviders() []types.AIProvider {
 	return providers
 }
 
+// ResolveProvider validates an explicitly requested provider and resolves
+// "auto" to an available one, returning an error instead of falling back
+func ResolveProvider(requested string) (types.AIProvider, error) {
+	switch requested {
+	case string(types.AIProviderClaude):
+		if !isClaudeAvailable() {
+			return "", fmt.Errorf("claude CLI not found in PATH. Install Claude Code or set ai.claude.cli_path")
+		}
+		return types.AIProviderClaude, nil
+	case "auto":
+		providers := GetAvailableProviders()
+		if len(providers) == 0 {
+			return "", fmt.Errorf("no AI provider available. Install Claude Code (claude CLI)")
+		}
+		return providers[0], nil
+	case "gemini", "openai":
+		return "", fmt.Errorf("%s provider is not supported. Use claude or auto instead", requested)
+	default:
+		return "", fmt.Errorf("invalid AI provider: %s (expected claude, gemini, openai or auto)", requested)
+	}
+}
+
 // DetectBestProvider returns the recommended AI provider based on availability
 func DetectBestProvider() types.AIProvider {
 	return types.AIProviderClaude
@@ -21,12 +21,22 @@ type AIClient interface {
 type AIContext struct {
 	CommitHistory  []types.CommitInfo
 	DiffSummary    string
+	DiffContent    string
 	FileChanges    []types.FileChange
 	BranchInfo     types.BranchInfo
 	ProjectContext ProjectContext
 	PreviousPRs    []types.PullRequest
 	Platform       types.PlatformType
 	TemplateType   types.TemplateType
+	// ChangeType is a conventional commit type (feat, fix, ...) the user chose,
+	// which takes precedence over the change type detected from the changes
+	ChangeType string
+	// ExtraFields names additional response fields to ask for, mapped to a
+	// description of what they should contain
+	ExtraFields map[string]string
+	// Issue is the issue the changes address, whose requirements and
+	// acceptance criteria the description should confirm
+	Issue *types.Issue
 }
 
 // ProjectContext contains information about the project
@@ -49,6 +59,9 @@ type AIResponse struct {
 	Confidence float32
 	TokensUsed int
 	Provider   types.AIProvider
+	// Extra holds any fields in the response beyond the ones above, such as
+	// those requested through AIContext.ExtraFields
+	Extra map[string]interface{}
 }
 
 // PromptTemplate represents a template for AI prompts
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"sync"
+
+	"auto-pr/pkg/types"
+)
+
+// MockCall records one GenerateContent call made to a MockClient
+type MockCall struct {
+	Context *AIContext
+	Prompt  string
+}
+
+// MockClient is an AIClient that returns a canned response without calling
+// any AI, recording the calls made to it. Install it with SetClientFactory
+// to exercise a whole command in tests.
+type MockClient struct {
+	Response *AIResponse // Returned, as a copy, by every call
+	Err      error       // Returned instead of the response when set
+
+	mu    sync.Mutex
+	calls []MockCall
+}
+
+// NewMockClient creates a mock client that always returns response
+func NewMockClient(response *AIResponse) *MockClient {
+	return &MockClient{Response: response}
+}
+
+// GenerateContent records the call and returns a copy of the canned response,
+// so callers that edit the response don't change what later calls get
+func (m *MockClient) GenerateContent(ctx *AIContext, prompt string) (*AIResponse, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, MockCall{Context: ctx, Prompt: prompt})
+	m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	response := AIResponse{}
+	if m.Response != nil {
+		response = *m.Response
+	}
+	response.Labels = append([]string(nil), response.Labels...)
+	response.Reviewers = append([]string(nil), response.Reviewers...)
+	if response.Provider == "" {
+		response.Provider = types.AIProviderClaude
+	}
+	return &response, nil
+}
+
+// Calls returns the GenerateContent calls made so far, oldest first
+func (m *MockClient) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// IsAvailable always reports true
+func (m *MockClient) IsAvailable() bool {
+	return true
+}
+
+// GetProvider returns types.AIProviderClaude, the provider the mock stands in for
+func (m *MockClient) GetProvider() types.AIProvider {
+	return types.AIProviderClaude
+}
+
+// ValidateConfig has nothing to validate
+func (m *MockClient) ValidateConfig() error {
+	return nil
+}
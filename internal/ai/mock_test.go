@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestMockClient(t *testing.T) {
+	mock := NewMockClient(&AIResponse{Title: "Canned title", Labels: []string{"bug"}})
+	restore := SetClientFactory(func(types.AIConfig) (AIClient, error) { return mock, nil })
+
+	client, err := NewClient(types.AIConfig{Provider: types.AIProviderClaude})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := &AIContext{DiffSummary: "1 file changed"}
+	first, err := client.GenerateContent(ctx, "first prompt")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	first.Labels[0] = "edited"
+
+	second, _ := client.GenerateContent(ctx, "second prompt")
+	if second.Title != "Canned title" || second.Labels[0] != "bug" {
+		t.Errorf("GenerateContent() = %+v, want the canned response unchanged", second)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Prompt != "first prompt" || calls[1].Context != ctx {
+		t.Errorf("Calls() = %+v, want both calls in order", calls)
+	}
+
+	mock.Err = errors.New("rate limited")
+	if _, err := client.GenerateContent(ctx, "third prompt"); err == nil {
+		t.Error("GenerateContent() ignored the configured error")
+	}
+
+	restore()
+	if _, err := NewClient(types.AIConfig{Provider: "unknown"}); err == nil {
+		t.Error("NewClient() still used the factory after restore")
+	}
+}
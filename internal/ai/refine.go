@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Refine asks client to critique and improve draft against ctx, feeding each
+// improved version back for up to iterations rounds. It stops early once a
+// round leaves the title and body unchanged. Fields a round leaves empty keep
+// their previous value, so a weak answer can't erase a good draft.
+func Refine(client AIClient, ctx *AIContext, draft *AIResponse, iterations int) (*AIResponse, error) {
+	current := draft
+	for round := 0; round < iterations; round++ {
+		improved, err := client.GenerateContent(ctx, refinePrompt(current))
+		if err != nil {
+			return nil, fmt.Errorf("refinement round %d failed: %w", round+1, err)
+		}
+		improved = keepDraftFields(current, improved)
+		if improved.Title == current.Title && improved.Body == current.Body {
+			break
+		}
+		current = improved
+	}
+	return current, nil
+}
+
+// refinePrompt asks for a critique of draft followed by an improved version
+func refinePrompt(draft *AIResponse) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("Below is a draft pull request title and description for the changes above. ")
+	prompt.WriteString("Critique it against the commits and diff, then return an improved version. Check that:\n")
+	prompt.WriteString("- it is clear and concise, and a reviewer can tell what changed and why\n")
+	prompt.WriteString("- it doesn't leave out context a reviewer needs, such as motivation, risks or how it was tested\n")
+	prompt.WriteString("- its scope is accurate: nothing the changes don't do, nothing significant they do missing\n")
+	prompt.WriteString("Keep what is already good. Put the improved title and description in the JSON fields, not the critique.\n\n")
+
+	fmt.Fprintf(&prompt, "### Draft Title:\n%s\n\n", draft.Title)
+	fmt.Fprintf(&prompt, "### Draft Description:\n%s\n", draft.Body)
+	if len(draft.Labels) > 0 {
+		fmt.Fprintf(&prompt, "\n### Draft Labels:\n%s\n", strings.Join(draft.Labels, ", "))
+	}
+
+	return prompt.String()
+}
+
+// keepDraftFields fills the fields improved left empty from draft
+func keepDraftFields(draft, improved *AIResponse) *AIResponse {
+	merged := *improved
+	if strings.TrimSpace(merged.Title) == "" {
+		merged.Title = draft.Title
+	}
+	if strings.TrimSpace(merged.Body) == "" {
+		merged.Body = draft.Body
+	}
+	if len(merged.Labels) == 0 {
+		merged.Labels = draft.Labels
+	}
+	if len(merged.Reviewers) == 0 {
+		merged.Reviewers = draft.Reviewers
+	}
+	if merged.Priority == "" {
+		merged.Priority = draft.Priority
+	}
+	if len(merged.Extra) == 0 {
+		merged.Extra = draft.Extra
+	}
+	merged.TokensUsed += draft.TokensUsed
+	return &merged
+}
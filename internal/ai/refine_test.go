@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+// scriptedClient answers GenerateContent with its responses in turn and
+// records the prompts it was given
+type scriptedClient struct {
+	responses []*AIResponse
+	prompts   []string
+}
+
+func (s *scriptedClient) GenerateContent(ctx *AIContext, prompt string) (*AIResponse, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+
+func (s *scriptedClient) IsAvailable() bool             { return true }
+func (s *scriptedClient) GetProvider() types.AIProvider { return types.AIProviderClaude }
+func (s *scriptedClient) ValidateConfig() error         { return nil }
+
+func TestRefine(t *testing.T) {
+	draft := &AIResponse{Title: "Update stuff", Body: "Changes things", Labels: []string{"feature"}, Reviewers: []string{"alice"}}
+
+	tests := []struct {
+		name       string
+		responses  []*AIResponse
+		iterations int
+		wantTitle  string
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "No iterations keeps the draft",
+			iterations: 0,
+			wantTitle:  "Update stuff",
+			wantCalls:  0,
+		},
+		{
+			name: "Stops at the iteration cap",
+			responses: []*AIResponse{
+				{Title: "Add retry to uploads", Body: "Retries failed uploads"},
+				{Title: "Retry failed uploads", Body: "Retries failed uploads up to 3 times"},
+				{Title: "Never reached", Body: "Never reached"},
+			},
+			iterations: 2,
+			wantTitle:  "Retry failed uploads",
+			wantCalls:  2,
+		},
+		{
+			name: "Stops once a round changes nothing",
+			responses: []*AIResponse{
+				{Title: "Add retry to uploads", Body: "Retries failed uploads"},
+				{Title: "Add retry to uploads", Body: "Retries failed uploads"},
+				{Title: "Never reached", Body: "Never reached"},
+			},
+			iterations: 3,
+			wantTitle:  "Add retry to uploads",
+			wantCalls:  2,
+		},
+		{
+			name:       "Empty answer keeps the draft",
+			responses:  []*AIResponse{{}},
+			iterations: 1,
+			wantTitle:  "Update stuff",
+			wantCalls:  1,
+		},
+		{
+			name:       "Failed call",
+			iterations: 1,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &scriptedClient{responses: tt.responses}
+			got, err := Refine(client, &AIContext{}, draft, tt.iterations)
+
+			if len(client.prompts) != tt.wantCalls {
+				t.Errorf("Refine() made %d calls, want %d", len(client.prompts), tt.wantCalls)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Refine() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Refine() error = %v", err)
+			}
+			if got.Title != tt.wantTitle {
+				t.Errorf("Refine() title = %q, want %q", got.Title, tt.wantTitle)
+			}
+			if len(got.Reviewers) == 0 || got.Reviewers[0] != "alice" {
+				t.Errorf("Refine() reviewers = %v, want the draft's kept", got.Reviewers)
+			}
+		})
+	}
+}
+
+func TestRefinePromptIncludesDraft(t *testing.T) {
+	prompt := refinePrompt(&AIResponse{Title: "Add retries", Body: "Retries uploads", Labels: []string{"feature"}})
+
+	for _, want := range []string{"Critique", "Add retries", "Retries uploads", "feature"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("refinePrompt() missing %q", want)
+		}
+	}
+}
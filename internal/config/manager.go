@@ -2,9 +2,17 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 
+	"auto-pr/internal/ui"
 	"auto-pr/pkg/types"
 
 	"github.com/spf13/viper"
@@ -14,11 +22,11 @@ import (
 // LoadConfig loads configuration from file
 func LoadConfig(configPath string) (*types.Config, error) {
 	if configPath == "" {
-		home, err := os.UserHomeDir()
+		defaultPath, err := DefaultConfigPath()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+			return nil, err
 		}
-		configPath = filepath.Join(home, ".auto-pr", "config.yaml")
+		configPath = defaultPath
 	}
 
 	// Check if config file exists
@@ -37,9 +45,11 @@ func LoadConfig(configPath string) (*types.Config, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	warnUnknownKeys(configPath)
 
 	// Merge with defaults
 	mergeWithDefaults(&config)
+	warnVersion(&config)
 
 	return &config, nil
 }
@@ -66,8 +76,83 @@ func WriteConfig(configPath string, config *types.Config) error {
 	return nil
 }
 
+// warningOutput is where configuration warnings are printed
+var warningOutput io.Writer = os.Stderr
+
+// warned remembers the warnings already printed, since the configuration is
+// loaded several times in one run
+var warned sync.Map
+
+// warnOnce prints a configuration warning unless it was already printed
+func warnOnce(warning string) {
+	if _, seen := warned.LoadOrStore(warning, true); !seen {
+		fmt.Fprintf(warningOutput, "%s %s\n", ui.Warning, warning)
+	}
+}
+
+// versionWarning describes the problem with a config file's schema version,
+// or returns an empty string when it is the current one
+func versionWarning(version int) string {
+	switch {
+	case version < CurrentConfigVersion:
+		return fmt.Sprintf("config file predates the current format (version %d, current %d). Run 'auto-pr config migrate' to upgrade it", version, CurrentConfigVersion)
+	case version > CurrentConfigVersion:
+		return fmt.Sprintf("config file is version %d, newer than this auto-pr supports (%d); some settings may be ignored", version, CurrentConfigVersion)
+	}
+	return ""
+}
+
+// warnVersion warns about an outdated or unknown schema version. Neither is
+// an error, as most settings still apply.
+func warnVersion(config *types.Config) {
+	if warning := versionWarning(config.Version); warning != "" {
+		warnOnce(warning)
+	}
+}
+
+// minTitleMaxLength is the shortest platforms.title_max_length allowed
+const minTitleMaxLength = 20
+
+// globalSettings are top-level keys read straight from viper rather than
+// through types.Config, which a config file may also set
+var globalSettings = map[string]bool{"no_emoji": true, "verbose": true}
+
+// FindUnknownKeys lists the keys in a config file that auto-pr doesn't
+// read, such as misspelled settings, as dotted paths
+func FindUnknownKeys(configPath string) ([]string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	var unknown []string
+	for _, key := range unknownKeys(raw, reflect.TypeOf(types.Config{}), "") {
+		if !globalSettings[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown, nil
+}
+
+// warnUnknownKeys warns about each key in the config file that is ignored
+func warnUnknownKeys(configPath string) {
+	keys, err := FindUnknownKeys(configPath)
+	if err != nil {
+		return
+	}
+	for _, key := range keys {
+		warnOnce(fmt.Sprintf("unknown config key %s is ignored; check its spelling", key))
+	}
+}
+
 // ValidateConfig validates a configuration
 func ValidateConfig(config *types.Config) error {
+	warnVersion(config)
+
 	// Validate AI configuration
 	if err := validateAIConfig(&config.AI); err != nil {
 		return fmt.Errorf("AI configuration error: %w", err)
@@ -78,6 +163,25 @@ func ValidateConfig(config *types.Config) error {
 		return fmt.Errorf("git configuration error: %w", err)
 	}
 
+	// Validate template configuration
+	if err := validateTemplateConfig(&config.Templates); err != nil {
+		return fmt.Errorf("template configuration error: %w", err)
+	}
+
+	// Validate label configuration
+	if err := validateLabelConfig(&config.Platforms.Labels); err != nil {
+		return fmt.Errorf("label configuration error: %w", err)
+	}
+
+	if err := validateTypeDefaults(config.TypeDefaults); err != nil {
+		return fmt.Errorf("type_defaults configuration error: %w", err)
+	}
+
+	// Shorter limits would cut most titles to a word or two
+	if config.Platforms.TitleMaxLength > 0 && config.Platforms.TitleMaxLength < minTitleMaxLength {
+		return fmt.Errorf("title_max_length must be at least %d, or negative to turn the limit off, got %d", minTitleMaxLength, config.Platforms.TitleMaxLength)
+	}
+
 	return nil
 }
 
@@ -90,7 +194,7 @@ func validateAIConfig(ai *types.AIConfig) error {
 	case "", "auto":
 		ai.Provider = types.AIProviderClaude // Default to Claude or convert auto to Claude
 	case "gemini":
-		return fmt.Errorf("gemini provider is no longer supported. Please use Claude Code instead")
+		return fmt.Errorf("gemini provider is no longer supported. Please use Claude Code instead, or run 'auto-pr config migrate'")
 	default:
 		return fmt.Errorf("invalid AI provider: %s", ai.Provider)
 	}
@@ -105,6 +209,11 @@ func validateAIConfig(ai *types.AIConfig) error {
 		return fmt.Errorf("temperature must be between 0 and 2, got %f", ai.Temperature)
 	}
 
+	// Validate refinement rounds (0 means use default)
+	if ai.RefineIterations < 0 || ai.RefineIterations > 5 {
+		return fmt.Errorf("refine_iterations must be between 0 (default) and 5, got %d", ai.RefineIterations)
+	}
+
 	// Validate Claude configuration
 	if ai.Provider == types.AIProviderClaude {
 		if ai.Claude.MaxTokens < 0 {
@@ -130,16 +239,96 @@ func validateGitConfig(git *types.GitConfig) error {
 		return fmt.Errorf("max_diff_size must be non-negative, got %d", git.MaxDiffSize)
 	}
 
+	for _, pattern := range git.ExcludeCommitPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("exclude_commit_patterns: invalid regular expression %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// validateLabelConfig validates label configuration
+func validateLabelConfig(labels *types.LabelConfig) error {
+	previous := 0
+	for i, threshold := range labels.SizeThresholds {
+		if strings.TrimSpace(threshold.Label) == "" {
+			return fmt.Errorf("size_thresholds[%d]: label must not be empty", i)
+		}
+		if threshold.Below < 0 {
+			return fmt.Errorf("size_thresholds[%d]: below must be positive, got %d", i, threshold.Below)
+		}
+		// Thresholds are checked in order, so each must allow more lines than the last
+		if threshold.Below == 0 {
+			if i != len(labels.SizeThresholds)-1 {
+				return fmt.Errorf("size_thresholds[%d]: only the last threshold may leave out below", i)
+			}
+			continue
+		}
+		if threshold.Below <= previous {
+			return fmt.Errorf("size_thresholds[%d]: below must be larger than the threshold before it, got %d", i, threshold.Below)
+		}
+		previous = threshold.Below
+	}
+	return nil
+}
+
+// validateTemplateConfig validates template configuration
+func validateTemplateConfig(templates *types.TemplateConfig) error {
+	for i, rule := range templates.PathRules {
+		if strings.TrimSpace(rule.Match) == "" {
+			return fmt.Errorf("path_rules[%d]: match must not be empty", i)
+		}
+		for _, segment := range strings.Split(rule.Match, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("path_rules[%d]: invalid match pattern %q: %w", i, rule.Match, err)
+			}
+		}
+		if rule.Template == "" && len(rule.Labels) == 0 {
+			return fmt.Errorf("path_rules[%d]: %q sets neither a template nor labels", i, rule.Match)
+		}
+	}
+
+	return nil
+}
+
+// changeTypes are the kinds of change create detects, which type_defaults
+// can set labels and reviewers for
+var changeTypes = []string{"feature", "bugfix", "hotfix", "refactor", "docs", "test", "deps"}
+
+// validateTypeDefaults checks that every type_defaults entry names a known
+// change type and adds something
+func validateTypeDefaults(defaults map[string]types.TypeDefault) error {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		known := false
+		for _, changeType := range changeTypes {
+			known = known || name == changeType
+		}
+		if !known {
+			return fmt.Errorf("unknown change type %q, expected one of: %s", name, strings.Join(changeTypes, ", "))
+		}
+		if entry := defaults[name]; len(entry.Labels) == 0 && len(entry.Reviewers) == 0 {
+			return fmt.Errorf("%s sets neither labels nor reviewers", name)
+		}
+	}
 	return nil
 }
 
 // getDefaultConfig returns default configuration
 func getDefaultConfig() *types.Config {
 	return &types.Config{
+		Version: CurrentConfigVersion,
 		AI: types.AIConfig{
-			Provider:    types.AIProviderClaude,
-			MaxTokens:   4096,
-			Temperature: 0.7,
+			Provider:         types.AIProviderClaude,
+			MaxTokens:        4096,
+			Temperature:      0.7,
+			RefineIterations: 1,
 			Claude: types.ClaudeConfig{
 				CLIPath:    "claude",
 				Model:      "claude-3-5-sonnet-20241022",
@@ -160,6 +349,14 @@ func getDefaultConfig() *types.Config {
 				MergeWhenPipelineSucceeds: false,
 				RemoveSourceBranch:        true,
 			},
+			Labels: types.LabelConfig{
+				SizeThresholds: []types.SizeThreshold{
+					{Label: "size/S", Below: 50},
+					{Label: "size/M", Below: 250},
+					{Label: "size/L"},
+				},
+			},
+			TitleMaxLength: 72,
 		},
 		Templates: types.TemplateConfig{
 			Feature:           "feature-template",
@@ -167,10 +364,13 @@ func getDefaultConfig() *types.Config {
 			CustomTemplateDir: "~/.auto-pr/templates",
 		},
 		Git: types.GitConfig{
-			CommitLimit:    10,
-			DiffContext:    3,
-			IgnorePatterns: []string{"*.log", "node_modules/", "*.tmp"},
-			MaxDiffSize:    10000,
+			CommitLimit:            10,
+			DiffContext:            3,
+			IgnorePatterns:         []string{"*.log", "node_modules/", "*.tmp"},
+			MaxDiffSize:            10000,
+			ProtectedBranches:      []string{"main", "master", "release/*"},
+			BaseBranchCandidates:   []string{"main", "master", "develop"},
+			ContextExcludePatterns: []string{"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock", "Gemfile.lock", "poetry.lock", "composer.lock", "Pipfile.lock"},
 		},
 	}
 }
@@ -184,6 +384,16 @@ func LoadConfigWithViper() (*types.Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if path := viper.ConfigFileUsed(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			// A config file without a version predates versioning
+			if !viper.IsSet("version") {
+				config.Version = 0
+			}
+			warnUnknownKeys(path)
+		}
+	}
+
 	// Apply environment variable overrides that viper might have loaded
 	applyEnvOverrides(config)
 
@@ -211,6 +421,24 @@ func applyEnvOverrides(config *types.Config) {
 	if temp := viper.GetFloat64("ai.temperature"); temp > 0 {
 		config.AI.Temperature = float32(temp)
 	}
+	if iterations := viper.GetInt("ai.refine_iterations"); iterations > 0 {
+		config.AI.RefineIterations = iterations
+	}
+
+	if viper.IsSet("ai.extra_fields") {
+		config.AI.ExtraFields = viper.GetStringMapString("ai.extra_fields")
+	}
+
+	// Platform config overrides
+	if viper.GetBool("platforms.github.use_codeowners") {
+		config.Platforms.GitHub.UseCodeowners = true
+	}
+	if viper.GetBool("platforms.gitlab.use_codeowners") {
+		config.Platforms.GitLab.UseCodeowners = true
+	}
+	if viper.IsSet("platforms.title_max_length") {
+		config.Platforms.TitleMaxLength = viper.GetInt("platforms.title_max_length")
+	}
 
 	// Git config overrides
 	if commitLimit := viper.GetInt("git.commit_limit"); commitLimit > 0 {
@@ -222,6 +450,72 @@ func applyEnvOverrides(config *types.Config) {
 	if maxDiffSize := viper.GetInt("git.max_diff_size"); maxDiffSize > 0 {
 		config.Git.MaxDiffSize = maxDiffSize
 	}
+	if viper.GetBool("git.detailed_commits") {
+		config.Git.DetailedCommits = true
+	}
+	if viper.GetBool("git.include_generated") {
+		config.Git.IncludeGenerated = true
+	}
+	if codeownersPath := viper.GetString("git.codeowners_path"); codeownersPath != "" {
+		config.Git.CodeownersPath = codeownersPath
+	}
+	if viper.IsSet("git.protected_branches") {
+		config.Git.ProtectedBranches = splitList(viper.GetStringSlice("git.protected_branches"))
+	}
+	if viper.IsSet("git.context_exclude_patterns") {
+		config.Git.ContextExcludePatterns = splitList(viper.GetStringSlice("git.context_exclude_patterns"))
+	}
+	if viper.IsSet("git.base_branch_candidates") {
+		config.Git.BaseBranchCandidates = splitList(viper.GetStringSlice("git.base_branch_candidates"))
+	}
+	if testCommand := viper.GetString("git.test_command"); testCommand != "" {
+		config.Git.TestCommand = testCommand
+	}
+	if promptTemplate := viper.GetString("git.commit_prompt_template"); promptTemplate != "" {
+		config.Git.CommitPromptTemplate = promptTemplate
+	}
+	if viper.IsSet("git.exclude_commit_authors") {
+		config.Git.ExcludeCommitAuthors = splitList(viper.GetStringSlice("git.exclude_commit_authors"))
+	}
+	if viper.IsSet("git.exclude_commit_patterns") {
+		config.Git.ExcludeCommitPatterns = viper.GetStringSlice("git.exclude_commit_patterns")
+	}
+	if viper.IsSet("git.auto_push") {
+		autoPush := viper.GetBool("git.auto_push")
+		config.Git.AutoPush = &autoPush
+	}
+
+	// Viper doesn't know the yaml names of the fields, so decode these as yaml
+	if viper.IsSet("platforms.labels.size_thresholds") {
+		if data, err := yaml.Marshal(viper.Get("platforms.labels.size_thresholds")); err == nil {
+			thresholds := []types.SizeThreshold{}
+			if err := yaml.Unmarshal(data, &thresholds); err == nil {
+				config.Platforms.Labels.SizeThresholds = thresholds
+			}
+		}
+	}
+	if viper.IsSet("type_defaults") {
+		if data, err := yaml.Marshal(viper.Get("type_defaults")); err == nil {
+			defaults := map[string]types.TypeDefault{}
+			if err := yaml.Unmarshal(data, &defaults); err == nil {
+				config.TypeDefaults = defaults
+			}
+		}
+	}
+}
+
+// splitList flattens comma-separated entries, as given in environment
+// variables, into a list of trimmed values
+func splitList(values []string) []string {
+	list := []string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
 }
 
 // mergeWithDefaults merges configuration with defaults
@@ -238,6 +532,9 @@ func mergeWithDefaults(config *types.Config) {
 	if config.AI.Provider == "" {
 		config.AI.Provider = defaults.AI.Provider
 	}
+	if config.AI.RefineIterations == 0 {
+		config.AI.RefineIterations = defaults.AI.RefineIterations
+	}
 
 	// Merge Claude config
 	if config.AI.Claude.CLIPath == "" {
@@ -264,8 +561,26 @@ func mergeWithDefaults(config *types.Config) {
 	if len(config.Git.IgnorePatterns) == 0 {
 		config.Git.IgnorePatterns = defaults.Git.IgnorePatterns
 	}
+	// An explicit empty list turns the protection off
+	if config.Git.ProtectedBranches == nil {
+		config.Git.ProtectedBranches = defaults.Git.ProtectedBranches
+	}
+	// An explicit empty list sends lockfile diffs to the AI too
+	if config.Git.ContextExcludePatterns == nil {
+		config.Git.ContextExcludePatterns = defaults.Git.ContextExcludePatterns
+	}
+	if len(config.Git.BaseBranchCandidates) == 0 {
+		config.Git.BaseBranchCandidates = defaults.Git.BaseBranchCandidates
+	}
 
 	// Merge platform config defaults
+	if config.Platforms.TitleMaxLength == 0 {
+		config.Platforms.TitleMaxLength = defaults.Platforms.TitleMaxLength
+	}
+	// An explicit empty list turns size labels off
+	if config.Platforms.Labels.SizeThresholds == nil {
+		config.Platforms.Labels.SizeThresholds = defaults.Platforms.Labels.SizeThresholds
+	}
 	if len(config.Platforms.GitHub.Labels) == 0 {
 		config.Platforms.GitHub.Labels = defaults.Platforms.GitHub.Labels
 	}
@@ -1,11 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"auto-pr/pkg/types"
+
+	"github.com/spf13/viper"
 )
 
 func TestValidateConfig(t *testing.T) {
@@ -94,6 +99,106 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Valid path rule",
+			config: &types.Config{
+				AI: types.AIConfig{Provider: types.AIProviderClaude},
+				Templates: types.TemplateConfig{
+					PathRules: []types.PathRule{{Match: "services/payments/**", Template: "payments", Labels: []string{"team-payments"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Path rule with a malformed pattern",
+			config: &types.Config{
+				AI: types.AIConfig{Provider: types.AIProviderClaude},
+				Templates: types.TemplateConfig{
+					PathRules: []types.PathRule{{Match: "services/[payments/**", Template: "payments"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Path rule without template or labels",
+			config: &types.Config{
+				AI: types.AIConfig{Provider: types.AIProviderClaude},
+				Templates: types.TemplateConfig{
+					PathRules: []types.PathRule{{Match: "services/payments/**"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid size thresholds",
+			config: &types.Config{
+				AI: types.AIConfig{Provider: types.AIProviderClaude},
+				Platforms: types.PlatformConfig{
+					Labels: types.LabelConfig{SizeThresholds: []types.SizeThreshold{{Label: "size/S", Below: 50}, {Label: "size/L"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Size thresholds out of order",
+			config: &types.Config{
+				AI: types.AIConfig{Provider: types.AIProviderClaude},
+				Platforms: types.PlatformConfig{
+					Labels: types.LabelConfig{SizeThresholds: []types.SizeThreshold{{Label: "size/M", Below: 250}, {Label: "size/S", Below: 50}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Size threshold without below before the last",
+			config: &types.Config{
+				AI: types.AIConfig{Provider: types.AIProviderClaude},
+				Platforms: types.PlatformConfig{
+					Labels: types.LabelConfig{SizeThresholds: []types.SizeThreshold{{Label: "size/L"}, {Label: "size/XL", Below: 1000}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Title limit turned off",
+			config: &types.Config{
+				AI:        types.AIConfig{Provider: types.AIProviderClaude},
+				Platforms: types.PlatformConfig{TitleMaxLength: -1},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Title limit too short",
+			config: &types.Config{
+				AI:        types.AIConfig{Provider: types.AIProviderClaude},
+				Platforms: types.PlatformConfig{TitleMaxLength: 10},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Type defaults",
+			config: &types.Config{
+				AI:           types.AIConfig{Provider: types.AIProviderClaude},
+				TypeDefaults: map[string]types.TypeDefault{"hotfix": {Labels: []string{"urgent"}, Reviewers: []string{"oncall"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Type defaults for an unknown change type",
+			config: &types.Config{
+				AI:           types.AIConfig{Provider: types.AIProviderClaude},
+				TypeDefaults: map[string]types.TypeDefault{"fix": {Labels: []string{"bug"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Type defaults adding nothing",
+			config: &types.Config{
+				AI:           types.AIConfig{Provider: types.AIProviderClaude},
+				TypeDefaults: map[string]types.TypeDefault{"docs": {}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -187,3 +292,122 @@ func TestWriteConfig(t *testing.T) {
 			loaded.AI.Provider, config.AI.Provider)
 	}
 }
+
+func TestVersionWarning(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		want    string
+	}{
+		{name: "Current", version: CurrentConfigVersion, want: ""},
+		{name: "Unversioned", version: 0, want: "config migrate"},
+		{name: "Newer", version: CurrentConfigVersion + 1, want: "newer than this auto-pr supports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := versionWarning(tt.version)
+			if tt.want == "" && got != "" {
+				t.Errorf("versionWarning(%d) = %q, want none", tt.version, got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("versionWarning(%d) = %q, want it to mention %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigWarnsOnceForNewerVersion(t *testing.T) {
+	var out bytes.Buffer
+	warningOutput = &out
+	defer func() { warningOutput = os.Stderr }()
+
+	config := getDefaultConfig()
+	config.Version = CurrentConfigVersion + 100
+
+	for i := 0; i < 2; i++ {
+		if err := ValidateConfig(config); err != nil {
+			t.Fatalf("ValidateConfig() error = %v, want a newer version to only warn", err)
+		}
+	}
+	if got := strings.Count(out.String(), "newer than this auto-pr supports"); got != 1 {
+		t.Errorf("ValidateConfig() warned %d times, want once:\n%s", got, out.String())
+	}
+}
+
+func TestFindUnknownKeys(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `version: 1
+no_emoji: true
+ai:
+  provider: claude
+  temprature: 0.2
+  extra_fields:
+    risk: "Risk assessment"
+  claude:
+    modle: claude-3-5-sonnet-20241022
+git:
+  commit_limit: 10
+colour: auto
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	keys, err := FindUnknownKeys(configPath)
+	if err != nil {
+		t.Fatalf("FindUnknownKeys() error = %v", err)
+	}
+
+	want := []string{"ai.claude.modle", "ai.temprature", "colour"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("FindUnknownKeys() = %v, want %v", keys, want)
+	}
+}
+
+func TestApplyEnvOverridesSizeThresholds(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("platforms.labels.size_thresholds", []interface{}{
+		map[string]interface{}{"label": "small", "below": 100},
+		map[string]interface{}{"label": "large"},
+	})
+
+	config := getDefaultConfig()
+	applyEnvOverrides(config)
+
+	want := []types.SizeThreshold{{Label: "small", Below: 100}, {Label: "large"}}
+	if !reflect.DeepEqual(config.Platforms.Labels.SizeThresholds, want) {
+		t.Errorf("SizeThresholds = %+v, want %+v", config.Platforms.Labels.SizeThresholds, want)
+	}
+}
+
+func TestApplyEnvOverridesAutoPush(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	config := getDefaultConfig()
+	applyEnvOverrides(config)
+	if config.Git.AutoPush != nil {
+		t.Errorf("AutoPush = %v, want unset by default", *config.Git.AutoPush)
+	}
+
+	viper.Set("git.auto_push", "false")
+	applyEnvOverrides(config)
+	if config.Git.AutoPush == nil || *config.Git.AutoPush {
+		t.Errorf("AutoPush = %v, want false", config.Git.AutoPush)
+	}
+}
+
+func TestApplyEnvOverridesTypeDefaults(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("type_defaults", map[string]interface{}{
+		"hotfix": map[string]interface{}{"labels": []interface{}{"urgent"}, "reviewers": []interface{}{"oncall"}},
+	})
+
+	config := getDefaultConfig()
+	applyEnvOverrides(config)
+
+	want := map[string]types.TypeDefault{"hotfix": {Labels: []string{"urgent"}, Reviewers: []string{"oncall"}}}
+	if !reflect.DeepEqual(config.TypeDefaults, want) {
+		t.Errorf("TypeDefaults = %+v, want %+v", config.TypeDefaults, want)
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"auto-pr/pkg/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion is the schema version written to new config files.
+// Files without a version predate versioning and count as version 0.
+const CurrentConfigVersion = 1
+
+// configMigration upgrades a raw config file from the previous version,
+// returning notes on what it changed
+type configMigration struct {
+	version int
+	apply   func(raw map[string]interface{}) []string
+}
+
+// configMigrations are applied in order to files older than their version
+var configMigrations = []configMigration{
+	{version: 1, apply: migrateRemovedProviders},
+}
+
+// MigrationResult describes what MigrateConfig did to a config file
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Notes       []string
+	BackupPath  string // Empty when the file was already current
+}
+
+// MigrateConfig upgrades the config file at configPath to the current schema:
+// it applies the migrations for its version, drops keys the schema no longer
+// has, fills in new defaults and rewrites it, keeping the original next to
+// it with a .bak suffix. A file that is already current is left untouched.
+func MigrateConfig(configPath string) (*MigrationResult, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config, result, err := migrateConfigData(data)
+	if err != nil {
+		return nil, err
+	}
+	if result.FromVersion == CurrentConfigVersion {
+		return result, nil
+	}
+
+	result.BackupPath = configPath + ".bak"
+	if err := os.WriteFile(result.BackupPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to back up config file: %w", err)
+	}
+	if err := WriteConfig(configPath, config); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// migrateConfigData upgrades the contents of a config file, returning the
+// migrated configuration and what changed
+func migrateConfigData(data []byte) (*types.Config, *MigrationResult, error) {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	from := 0
+	if version, ok := raw["version"].(int); ok {
+		from = version
+	}
+	if from > CurrentConfigVersion {
+		return nil, nil, fmt.Errorf("config file is version %d, newer than this auto-pr supports (%d)", from, CurrentConfigVersion)
+	}
+
+	result := &MigrationResult{FromVersion: from, ToVersion: CurrentConfigVersion}
+	for _, migration := range configMigrations {
+		if migration.version > from {
+			result.Notes = append(result.Notes, migration.apply(raw)...)
+		}
+	}
+
+	for _, key := range unknownKeys(raw, reflect.TypeOf(types.Config{}), "") {
+		result.Notes = append(result.Notes, fmt.Sprintf("removed %s, which auto-pr doesn't use", key))
+	}
+
+	// Round-trip through the schema, which drops the unknown keys
+	migrated, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	var config types.Config
+	if err := yaml.Unmarshal(migrated, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse migrated config: %w", err)
+	}
+	config.Version = CurrentConfigVersion
+	mergeWithDefaults(&config)
+
+	return &config, result, nil
+}
+
+// migrateRemovedProviders switches providers other than Claude to Claude and
+// drops their settings (version 1)
+func migrateRemovedProviders(raw map[string]interface{}) []string {
+	ai, ok := raw["ai"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var notes []string
+	if provider, _ := ai["provider"].(string); provider == "gemini" || provider == "openai" {
+		ai["provider"] = string(types.AIProviderClaude)
+		notes = append(notes, fmt.Sprintf("changed ai.provider from %s to claude, the only supported provider; Claude Code must be installed", provider))
+	}
+	for _, key := range []string{"gemini", "openai", "api_key"} {
+		if _, ok := ai[key]; ok {
+			delete(ai, key)
+			notes = append(notes, fmt.Sprintf("removed ai.%s, which is no longer used", key))
+		}
+	}
+	return notes
+}
+
+// unknownKeys lists the dotted keys in raw that the schema struct doesn't
+// have, sorted
+func unknownKeys(raw map[string]interface{}, schema reflect.Type, prefix string) []string {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < schema.NumField(); i++ {
+		field := schema.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+
+	var keys []string
+	for key, value := range raw {
+		fieldType, ok := fields[key]
+		if !ok {
+			keys = append(keys, prefix+key)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok && fieldType.Kind() == reflect.Struct {
+			keys = append(keys, unknownKeys(nested, fieldType, prefix+key+".")...)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+
+	original := `ai:
+  provider: gemini
+  max_tokens: 2048
+  gemini:
+    api_key: secret
+  claude:
+    use_session: false
+git:
+  commit_limit: 5
+  old_option: true
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	result, err := MigrateConfig(configPath)
+	if err != nil {
+		t.Fatalf("MigrateConfig() error = %v", err)
+	}
+
+	if result.FromVersion != 0 || result.ToVersion != CurrentConfigVersion {
+		t.Errorf("MigrateConfig() versions = %d -> %d, want 0 -> %d", result.FromVersion, result.ToVersion, CurrentConfigVersion)
+	}
+	notes := strings.Join(result.Notes, "\n")
+	for _, want := range []string{"ai.provider from gemini", "ai.gemini", "git.old_option"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("MigrateConfig() notes = %q, missing %q", notes, want)
+		}
+	}
+	if strings.Contains(notes, "use_session") {
+		t.Errorf("MigrateConfig() reported a known setting as unused: %q", notes)
+	}
+
+	backup, err := os.ReadFile(result.BackupPath)
+	if err != nil || string(backup) != original {
+		t.Errorf("MigrateConfig() backup = %q, %v; want the original file", backup, err)
+	}
+
+	migrated, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load migrated config: %v", err)
+	}
+	if err := ValidateConfig(migrated); err != nil {
+		t.Errorf("Migrated config is invalid: %v", err)
+	}
+	if migrated.Version != CurrentConfigVersion {
+		t.Errorf("Migrated Version = %d, want %d", migrated.Version, CurrentConfigVersion)
+	}
+	if migrated.AI.Provider != types.AIProviderClaude {
+		t.Errorf("Migrated AI.Provider = %v, want %v", migrated.AI.Provider, types.AIProviderClaude)
+	}
+	if migrated.AI.MaxTokens != 2048 || migrated.Git.CommitLimit != 5 {
+		t.Errorf("Migrated config lost settings: max_tokens %d, commit_limit %d", migrated.AI.MaxTokens, migrated.Git.CommitLimit)
+	}
+	if len(migrated.Git.ProtectedBranches) == 0 {
+		t.Error("Migrated config is missing the default protected branches")
+	}
+
+	// Migrating again finds nothing to do
+	again, err := MigrateConfig(configPath)
+	if err != nil {
+		t.Fatalf("MigrateConfig() second run error = %v", err)
+	}
+	if again.BackupPath != "" || len(again.Notes) != 0 {
+		t.Errorf("MigrateConfig() second run = %+v, want no changes", again)
+	}
+}
+
+func TestMigrateConfigNewerVersion(t *testing.T) {
+	if _, _, err := migrateConfigData([]byte("version: 99\n")); err == nil {
+		t.Error("migrateConfigData() accepted a config from a newer version")
+	}
+}
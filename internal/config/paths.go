@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appDirName is the directory name used under the XDG base directories
+const appDirName = "auto-pr"
+
+// legacyDirName is the pre-XDG directory in the home directory
+const legacyDirName = ".auto-pr"
+
+// ConfigDir returns the directory holding config.yaml. It is
+// $XDG_CONFIG_HOME/auto-pr when XDG_CONFIG_HOME is set, and ~/.auto-pr
+// otherwise or when only the legacy config file exists.
+func ConfigDir() (string, error) {
+	return resolveDir("XDG_CONFIG_HOME", "config.yaml")
+}
+
+// DataDir returns the directory for user data such as custom templates. It is
+// $XDG_DATA_HOME/auto-pr when XDG_DATA_HOME is set, and ~/.auto-pr otherwise
+// or when only the legacy templates directory exists.
+func DataDir() (string, error) {
+	return resolveDir("XDG_DATA_HOME", "templates")
+}
+
+// DefaultConfigPath returns the path of the default config file
+func DefaultConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// resolveDir picks between the XDG directory named by xdgEnv and the legacy
+// ~/.auto-pr. The legacy directory is kept while the XDG one doesn't exist
+// yet and the legacy marker entry does, so existing setups keep working.
+func resolveDir(xdgEnv, legacyMarker string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	legacyDir := filepath.Join(home, legacyDirName)
+
+	xdgHome := os.Getenv(xdgEnv)
+	if xdgHome == "" {
+		return legacyDir, nil
+	}
+	xdgDir := filepath.Join(xdgHome, appDirName)
+
+	if _, err := os.Stat(xdgDir); err == nil {
+		return xdgDir, nil
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, legacyMarker)); err == nil {
+		return legacyDir, nil
+	}
+
+	return xdgDir, nil
+}
+
+// AppDirs returns the auto-pr directories that exist: the legacy ~/.auto-pr
+// and the auto-pr directories under XDG_CONFIG_HOME and XDG_DATA_HOME, each
+// listed once. Together they hold all of auto-pr's config, templates and
+// other user data.
+func AppDirs() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	candidates := []string{filepath.Join(home, legacyDirName)}
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME"} {
+		if xdgHome := os.Getenv(env); xdgHome != "" {
+			candidates = append(candidates, filepath.Join(xdgHome, appDirName))
+		}
+	}
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, dir := range candidates {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if info, err := os.Lstat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// RemoveAppDir deletes one of the directories AppDirs returns and everything
+// in it. Any other path is refused, so a mistake can't delete files auto-pr
+// doesn't own.
+func RemoveAppDir(dir string) error {
+	dirs, err := AppDirs()
+	if err != nil {
+		return err
+	}
+
+	dir = filepath.Clean(dir)
+	for _, appDir := range dirs {
+		if appDir == dir {
+			return os.RemoveAll(dir)
+		}
+	}
+	return fmt.Errorf("refusing to remove %s: not an auto-pr directory", dir)
+}
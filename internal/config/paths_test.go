@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		setXDG    bool
+		legacy    bool // ~/.auto-pr/config.yaml exists
+		xdgExists bool // $XDG_CONFIG_HOME/auto-pr exists
+		want      string
+	}{
+		{name: "XDG unset", setXDG: false, want: "legacy"},
+		{name: "XDG set, nothing exists yet", setXDG: true, want: "xdg"},
+		{name: "XDG set, only legacy config exists", setXDG: true, legacy: true, want: "legacy"},
+		{name: "XDG set, both exist", setXDG: true, legacy: true, xdgExists: true, want: "xdg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			xdgHome := filepath.Join(home, ".config")
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", "")
+			if tt.setXDG {
+				t.Setenv("XDG_CONFIG_HOME", xdgHome)
+			}
+
+			legacyDir := filepath.Join(home, ".auto-pr")
+			xdgDir := filepath.Join(xdgHome, "auto-pr")
+			if tt.legacy {
+				if err := os.MkdirAll(legacyDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(legacyDir, "config.yaml"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.xdgExists {
+				if err := os.MkdirAll(xdgDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := legacyDir
+			if tt.want == "xdg" {
+				want = xdgDir
+			}
+
+			got, err := ConfigDir()
+			if err != nil {
+				t.Fatalf("ConfigDir() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("ConfigDir() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDataDirKeepsLegacyTemplates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+
+	// A legacy directory holding only config does not pin templates to it
+	legacyDir := filepath.Join(home, ".auto-pr")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() error = %v", err)
+	}
+	if want := filepath.Join(home, ".local", "share", "auto-pr"); got != want {
+		t.Errorf("DataDir() = %v, want %v", got, want)
+	}
+
+	if err := os.MkdirAll(filepath.Join(legacyDir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err = DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() error = %v", err)
+	}
+	if got != legacyDir {
+		t.Errorf("DataDir() = %v, want legacy %v", got, legacyDir)
+	}
+}
+
+func TestAppDirsAndRemoveAppDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+
+	legacyDir := filepath.Join(home, ".auto-pr")
+	dataDir := filepath.Join(home, ".local", "share", "auto-pr")
+	for _, dir := range []string{legacyDir, filepath.Join(dataDir, "templates")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dirs, err := AppDirs()
+	if err != nil {
+		t.Fatalf("AppDirs() error = %v", err)
+	}
+	// The XDG config directory doesn't exist, so it isn't listed
+	if len(dirs) != 2 || dirs[0] != legacyDir || dirs[1] != dataDir {
+		t.Errorf("AppDirs() = %v, want [%s %s]", dirs, legacyDir, dataDir)
+	}
+
+	other := filepath.Join(home, "projects", "auto-pr")
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveAppDir(other); err == nil {
+		t.Errorf("RemoveAppDir(%s) succeeded for a directory AppDirs doesn't list", other)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("RemoveAppDir() removed %s: %v", other, err)
+	}
+
+	if err := RemoveAppDir(dataDir); err != nil {
+		t.Fatalf("RemoveAppDir() error = %v", err)
+	}
+	if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+		t.Errorf("RemoveAppDir() left %s behind", dataDir)
+	}
+}
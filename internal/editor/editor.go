@@ -0,0 +1,77 @@
+// Package editor opens files and text in the user's editor
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// command returns the user's editor from $EDITOR, which may include
+// arguments such as "code --wait", defaulting to vi
+func command() []string {
+	if fields := strings.Fields(os.Getenv("EDITOR")); len(fields) > 0 {
+		return fields
+	}
+	return []string{"vi"}
+}
+
+// Open opens a file in the user's editor and waits for it to close
+func Open(path string) error {
+	args := command()
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Edit writes text to a temporary file named after pattern, opens it in
+// the user's editor and returns the edited text with lines starting with '#'
+// removed, the way git treats commit messages. hint is added as such comment
+// lines below the text.
+func Edit(text, pattern, hint string) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	content := text
+	if hint != "" {
+		content += "\n"
+		for _, line := range strings.Split(hint, "\n") {
+			content += "\n# " + line
+		}
+		content += "\n"
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := Open(file.Name()); err != nil {
+		return "", fmt.Errorf("failed to open editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return stripComments(string(edited)), nil
+}
+
+// stripComments drops comment lines and surrounding blank lines
+func stripComments(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeEditor sets $EDITOR to a script that runs the given shell commands on
+// the file it is given as $1
+func fakeEditor(t *testing.T, script string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake editor: %v", err)
+	}
+	t.Setenv("EDITOR", path)
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			name:   "Unchanged keeps the text without the hint",
+			script: "true",
+			want:   "feat: add login\n\nBody text",
+		},
+		{
+			name:   "Edited text is returned",
+			script: `printf 'fix: correct login\n# a comment\n\nNew body\n' > "$1"`,
+			want:   "fix: correct login\n\nNew body",
+		},
+		{
+			name:   "Emptied file returns nothing",
+			script: `printf '# only comments\n' > "$1"`,
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeEditor(t, tt.script)
+
+			got, err := Edit("feat: add login\n\nBody text", "edit-test-*.txt", "Lines starting with '#' are ignored")
+			if err != nil {
+				t.Fatalf("Edit() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Edit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorWithArguments(t *testing.T) {
+	t.Setenv("EDITOR", "code --wait")
+	if got := command(); len(got) != 2 || got[0] != "code" || got[1] != "--wait" {
+		t.Errorf("command() = %q, want [code --wait]", got)
+	}
+
+	t.Setenv("EDITOR", "")
+	if got := command(); len(got) != 1 || got[0] != "vi" {
+		t.Errorf("command() = %q, want [vi]", got)
+	}
+}
@@ -1,20 +1,26 @@
 package git
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"auto-pr/pkg/types"
 )
 
+// ErrDetachedHead is returned when HEAD doesn't point at a branch, as during a
+// rebase, a bisect or after checking out a tag
+var ErrDetachedHead = errors.New("you are in a detached HEAD state; checkout a branch first")
+
 // Analyzer provides git repository analysis functionality
 type Analyzer struct {
-	repoPath string
+	repoPath       string
+	runner         CommandRunner
+	diffContext    int      // Lines of context in diffs; negative uses git's default
+	baseCandidates []string // Base branches to try, in order, when the remote names no default
+	skipFileStats  bool     // Leave per-file line counts at 0 instead of asking git for each file
+	headBranch     string   // Branch compared with the base branch instead of HEAD, when set
 }
 
 // NewAnalyzer creates a new git analyzer for the specified repository path
@@ -25,23 +31,104 @@ func NewAnalyzer(repoPath string) (*Analyzer, error) {
 	}
 
 	return &Analyzer{
-		repoPath: absPath,
+		repoPath:       absPath,
+		runner:         execRunner{dir: absPath},
+		diffContext:    -1,
+		baseCandidates: CommonBaseBranches,
 	}, nil
 }
 
-// IsGitRepository checks if the current directory is a git repository
+// SetCommandRunner makes the analyzer run git through runner instead of the
+// git executable, e.g. to test against canned output
+func (a *Analyzer) SetCommandRunner(runner CommandRunner) {
+	a.runner = runner
+}
+
+// SetDiffContext sets how many lines of context surround the changes in the
+// diffs it returns (git.diff_context)
+func (a *Analyzer) SetDiffContext(lines int) {
+	a.diffContext = lines
+}
+
+// SetSkipFileStats skips counting the lines changed in each file, which
+// takes a git call per file and is slow in very large repositories. The
+// totals from git diff --stat are still filled in.
+func (a *Analyzer) SetSkipFileStats(skip bool) {
+	a.skipFileStats = skip
+}
+
+// SetBaseBranchCandidates sets the base branches tried, in order, when the
+// remote doesn't name its default branch (git.base_branch_candidates). An
+// empty list keeps CommonBaseBranches.
+func (a *Analyzer) SetBaseBranchCandidates(candidates []string) {
+	if len(candidates) > 0 {
+		a.baseCandidates = candidates
+	}
+}
+
+// BaseBranchCandidates returns the base branches tried when the remote
+// doesn't name its default branch
+func (a *Analyzer) BaseBranchCandidates() []string {
+	return a.baseCandidates
+}
+
+// SetHeadBranch makes the analyzer compare the local branch with the base
+// branch instead of HEAD, so a branch can be described without checking it
+// out. The status still reports the checked-out branch and its changes.
+func (a *Analyzer) SetHeadBranch(branch string) error {
+	if _, err := a.resolveCommit("refs/heads/" + branch); err != nil {
+		return fmt.Errorf("branch %s not found locally", branch)
+	}
+	a.headBranch = branch
+	return nil
+}
+
+// headRef returns the ref compared with the base branch: the branch set with
+// SetHeadBranch, or HEAD
+func (a *Analyzer) headRef() string {
+	if a.headBranch != "" {
+		return "refs/heads/" + a.headBranch
+	}
+	return "HEAD"
+}
+
+// HeadBranchHash returns the commit compared with the base branch: the tip of
+// the branch set with SetHeadBranch, or HEAD
+func (a *Analyzer) HeadBranchHash() (string, error) {
+	return a.resolveCommit(a.headRef())
+}
+
+// RepoPath returns the absolute path of the repository
+func (a *Analyzer) RepoPath() string {
+	return a.repoPath
+}
+
+// IsGitRepository reports whether the path is inside a git work tree. Asking
+// git rather than looking for a .git directory also covers linked worktrees
+// and submodules, where .git is a file pointing elsewhere.
 func (a *Analyzer) IsGitRepository() bool {
-	gitDir := filepath.Join(a.repoPath, ".git")
-	if stat, err := os.Stat(gitDir); err == nil {
-		return stat.IsDir()
+	output, err := a.runner.Run("rev-parse", "--is-inside-work-tree")
+	if err != nil {
+		return false
 	}
 
-	// Check if .git is a file (in case of git worktrees)
-	if _, err := os.Stat(gitDir); err == nil {
-		return true
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// CommonGitDir returns the absolute path of the git directory shared by all
+// worktrees, which holds the refs and config. In a plain clone it is .git.
+func (a *Analyzer) CommonGitDir() (string, error) {
+	output, err := a.runner.Run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve git directory: %w", err)
 	}
 
-	return false
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(a.repoPath, dir)
+	}
+
+	return filepath.Clean(dir), nil
 }
 
 // GetStatus returns the current status of the git repository
@@ -52,12 +139,19 @@ func (a *Analyzer) GetStatus() (*types.GitStatus, error) {
 
 	status := &types.GitStatus{IsGitRepo: true}
 
-	// Get current branch
+	// Get current branch, or the commit HEAD points at when detached
 	currentBranch, err := a.getCurrentBranch()
-	if err != nil {
+	switch {
+	case errors.Is(err, ErrDetachedHead):
+		status.DetachedHead = true
+		if status.HeadCommit, err = a.getHeadCommit(); err != nil {
+			return nil, err
+		}
+	case err != nil:
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	default:
+		status.CurrentBranch = currentBranch
 	}
-	status.CurrentBranch = currentBranch
 
 	// Get remote URL
 	remoteURL, err := a.getRemoteURL()
@@ -72,18 +166,17 @@ func (a *Analyzer) GetStatus() (*types.GitStatus, error) {
 	}
 
 	// Get file statuses
-	staged, unstaged, untracked, err := a.getFileStatuses()
+	entries, err := a.getFileStatuses()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file statuses: %w", err)
 	}
 
-	status.StagedFiles = staged
-	status.UnstagedFiles = unstaged
-	status.UntrackedFiles = untracked
-	status.HasChanges = len(staged) > 0 || len(unstaged) > 0 || len(untracked) > 0
+	status.Entries = entries
+	fillFileLists(status, entries)
+	status.HasChanges = len(entries) > 0
 
 	// Get commit counts
-	ahead, behind, err := a.getCommitCounts(baseBranch)
+	ahead, behind, err := a.CommitCounts(baseBranch)
 	if err == nil {
 		status.CommitsAhead = ahead
 		status.CommitsBehind = behind
@@ -98,33 +191,77 @@ func (a *Analyzer) GetRemoteURL() string {
 	return remoteURL
 }
 
+// GetRemoteURLByName returns the URL of the named remote
+func (a *Analyzer) GetRemoteURLByName(remote string) (string, error) {
+	output, err := a.runner.Run("remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote '%s': %w", remote, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // getCurrentBranch returns the current branch name
 func (a *Analyzer) getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "-C", a.repoPath, "branch", "--show-current")
-	output, err := cmd.Output()
+	output, err := a.runner.Run("branch", "--show-current")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
 
+	branch := strings.TrimSpace(string(output))
+	if branch == "" {
+		return "", ErrDetachedHead
+	}
+
+	return branch, nil
+}
+
+// getHeadCommit returns the full hash of the commit HEAD points at
+func (a *Analyzer) getHeadCommit() (string, error) {
+	output, err := a.runner.Run("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
 	return strings.TrimSpace(string(output)), nil
 }
 
 // getRemoteURL returns the remote URL for origin
 func (a *Analyzer) getRemoteURL() (string, error) {
-	cmd := exec.Command("git", "-C", a.repoPath, "remote", "get-url", "origin")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get remote URL: %w", err)
+	return a.GetRemoteURLByName("origin")
+}
+
+// CommonBaseBranches are the branch names tried as a base when the remote
+// does not name a default branch and none are configured
+var CommonBaseBranches = []string{"main", "master", "develop"}
+
+// InferBaseBranch returns the candidate whose merge-base with HEAD is closest
+// to HEAD, i.e. the branch HEAD was actually forked from. Earlier candidates
+// win ties, and the current branch is never its own base.
+func (a *Analyzer) InferBaseBranch(candidates []string) (string, error) {
+	current := a.headBranch
+	if current == "" {
+		current, _ = a.getCurrentBranch()
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	var others []string
+	for _, candidate := range candidates {
+		if candidate != "" && candidate != current {
+			others = append(others, candidate)
+		}
+	}
+	if len(others) == 0 {
+		return "", fmt.Errorf("no candidate base branches")
+	}
+
+	return a.nearestForkPoint(a.headRef(), others)
 }
 
-// getBaseBranch attempts to determine the base branch (main/master)
+// getBaseBranch attempts to determine the base branch: the remote's default
+// branch, or else the first candidate the remote has
 func (a *Analyzer) getBaseBranch() (string, error) {
 	// Try to get the default branch from remote
-	cmd := exec.Command("git", "-C", a.repoPath, "symbolic-ref", "refs/remotes/origin/HEAD")
-	output, err := cmd.Output()
+	output, err := a.runner.Run("symbolic-ref", "refs/remotes/origin/HEAD")
 	if err == nil {
 		branch := strings.TrimSpace(string(output))
 		parts := strings.Split(branch, "/")
@@ -133,69 +270,35 @@ func (a *Analyzer) getBaseBranch() (string, error) {
 		}
 	}
 
-	// Fallback: check common branch names
-	commonBranches := []string{"main", "master", "develop"}
-	for _, branch := range commonBranches {
-		cmd := exec.Command("git", "-C", a.repoPath, "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch)
-		if cmd.Run() == nil {
+	// Fallback: check the candidate branch names
+	for _, branch := range a.baseCandidates {
+		if _, err := a.runner.Run("show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch); err == nil {
 			return branch, nil
 		}
 	}
 
+	if len(a.baseCandidates) > 0 {
+		return a.baseCandidates[0], nil
+	}
 	return "main", nil // Default fallback
 }
 
-// getFileStatuses returns lists of staged, unstaged, and untracked files
-func (a *Analyzer) getFileStatuses() (staged, unstaged, untracked []string, err error) {
-	cmd := exec.Command("git", "-C", a.repoPath, "status", "--porcelain=v1")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get git status: %w", err)
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) < 3 {
-			continue
-		}
-
-		indexStatus := line[0]
-		workTreeStatus := line[1]
-		filename := line[3:]
-
-		if indexStatus != ' ' && indexStatus != '?' {
-			staged = append(staged, filename)
-		}
-		if workTreeStatus != ' ' && workTreeStatus != '?' {
-			unstaged = append(unstaged, filename)
-		}
-		if indexStatus == '?' && workTreeStatus == '?' {
-			untracked = append(untracked, filename)
-		}
+// CommitCounts returns the number of commits HEAD is ahead of and behind the
+// base branch, comparing with origin and falling back to the local branch
+func (a *Analyzer) CommitCounts(baseBranch string) (ahead, behind int, err error) {
+	base := "origin/" + baseBranch
+	if _, err := a.resolveCommit(base); err != nil {
+		base = baseBranch
 	}
 
-	return staged, unstaged, untracked, scanner.Err()
-}
-
-// getCommitCounts returns the number of commits ahead and behind the base branch
-func (a *Analyzer) getCommitCounts(baseBranch string) (ahead, behind int, err error) {
 	// Get commits ahead
-	cmd := exec.Command("git", "-C", a.repoPath, "rev-list", "--count", fmt.Sprintf("origin/%s..HEAD", baseBranch))
-	output, err := cmd.Output()
-	if err == nil {
-		if count, parseErr := strconv.Atoi(strings.TrimSpace(string(output))); parseErr == nil {
-			ahead = count
-		}
+	if count, err := a.countCommits(base + ".." + a.headRef()); err == nil {
+		ahead = count
 	}
 
 	// Get commits behind
-	cmd = exec.Command("git", "-C", a.repoPath, "rev-list", "--count", fmt.Sprintf("HEAD..origin/%s", baseBranch))
-	output, err = cmd.Output()
-	if err == nil {
-		if count, parseErr := strconv.Atoi(strings.TrimSpace(string(output))); parseErr == nil {
-			behind = count
-		}
+	if count, err := a.countCommits(a.headRef() + ".." + base); err == nil {
+		behind = count
 	}
 
 	return ahead, behind, nil
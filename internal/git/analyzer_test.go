@@ -0,0 +1,254 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with a single commit on branch main and
+// returns its path with a helper running git in it
+func newTestRepo(t testing.TB) (string, func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	run("init", "-q", "-b", "main")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	return dir, run
+}
+
+func TestGetStatusDetachedHead(t *testing.T) {
+	dir, run := newTestRepo(t)
+	head := run("rev-parse", "HEAD")
+	run("checkout", "-q", "--detach")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	if _, err := a.getCurrentBranch(); !errors.Is(err, ErrDetachedHead) {
+		t.Errorf("getCurrentBranch() error = %v, want %v", err, ErrDetachedHead)
+	}
+
+	status, err := a.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if !status.DetachedHead {
+		t.Error("GetStatus().DetachedHead = false, want true")
+	}
+	if status.HeadCommit != head {
+		t.Errorf("GetStatus().HeadCommit = %v, want %v", status.HeadCommit, head)
+	}
+	if status.CurrentBranch != "" {
+		t.Errorf("GetStatus().CurrentBranch = %v, want empty", status.CurrentBranch)
+	}
+
+	run("checkout", "-q", "main")
+	status, err = a.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.DetachedHead || status.CurrentBranch != "main" {
+		t.Errorf("GetStatus() = detached %v on %q, want branch main", status.DetachedHead, status.CurrentBranch)
+	}
+}
+
+func TestIsGitRepositoryWorktree(t *testing.T) {
+	dir, run := newTestRepo(t)
+	worktree := filepath.Join(t.TempDir(), "linked")
+	run("worktree", "add", "-q", "-b", "feature/linked", worktree)
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Main work tree", path: dir, want: true},
+		{name: "Linked worktree", path: worktree, want: true},
+		{name: "Subdirectory of worktree", path: filepath.Join(worktree, "sub"), want: true},
+		{name: "Inside .git", path: filepath.Join(dir, ".git"), want: false},
+		{name: "Not a repository", path: t.TempDir(), want: false},
+	}
+
+	if err := os.Mkdir(filepath.Join(worktree, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAnalyzer(tt.path)
+			if err != nil {
+				t.Fatalf("NewAnalyzer() error = %v", err)
+			}
+			if got := a.IsGitRepository(); got != tt.want {
+				t.Errorf("IsGitRepository() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The worktree shares the refs of the main repository
+	a, err := NewAnalyzer(worktree)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	gitDir, err := a.CommonGitDir()
+	if err != nil {
+		t.Fatalf("CommonGitDir() error = %v", err)
+	}
+	want, err := filepath.EvalSymlinks(filepath.Join(dir, ".git"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := filepath.EvalSymlinks(gitDir); got != want {
+		t.Errorf("CommonGitDir() = %v, want %v", got, want)
+	}
+
+	status, err := a.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if !status.IsGitRepo || status.CurrentBranch != "feature/linked" {
+		t.Errorf("GetStatus() = repo %v on %q, want repo on feature/linked", status.IsGitRepo, status.CurrentBranch)
+	}
+}
+
+func TestGetBaseBranchCandidates(t *testing.T) {
+	dir, run := newTestRepo(t)
+	// The remote has trunk and master but doesn't name its default branch
+	run("update-ref", "refs/remotes/origin/trunk", "HEAD")
+	run("update-ref", "refs/remotes/origin/master", "HEAD")
+
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{name: "Built-in candidates", want: "master"},
+		{name: "Configured order", candidates: []string{"integration", "trunk", "master"}, want: "trunk"},
+		{name: "None on the remote", candidates: []string{"integration"}, want: "integration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAnalyzer(dir)
+			if err != nil {
+				t.Fatalf("NewAnalyzer() error = %v", err)
+			}
+			a.SetBaseBranchCandidates(tt.candidates)
+			if got, _ := a.getBaseBranch(); got != tt.want {
+				t.Errorf("getBaseBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The remote's own default branch comes first
+	run("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/master")
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	a.SetBaseBranchCandidates([]string{"trunk"})
+	if got, _ := a.getBaseBranch(); got != "master" {
+		t.Errorf("getBaseBranch() = %q, want the remote's default master", got)
+	}
+}
+
+func TestCommitCountsWithRunner(t *testing.T) {
+	tests := []struct {
+		name        string
+		outputs     map[string]string
+		wantAhead   int
+		wantBehind  int
+		wantCommand string
+	}{
+		{
+			name: "Compares with origin",
+			outputs: map[string]string{
+				"rev-parse --verify --quiet origin/main^{commit}": "abc123\n",
+				"rev-list --count origin/main..HEAD":              "3\n",
+				"rev-list --count HEAD..origin/main":              "1\n",
+			},
+			wantAhead:   3,
+			wantBehind:  1,
+			wantCommand: "rev-list --count origin/main..HEAD",
+		},
+		{
+			name: "Falls back to the local branch",
+			outputs: map[string]string{
+				"rev-list --count main..HEAD": "2\n",
+				"rev-list --count HEAD..main": "0\n",
+			},
+			wantAhead:   2,
+			wantCommand: "rev-list --count main..HEAD",
+		},
+		{
+			name:    "Counts it can't get are zero",
+			outputs: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, runner := newFakeAnalyzer(t, tt.outputs)
+			ahead, behind, err := a.CommitCounts("main")
+			if err != nil {
+				t.Fatalf("CommitCounts() error = %v", err)
+			}
+			if ahead != tt.wantAhead || behind != tt.wantBehind {
+				t.Errorf("CommitCounts() = %d ahead, %d behind; want %d, %d", ahead, behind, tt.wantAhead, tt.wantBehind)
+			}
+			if tt.wantCommand != "" && !strings.Contains(strings.Join(runner.calls, "\n"), tt.wantCommand) {
+				t.Errorf("CommitCounts() ran %v, want %q among them", runner.calls, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestSetHeadBranch(t *testing.T) {
+	dir, run := newTestRepo(t)
+	run("checkout", "-q", "-b", "feature/other")
+	for _, message := range []string{"one", "two"} {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", message)
+	}
+	run("checkout", "-q", "main")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	if err := a.SetHeadBranch("feature/missing"); err == nil {
+		t.Error("SetHeadBranch() of a missing branch succeeded, want an error")
+	}
+	if err := a.SetHeadBranch("feature/other"); err != nil {
+		t.Fatalf("SetHeadBranch() error = %v", err)
+	}
+
+	ahead, behind, err := a.CommitCounts("main")
+	if err != nil {
+		t.Fatalf("CommitCounts() error = %v", err)
+	}
+	if ahead != 2 || behind != 0 {
+		t.Errorf("CommitCounts() = %d ahead, %d behind, want 2 and 0 for feature/other", ahead, behind)
+	}
+	if base, err := a.InferBaseBranch([]string{"main"}); err != nil || base != "main" {
+		t.Errorf("InferBaseBranch() = %q, %v, want main", base, err)
+	}
+}
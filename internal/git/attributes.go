@@ -0,0 +1,107 @@
+package git
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// generatedAttribute is the .gitattributes attribute GitHub's linguist (and
+// GitLab) use to mark generated files, which diffs collapse by default
+const generatedAttribute = "linguist-generated"
+
+// attributeRule is a .gitattributes line setting or unsetting the generated
+// attribute for the files matching pattern
+type attributeRule struct {
+	pattern   string
+	generated bool
+}
+
+// parseGeneratedAttributes reads the rules for linguist-generated from the
+// contents of a .gitattributes file, in order. Lines that don't mention the
+// attribute, comments and macro definitions are skipped.
+func parseGeneratedAttributes(content string) []attributeRule {
+	var rules []attributeRule
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+			continue
+		}
+
+		for _, attr := range fields[1:] {
+			switch attr {
+			case generatedAttribute, generatedAttribute + "=true":
+				rules = append(rules, attributeRule{pattern: fields[0], generated: true})
+			case "-" + generatedAttribute, "!" + generatedAttribute, generatedAttribute + "=false":
+				rules = append(rules, attributeRule{pattern: fields[0], generated: false})
+			}
+		}
+	}
+	return rules
+}
+
+// GeneratedFiles returns which of files (paths relative to the repository
+// root) the repository's root .gitattributes marks linguist-generated. Like
+// git, the last matching rule wins. A missing .gitattributes marks nothing.
+func (a *Analyzer) GeneratedFiles(files []string) (map[string]bool, error) {
+	data, err := os.ReadFile(filepath.Join(a.repoPath, ".gitattributes"))
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return matchGenerated(parseGeneratedAttributes(string(data)), files), nil
+}
+
+// matchGenerated returns the files the rules leave marked as generated
+func matchGenerated(rules []attributeRule, files []string) map[string]bool {
+	generated := make(map[string]bool)
+	for _, file := range files {
+		marked := false
+		for _, rule := range rules {
+			if matchAttributePattern(rule.pattern, file) {
+				marked = rule.generated
+			}
+		}
+		if marked {
+			generated[file] = true
+		}
+	}
+	return generated
+}
+
+// matchAttributePattern matches a file path against a .gitattributes pattern:
+// a pattern without a slash matches the base name at any depth, any other is
+// matched against the whole path, with "**" spanning directories
+func matchAttributePattern(pattern, file string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(file))
+		return matched
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(file, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a "**"
+// segment matches any number of directories
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], name[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
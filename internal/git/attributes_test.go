@@ -0,0 +1,73 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedFiles(t *testing.T) {
+	attributes := `# Generated code
+*.pb.go linguist-generated=true
+api/gen/** linguist-generated
+/schema.graphql linguist-generated
+docs/*.md -linguist-generated
+api/gen/keep.go linguist-generated=false
+*.png binary
+[attr]generated linguist-generated
+`
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attributes), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+	analyzer, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Base name glob at root", path: "types.pb.go", want: true},
+		{name: "Base name glob in subdirectory", path: "internal/proto/types.pb.go", want: true},
+		{name: "Directory with **", path: "api/gen/client/client.go", want: true},
+		{name: "Anchored pattern", path: "schema.graphql", want: true},
+		{name: "Anchored pattern elsewhere", path: "web/schema.graphql", want: false},
+		{name: "Unset by a later rule", path: "api/gen/keep.go", want: false},
+		{name: "Explicitly not generated", path: "docs/guide.md", want: false},
+		{name: "Other attribute", path: "logo.png", want: false},
+		{name: "Ordinary file", path: "main.go", want: false},
+	}
+
+	var files []string
+	for _, tt := range tests {
+		files = append(files, tt.path)
+	}
+	generated, err := analyzer.GeneratedFiles(files)
+	if err != nil {
+		t.Fatalf("GeneratedFiles() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generated[tt.path]; got != tt.want {
+				t.Errorf("GeneratedFiles()[%q] = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratedFilesWithoutAttributes(t *testing.T) {
+	analyzer, err := NewAnalyzer(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	generated, err := analyzer.GeneratedFiles([]string{"main.go"})
+	if err != nil || len(generated) != 0 {
+		t.Errorf("GeneratedFiles() = %v, %v; want nothing marked", generated, err)
+	}
+}
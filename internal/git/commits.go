@@ -3,7 +3,6 @@ package git
 import (
 	"bufio"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -11,19 +10,39 @@ import (
 	"auto-pr/pkg/types"
 )
 
+// shortHashLength is the number of characters shown for abbreviated hashes
+const shortHashLength = 8
+
+// Markers framing the raw commit message in log output, so multi-line bodies
+// can't be mistaken for file names
+const (
+	messageStart = "\x1d"
+	messageEnd   = "\x1e"
+)
+
+// commitLogFormat prints a "hash|subject|author|email|timestamp" header line
+// followed by the framed raw message
+const commitLogFormat = "--pretty=format:%H|%s|%an|%ae|%at%n%x1d%B%x1e"
+
+// ShortHash returns the abbreviated form of a commit hash, leaving hashes
+// that are already shorter than the display length untouched
+func ShortHash(hash string) string {
+	if len(hash) > shortHashLength {
+		return hash[:shortHashLength]
+	}
+	return hash
+}
+
 // GetCommitHistory returns the commit history for the current branch
 func (a *Analyzer) GetCommitHistory(limit int) ([]types.CommitInfo, error) {
 	if limit <= 0 {
 		limit = 10 // Default limit
 	}
 
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"log",
+	output, err := a.runner.Run("log",
 		fmt.Sprintf("-%d", limit),
-		"--pretty=format:%H|%s|%an|%ae|%at",
+		commitLogFormat,
 		"--name-only")
-
-	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get commit history: %w", err)
 	}
@@ -31,41 +50,45 @@ func (a *Analyzer) GetCommitHistory(limit int) ([]types.CommitInfo, error) {
 	return a.parseCommitHistory(string(output))
 }
 
-// GetCommitsSinceBase returns commits since the base branch
-func (a *Analyzer) GetCommitsSinceBase(baseBranch string) ([]types.CommitInfo, error) {
+// GetCommitsSinceBase returns commits since the base branch, newest first.
+// A limit of 0 or less returns every commit since the base.
+func (a *Analyzer) GetCommitsSinceBase(baseBranch string, limit int) ([]types.CommitInfo, error) {
+	return a.GetCommitsSinceBaseForPaths(baseBranch, limit, nil)
+}
+
+// GetCommitsSinceBaseForPaths returns commits since the base branch that touch
+// the given paths, listing only the files under those paths. Merge commits
+// are left out since they only repeat changes from other commits.
+func (a *Analyzer) GetCommitsSinceBaseForPaths(baseBranch string, limit int, paths []string) ([]types.CommitInfo, error) {
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
 
+	limitArgs := []string{}
+	if limit > 0 {
+		limitArgs = append(limitArgs, fmt.Sprintf("-%d", limit))
+	}
+
 	// Check if base branch exists on remote
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"rev-parse", "--verify", fmt.Sprintf("origin/%s", baseBranch))
-	if err := cmd.Run(); err != nil {
+	if _, err := a.runner.Run("rev-parse", "--verify", fmt.Sprintf("origin/%s", baseBranch)); err != nil {
 		// Fallback to local base branch
-		cmd = exec.Command("git", "-C", a.repoPath,
-			"rev-parse", "--verify", baseBranch)
-		if err := cmd.Run(); err != nil {
+		if _, err := a.runner.Run("rev-parse", "--verify", baseBranch); err != nil {
 			return nil, fmt.Errorf("base branch %s not found", baseBranch)
 		}
 	}
 
 	// Get commits between base and HEAD
-	cmd = exec.Command("git", "-C", a.repoPath,
-		"log",
-		fmt.Sprintf("origin/%s..HEAD", baseBranch),
-		"--pretty=format:%H|%s|%an|%ae|%at",
-		"--name-only")
-
-	output, err := cmd.Output()
+	args := append([]string{"log", "--no-merges"}, limitArgs...)
+	output, err := a.runner.Run(append(append(args,
+		fmt.Sprintf("origin/%s..%s", baseBranch, a.headRef()),
+		commitLogFormat,
+		"--name-only"), pathspecArgs(paths)...)...)
 	if err != nil {
 		// Fallback to local base branch comparison
-		cmd = exec.Command("git", "-C", a.repoPath,
-			"log",
-			fmt.Sprintf("%s..HEAD", baseBranch),
-			"--pretty=format:%H|%s|%an|%ae|%at",
-			"--name-only")
-
-		output, err = cmd.Output()
+		output, err = a.runner.Run(append(append(args,
+			fmt.Sprintf("%s..%s", baseBranch, a.headRef()),
+			commitLogFormat,
+			"--name-only"), pathspecArgs(paths)...)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get commits since base: %w", err)
 		}
@@ -78,14 +101,88 @@ func (a *Analyzer) GetCommitsSinceBase(baseBranch string) ([]types.CommitInfo, e
 	return a.parseCommitHistory(string(output))
 }
 
+// GetRecentAuthors returns the distinct "Name <email>" identities of authors who
+// recently touched the given paths, excluding the configured git user
+func (a *Analyzer) GetRecentAuthors(paths []string, limit int) ([]string, error) {
+	return a.GetRecentAuthorsSince(paths, time.Time{}, limit)
+}
+
+// GetRecentAuthorsSince is GetRecentAuthors limited to commits made after
+// since; a zero time looks at the last limit commits whenever they were made
+func (a *Analyzer) GetRecentAuthorsSince(paths []string, since time.Time, limit int) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	args := []string{"log", fmt.Sprintf("-%d", limit), "--pretty=format:%an <%ae>"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	args = append(append(args, "--"), paths...)
+
+	output, err := a.runner.Run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent authors: %w", err)
+	}
+
+	// Skip the current user, who is already the commit author
+	var currentEmail string
+	if emailOutput, err := a.runner.Run("config", "user.email"); err == nil {
+		currentEmail = strings.TrimSpace(string(emailOutput))
+	}
+
+	seen := make(map[string]bool)
+	authors := []string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		if currentEmail != "" && strings.HasSuffix(line, "<"+currentEmail+">") {
+			continue
+		}
+		authors = append(authors, line)
+	}
+
+	return authors, nil
+}
+
 // parseCommitHistory parses git log output into CommitInfo structs
 func (a *Analyzer) parseCommitHistory(output string) ([]types.CommitInfo, error) {
 	var commits []types.CommitInfo
 	var currentCommit *types.CommitInfo
 
+	// Raw message lines of the current commit while inside the markers
+	var message []string
+	inMessage := false
+
 	scanner := bufio.NewScanner(strings.NewReader(output))
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		rawLine := scanner.Text()
+
+		if !inMessage && currentCommit != nil && strings.HasPrefix(rawLine, messageStart) {
+			rawLine = strings.TrimPrefix(rawLine, messageStart)
+			message = nil
+			inMessage = true
+		}
+
+		if inMessage {
+			before, _, found := strings.Cut(rawLine, messageEnd)
+			message = append(message, before)
+			if found {
+				raw := strings.Join(message, "\n")
+				currentCommit.Body = messageBody(raw)
+				currentCommit.Trailers = parseTrailers(raw)
+				inMessage = false
+			}
+			continue
+		}
+
+		line := strings.TrimSpace(rawLine)
 		if line == "" {
 			continue
 		}
@@ -128,12 +225,104 @@ func (a *Analyzer) parseCommitHistory(output string) ([]types.CommitInfo, error)
 	return commits, scanner.Err()
 }
 
+// messageBody returns a raw commit message without its subject: everything
+// after the first blank line, trailers included
+func messageBody(message string) string {
+	_, body, found := strings.Cut(strings.TrimSpace(message), "\n\n")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(body)
+}
+
+// parseTrailers extracts "Key: value" trailers such as "Refs: #12" or
+// "Reviewed-by: Alice <alice@example.com>" from a raw commit message. Like
+// git, it only looks at the last paragraph, which must not be the subject and
+// must consist entirely of trailers. Indented lines continue the previous
+// value. Keys are kept as written; repeated keys collect every value in order.
+func parseTrailers(message string) map[string][]string {
+	paragraphs := splitParagraphs(message)
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	type trailer struct {
+		key   string
+		value string
+	}
+	var parsed []trailer
+	for _, line := range paragraphs[len(paragraphs)-1] {
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(parsed) == 0 {
+				return nil
+			}
+			last := &parsed[len(parsed)-1]
+			last.value = strings.TrimSpace(last.value + " " + strings.TrimSpace(line))
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found || !isTrailerKey(key) {
+			return nil
+		}
+		parsed = append(parsed, trailer{key: key, value: strings.TrimSpace(value)})
+	}
+
+	trailers := make(map[string][]string)
+	for _, t := range parsed {
+		trailers[t.key] = append(trailers[t.key], t.value)
+	}
+	return trailers
+}
+
+// splitParagraphs splits a message into groups of non-blank lines
+func splitParagraphs(message string) [][]string {
+	var paragraphs [][]string
+	var current []string
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	return paragraphs
+}
+
+// isTrailerKey reports whether key is a valid trailer token: letters, digits
+// and hyphens, with no spaces
+func isTrailerKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLastCommitMessage returns the full message of the HEAD commit
+func (a *Analyzer) GetLastCommitMessage() (string, error) {
+	output, err := a.runner.Run("log", "-1", "--format=%B")
+	if err != nil {
+		return "", fmt.Errorf("failed to get last commit message: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GetCommitDiff returns the diff for a specific commit
 func (a *Analyzer) GetCommitDiff(commitHash string) (string, error) {
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"show", commitHash, "--pretty=format:", "--name-only")
-
-	output, err := cmd.Output()
+	output, err := a.runner.Run("show", commitHash, "--pretty=format:", "--name-only")
 	if err != nil {
 		return "", fmt.Errorf("failed to get commit diff: %w", err)
 	}
@@ -143,10 +332,7 @@ func (a *Analyzer) GetCommitDiff(commitHash string) (string, error) {
 
 // GetCommitStats returns statistics for a commit
 func (a *Analyzer) GetCommitStats(commitHash string) (*types.DiffSummary, error) {
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"show", commitHash, "--stat", "--format=")
-
-	output, err := cmd.Output()
+	output, err := a.runner.Run("show", commitHash, "--stat", "--format=")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get commit stats: %w", err)
 	}
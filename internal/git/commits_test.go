@@ -0,0 +1,219 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShortHash(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+		want string
+	}{
+		{
+			name: "Full SHA-1 hash",
+			hash: "4eabff4c0b1e2d3f4a5b6c7d8e9f0a1b2c3d4e5f",
+			want: "4eabff4c",
+		},
+		{
+			name: "Exactly eight characters",
+			hash: "abcdef12",
+			want: "abcdef12",
+		},
+		{
+			name: "Short hash",
+			hash: "abc123",
+			want: "abc123",
+		},
+		{
+			name: "Empty hash",
+			hash: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShortHash(tt.hash); got != tt.want {
+				t.Errorf("ShortHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommitHistoryShortHash(t *testing.T) {
+	a := &Analyzer{}
+
+	commits, err := a.parseCommitHistory("abc|Short hash commit|Alice|alice@example.com|1700000000\nmain.go\n")
+	if err != nil {
+		t.Fatalf("parseCommitHistory() error = %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("parseCommitHistory() returned %d commits, want 1", len(commits))
+	}
+
+	if got := ShortHash(commits[0].Hash); got != "abc" {
+		t.Errorf("ShortHash() = %v, want %v", got, "abc")
+	}
+}
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    map[string][]string
+	}{
+		{
+			name:    "Multiple trailers",
+			message: "feat: add export\n\nAdds CSV export.\n\nRefs: #12\nType: feature\nReviewed-by: Alice <alice@example.com>\n",
+			want: map[string][]string{
+				"Refs":        {"#12"},
+				"Type":        {"feature"},
+				"Reviewed-by": {"Alice <alice@example.com>"},
+			},
+		},
+		{
+			name:    "Repeated keys keep every value",
+			message: "fix: handle nil\n\nRefs: #3\nRefs: #4\nReviewed-by: Bob <bob@example.com>",
+			want: map[string][]string{
+				"Refs":        {"#3", "#4"},
+				"Reviewed-by": {"Bob <bob@example.com>"},
+			},
+		},
+		{
+			name:    "Continuation lines are folded",
+			message: "docs: update\n\nNote: this spans\n  two lines\nRefs: #9\n",
+			want: map[string][]string{
+				"Note": {"this spans two lines"},
+				"Refs": {"#9"},
+			},
+		},
+		{
+			name:    "Subject only",
+			message: "Refs: #1",
+			want:    nil,
+		},
+		{
+			name:    "Last paragraph is not all trailers",
+			message: "feat: x\n\nRefs: #1\nThis line is prose.",
+			want:    nil,
+		},
+		{
+			name:    "Key with spaces is not a trailer",
+			message: "feat: x\n\nSee also: the docs",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTrailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTrailers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommitHistoryTrailers(t *testing.T) {
+	a := &Analyzer{}
+
+	output := "aaa|feat: add export|Alice|alice@example.com|1700000000\n" +
+		"\x1dfeat: add export\n\nBody line | with a pipe\n\nRefs: #12\nType: feature\n\x1e\n\n" +
+		"export.go\nexport_test.go\n\n" +
+		"bbb|fix: typo|Bob|bob@example.com|1700000100\n" +
+		"\x1dfix: typo\n\x1e\n\n" +
+		"README.md\n"
+
+	commits, err := a.parseCommitHistory(output)
+	if err != nil {
+		t.Fatalf("parseCommitHistory() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("parseCommitHistory() returned %d commits, want 2", len(commits))
+	}
+
+	wantTrailers := map[string][]string{"Refs": {"#12"}, "Type": {"feature"}}
+	if !reflect.DeepEqual(commits[0].Trailers, wantTrailers) {
+		t.Errorf("commits[0].Trailers = %v, want %v", commits[0].Trailers, wantTrailers)
+	}
+	if wantFiles := []string{"export.go", "export_test.go"}; !reflect.DeepEqual(commits[0].Files, wantFiles) {
+		t.Errorf("commits[0].Files = %v, want %v", commits[0].Files, wantFiles)
+	}
+	if want := "Body line | with a pipe\n\nRefs: #12\nType: feature"; commits[0].Body != want {
+		t.Errorf("commits[0].Body = %q, want %q", commits[0].Body, want)
+	}
+	if commits[1].Trailers != nil {
+		t.Errorf("commits[1].Trailers = %v, want nil", commits[1].Trailers)
+	}
+	if commits[1].Body != "" {
+		t.Errorf("commits[1].Body = %q, want empty for a subject-only message", commits[1].Body)
+	}
+	if wantFiles := []string{"README.md"}; !reflect.DeepEqual(commits[1].Files, wantFiles) {
+		t.Errorf("commits[1].Files = %v, want %v", commits[1].Files, wantFiles)
+	}
+}
+
+func TestGetCommitsSinceBaseSkipsMerges(t *testing.T) {
+	dir, run := newTestRepo(t)
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+
+	commit("main work")
+	run("checkout", "-q", "-b", "feature", "HEAD~1")
+	commit("feat: add thing")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "merge", "-q", "--no-edit", "main")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	commits, err := a.GetCommitsSinceBase("main", 0)
+	if err != nil {
+		t.Fatalf("GetCommitsSinceBase() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Message != "feat: add thing" {
+		t.Errorf("GetCommitsSinceBase() = %+v, want only the feature commit", commits)
+	}
+}
+
+func TestGetCommitHistoryWithRunner(t *testing.T) {
+	log := "bbbb2222|fix: handle empty input|Bob|bob@example.com|1700000100\n" +
+		"\x1dfix: handle empty input\n\nRefs: #7\n\x1e\n" +
+		"parser.go\n" +
+		"parser_test.go\n" +
+		"\n" +
+		"aaaa1111|Add parser|Alice|alice@example.com|1700000000\n" +
+		"\x1dAdd parser\n\x1e\n" +
+		"parser.go\n"
+	a, _ := newFakeAnalyzer(t, map[string]string{
+		"log -5 " + commitLogFormat + " --name-only": log,
+	})
+
+	commits, err := a.GetCommitHistory(5)
+	if err != nil {
+		t.Fatalf("GetCommitHistory() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("GetCommitHistory() returned %d commits, want 2", len(commits))
+	}
+
+	first := commits[0]
+	if first.Hash != "bbbb2222" || first.Message != "fix: handle empty input" || first.Author != "Bob" || first.Email != "bob@example.com" {
+		t.Errorf("commits[0] = %+v, want Bob's fix", first)
+	}
+	if first.Date.Unix() != 1700000100 {
+		t.Errorf("commits[0].Date = %v, want the commit timestamp", first.Date)
+	}
+	if strings.Join(first.Files, ",") != "parser.go,parser_test.go" {
+		t.Errorf("commits[0].Files = %v, want parser.go and parser_test.go", first.Files)
+	}
+	if refs := first.Trailers["Refs"]; len(refs) != 1 || refs[0] != "#7" {
+		t.Errorf("commits[0].Trailers = %v, want Refs: #7", first.Trailers)
+	}
+	if strings.Join(commits[1].Files, ",") != "parser.go" || commits[1].Trailers != nil {
+		t.Errorf("commits[1] = %+v, want one file and no trailers", commits[1])
+	}
+}
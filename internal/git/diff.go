@@ -3,7 +3,7 @@ package git
 import (
 	"bufio"
 	"fmt"
-	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,13 +12,12 @@ import (
 
 // GetDiff returns the diff for staged and unstaged changes
 func (a *Analyzer) GetDiff(staged bool) (string, error) {
-	args := []string{"-C", a.repoPath, "diff"}
+	args := append([]string{"diff"}, a.contextArgs()...)
 	if staged {
 		args = append(args, "--staged")
 	}
 
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
+	output, err := a.runner.Run(args...)
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff: %w", err)
 	}
@@ -26,11 +25,37 @@ func (a *Analyzer) GetDiff(staged bool) (string, error) {
 	return string(output), nil
 }
 
+// contextArgs returns the -U<n> argument setting the lines of context in a
+// diff, or nothing to keep git's default
+func (a *Analyzer) contextArgs() []string {
+	if a.diffContext < 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("-U%d", a.diffContext)}
+}
+
+// emptyTree is the hash of the empty tree, which a root commit is compared with
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// GetAmendDiff returns the diff an amended HEAD commit would have: the changes
+// of the last commit together with those staged on top of it
+func (a *Analyzer) GetAmendDiff() (string, error) {
+	args := append([]string{"diff"}, a.contextArgs()...)
+	output, err := a.runner.Run(append(args, "--staged", "HEAD^")...)
+	if err != nil {
+		// The root commit has no parent, so the whole tree is its change
+		if output, err = a.runner.Run(append(args, "--staged", emptyTree)...); err != nil {
+			return "", fmt.Errorf("failed to get amend diff: %w", err)
+		}
+	}
+
+	return string(output), nil
+}
+
 // GetDiffSummary returns a summary of changes in the working directory
 func (a *Analyzer) GetDiffSummary() (*types.DiffSummary, error) {
 	// Get overall statistics
-	cmd := exec.Command("git", "-C", a.repoPath, "diff", "--stat")
-	output, err := cmd.Output()
+	output, err := a.runner.Run("diff", "--stat")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get diff summary: %w", err)
 	}
@@ -49,21 +74,21 @@ func (a *Analyzer) GetDiffSummary() (*types.DiffSummary, error) {
 
 // GetBranchDiff returns diff between current branch and base branch
 func (a *Analyzer) GetBranchDiff(baseBranch string) (*types.DiffSummary, error) {
+	return a.GetBranchDiffForPaths(baseBranch, nil)
+}
+
+// GetBranchDiffForPaths returns diff between current branch and base branch,
+// limited to the given paths when any are given
+func (a *Analyzer) GetBranchDiffForPaths(baseBranch string, paths []string) (*types.DiffSummary, error) {
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
 
 	// Get diff statistics
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"diff", fmt.Sprintf("origin/%s...HEAD", baseBranch), "--stat")
-
-	output, err := cmd.Output()
+	output, err := a.runner.Run(append([]string{"diff", fmt.Sprintf("origin/%s...%s", baseBranch, a.headRef()), "--stat"}, pathspecArgs(paths)...)...)
 	if err != nil {
 		// Fallback to local comparison
-		cmd = exec.Command("git", "-C", a.repoPath,
-			"diff", fmt.Sprintf("%s...HEAD", baseBranch), "--stat")
-
-		output, err = cmd.Output()
+		output, err = a.runner.Run(append([]string{"diff", fmt.Sprintf("%s...%s", baseBranch, a.headRef()), "--stat"}, pathspecArgs(paths)...)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get branch diff: %w", err)
 		}
@@ -72,7 +97,7 @@ func (a *Analyzer) GetBranchDiff(baseBranch string) (*types.DiffSummary, error)
 	summary, _ := a.parseStatOutput(string(output))
 
 	// Get detailed file changes for branch comparison
-	fileChanges, err := a.getBranchFileChanges(baseBranch)
+	fileChanges, err := a.getBranchFileChanges(baseBranch, paths)
 	if err != nil {
 		return summary, nil // Return partial summary
 	}
@@ -81,6 +106,15 @@ func (a *Analyzer) GetBranchDiff(baseBranch string) (*types.DiffSummary, error)
 	return summary, nil
 }
 
+// pathspecArgs returns the trailing "-- <path>..." arguments limiting a git
+// command to the given paths, or nothing when there are none
+func pathspecArgs(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	return append([]string{"--"}, paths...)
+}
+
 // getDetailedFileChanges returns detailed file change information
 func (a *Analyzer) getDetailedFileChanges() ([]types.FileChange, error) {
 	var changes []types.FileChange
@@ -104,43 +138,115 @@ func (a *Analyzer) getDetailedFileChanges() ([]types.FileChange, error) {
 }
 
 // getBranchFileChanges returns file changes between branches
-func (a *Analyzer) getBranchFileChanges(baseBranch string) ([]types.FileChange, error) {
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"diff", fmt.Sprintf("origin/%s...HEAD", baseBranch), "--name-status")
-
-	output, err := cmd.Output()
+func (a *Analyzer) getBranchFileChanges(baseBranch string, paths []string) ([]types.FileChange, error) {
+	output, err := a.runner.Run(append([]string{"diff", fmt.Sprintf("origin/%s...%s", baseBranch, a.headRef()), "--name-status"}, pathspecArgs(paths)...)...)
 	if err != nil {
 		// Fallback to local comparison
-		cmd = exec.Command("git", "-C", a.repoPath,
-			"diff", fmt.Sprintf("%s...HEAD", baseBranch), "--name-status")
-
-		output, err = cmd.Output()
+		output, err = a.runner.Run(append([]string{"diff", fmt.Sprintf("%s...%s", baseBranch, a.headRef()), "--name-status"}, pathspecArgs(paths)...)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get branch file changes: %w", err)
 		}
 	}
 
-	return a.parseNameStatus(string(output))
+	return a.parseNameStatus(string(output), fmt.Sprintf("%s...%s", baseBranch, a.headRef()))
+}
+
+// GetStagedFileChanges returns the staged file changes with their real statuses
+func (a *Analyzer) GetStagedFileChanges() ([]types.FileChange, error) {
+	return a.getFileChangesForStatus("--staged")
+}
+
+// TruncateDiff bounds a diff to maxSize bytes, cutting at a line boundary and
+// noting how much was omitted. A maxSize of 0 or less leaves the diff untouched.
+func TruncateDiff(diff string, maxSize int) string {
+	if maxSize <= 0 || len(diff) <= maxSize {
+		return diff
+	}
+
+	truncated := diff[:maxSize]
+	if idx := strings.LastIndex(truncated, "\n"); idx > 0 {
+		truncated = truncated[:idx+1]
+	}
+
+	return fmt.Sprintf("%s... (diff truncated, %d bytes omitted)\n", truncated, len(diff)-len(truncated))
+}
+
+// ParseUnifiedDiff parses unified diff text (as produced by git diff) into
+// file changes without touching a repository
+func ParseUnifiedDiff(diff string) []types.FileChange {
+	var changes []types.FileChange
+	var current *types.FileChange
+	inHunk := false
+
+	flush := func() {
+		if current != nil {
+			changes = append(changes, *current)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &types.FileChange{Status: types.StatusModified}
+			inHunk = false
+			// "diff --git a/path b/path" - take the destination path
+			if idx := strings.LastIndex(line, " b/"); idx != -1 {
+				current.Path = line[idx+3:]
+			}
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk && strings.HasPrefix(line, "new file mode"):
+			current.Status = types.StatusAdded
+		case !inHunk && strings.HasPrefix(line, "deleted file mode"):
+			current.Status = types.StatusDeleted
+		case !inHunk && strings.HasPrefix(line, "rename to "):
+			current.Status = types.StatusRenamed
+			current.Path = strings.TrimPrefix(line, "rename to ")
+		case !inHunk && strings.HasPrefix(line, "copy to "):
+			current.Status = types.StatusCopied
+			current.Path = strings.TrimPrefix(line, "copy to ")
+		case !inHunk && strings.HasPrefix(line, "+++ ") && line != "+++ /dev/null":
+			current.Path = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case !inHunk && strings.HasPrefix(line, "Binary files "):
+			current.IsBinary = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Additions++
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Deletions++
+		}
+	}
+	flush()
+
+	return changes
 }
 
 // getFileChangesForStatus returns file changes for a specific git diff status
 func (a *Analyzer) getFileChangesForStatus(statusFlag string) ([]types.FileChange, error) {
-	args := []string{"-C", a.repoPath, "diff", "--name-status"}
+	args := []string{"diff", "--name-status"}
+	var statsArgs []string
 	if statusFlag != "" {
 		args = append(args, statusFlag)
+		statsArgs = append(statsArgs, statusFlag)
 	}
 
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
+	output, err := a.runner.Run(args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file changes: %w", err)
 	}
 
-	return a.parseNameStatus(string(output))
+	return a.parseNameStatus(string(output), statsArgs...)
 }
 
-// parseNameStatus parses git diff --name-status output
-func (a *Analyzer) parseNameStatus(output string) ([]types.FileChange, error) {
+// parseNameStatus parses git diff --name-status output. statsArgs are passed
+// to git diff --numstat so per-file stats match the same comparison.
+func (a *Analyzer) parseNameStatus(output string, statsArgs ...string) ([]types.FileChange, error) {
 	var changes []types.FileChange
 
 	scanner := bufio.NewScanner(strings.NewReader(output))
@@ -159,10 +265,14 @@ func (a *Analyzer) parseNameStatus(output string) ([]types.FileChange, error) {
 		filepath := parts[1]
 
 		// Get detailed stats for this file
-		additions, deletions, err := a.getFileStats(filepath)
-		if err != nil {
-			// Continue without detailed stats
-			additions, deletions = 0, 0
+		var additions, deletions int
+		if !a.skipFileStats {
+			var err error
+			additions, deletions, err = a.getFileStats(filepath, statsArgs...)
+			if err != nil {
+				// Continue without detailed stats
+				additions, deletions = 0, 0
+			}
 		}
 
 		changes = append(changes, types.FileChange{
@@ -178,11 +288,12 @@ func (a *Analyzer) parseNameStatus(output string) ([]types.FileChange, error) {
 }
 
 // getFileStats returns addition/deletion counts for a specific file
-func (a *Analyzer) getFileStats(filepath string) (int, int, error) {
-	cmd := exec.Command("git", "-C", a.repoPath,
-		"diff", "--numstat", "--", filepath)
+func (a *Analyzer) getFileStats(filepath string, statsArgs ...string) (int, int, error) {
+	args := []string{"diff", "--numstat"}
+	args = append(args, statsArgs...)
+	args = append(args, "--", filepath)
 
-	output, err := cmd.Output()
+	output, err := a.runner.Run(args...)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -246,11 +357,14 @@ func (a *Analyzer) mergeFileChanges(changes []types.FileChange) []types.FileChan
 		}
 	}
 
-	// Convert map back to slice
+	// Convert map back to slice, sorted by path so the AI context is reproducible
 	var merged []types.FileChange
 	for _, change := range fileMap {
 		merged = append(merged, change)
 	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Path < merged[j].Path
+	})
 
 	return merged
 }
@@ -0,0 +1,301 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestTruncateDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n+line one\n+line two\n+line three\n"
+
+	tests := []struct {
+		name          string
+		maxSize       int
+		wantTruncated bool
+	}{
+		{name: "Unlimited", maxSize: 0, wantTruncated: false},
+		{name: "Within limit", maxSize: len(diff), wantTruncated: false},
+		{name: "Over limit", maxSize: 40, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateDiff(diff, tt.maxSize)
+			if !tt.wantTruncated {
+				if got != diff {
+					t.Errorf("TruncateDiff() = %q, want unchanged diff", got)
+				}
+				return
+			}
+
+			if !strings.Contains(got, "diff truncated") {
+				t.Errorf("TruncateDiff() = %q, want truncation note", got)
+			}
+			if !strings.HasPrefix(got, "diff --git a/main.go b/main.go\n") {
+				t.Errorf("TruncateDiff() = %q, want to keep leading lines", got)
+			}
+			if strings.Contains(got, "line three") {
+				t.Errorf("TruncateDiff() = %q, want trailing lines dropped", got)
+			}
+		})
+	}
+}
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+-// old comment
++// new comment
++// another line
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1,2 @@
++# New
++--- not a header
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/a.go b/b.go
+similarity index 100%
+rename from a.go
+rename to b.go
+diff --git a/logo.png b/logo.png
+index 5555555..6666666 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+	want := []types.FileChange{
+		{Path: "main.go", Status: types.StatusModified, Additions: 2, Deletions: 1},
+		{Path: "docs/new.md", Status: types.StatusAdded, Additions: 2, Deletions: 0},
+		{Path: "old.txt", Status: types.StatusDeleted, Additions: 0, Deletions: 1},
+		{Path: "b.go", Status: types.StatusRenamed},
+		{Path: "logo.png", Status: types.StatusModified, IsBinary: true},
+	}
+
+	got := ParseUnifiedDiff(diff)
+	if len(got) != len(want) {
+		t.Fatalf("ParseUnifiedDiff() returned %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseUnifiedDiff()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMergeFileChangesOrdering(t *testing.T) {
+	a := &Analyzer{}
+	changes := []types.FileChange{
+		{Path: "zeta.go", Status: types.StatusModified, Additions: 1},
+		{Path: "alpha.go", Status: types.StatusAdded, Additions: 2},
+		{Path: "cmd/root.go", Status: types.StatusModified, Deletions: 1},
+		{Path: "alpha.go", Status: types.StatusModified, Additions: 3},
+		{Path: "beta.go", Status: types.StatusDeleted, Deletions: 4},
+	}
+	want := []string{"alpha.go", "beta.go", "cmd/root.go", "zeta.go"}
+
+	// Map iteration order varies between runs, so check several merges
+	for i := 0; i < 20; i++ {
+		merged := a.mergeFileChanges(changes)
+		if len(merged) != len(want) {
+			t.Fatalf("mergeFileChanges() returned %d changes, want %d", len(merged), len(want))
+		}
+		for j, change := range merged {
+			if change.Path != want[j] {
+				t.Fatalf("mergeFileChanges()[%d].Path = %q, want %q", j, change.Path, want[j])
+			}
+		}
+		if merged[0].Additions != 5 {
+			t.Errorf("merged alpha.go additions = %d, want 5", merged[0].Additions)
+		}
+	}
+}
+
+func TestGetAmendDiff(t *testing.T) {
+	dir, run := newTestRepo(t)
+	stageFile := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", name)
+	}
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	amendDiffPaths := func() string {
+		t.Helper()
+		diff, err := a.GetAmendDiff()
+		if err != nil {
+			t.Fatalf("GetAmendDiff() error = %v", err)
+		}
+		var paths []string
+		for _, change := range ParseUnifiedDiff(diff) {
+			paths = append(paths, change.Path)
+		}
+		return strings.Join(paths, ",")
+	}
+
+	// The root commit has no parent and is compared with the empty tree
+	stageFile("root.txt")
+	if got := amendDiffPaths(); got != "root.txt" {
+		t.Errorf("GetAmendDiff() on the root commit files = %v, want root.txt", got)
+	}
+
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "add root")
+	stageFile("last.txt")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "add last")
+	stageFile("staged.txt")
+
+	if got := amendDiffPaths(); got != "last.txt,staged.txt" {
+		t.Errorf("GetAmendDiff() files = %v, want last.txt,staged.txt", got)
+	}
+}
+
+func TestDiffContext(t *testing.T) {
+	dir, run := newTestRepo(t)
+	lines := []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+	writeLines := func() {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeLines()
+	run("add", "file.txt")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "add file")
+	// An amended commit on top would show the staged change alone
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "to amend")
+	lines[4] = "FIVE"
+	writeLines()
+	run("add", "file.txt")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		context  int
+		set      bool
+		wantHunk string
+	}{
+		{name: "Git default", wantHunk: "@@ -2,7 +2,7 @@"},
+		{name: "No context", context: 0, set: true, wantHunk: "@@ -5 +5 @@"},
+		{name: "One line", context: 1, set: true, wantHunk: "@@ -4,3 +4,3 @@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.diffContext = -1
+			if tt.set {
+				a.SetDiffContext(tt.context)
+			}
+
+			diff, err := a.GetDiff(true)
+			if err != nil {
+				t.Fatalf("GetDiff() error = %v", err)
+			}
+			if !strings.Contains(diff, tt.wantHunk) {
+				t.Errorf("GetDiff() hunk header missing %q:\n%s", tt.wantHunk, diff)
+			}
+
+			amend, err := a.GetAmendDiff()
+			if err != nil {
+				t.Fatalf("GetAmendDiff() error = %v", err)
+			}
+			if !strings.Contains(amend, tt.wantHunk) {
+				t.Errorf("GetAmendDiff() hunk header missing %q:\n%s", tt.wantHunk, amend)
+			}
+		})
+	}
+}
+
+// newBranchRepo returns a repository on a feature branch that changes
+// fileCount files since main
+func newBranchRepo(tb testing.TB, fileCount int) string {
+	tb.Helper()
+	dir, run := newTestRepo(tb)
+	run("checkout", "-q", "-b", "feature")
+	for i := 0; i < fileCount; i++ {
+		content := strings.Repeat("line\n", i+1)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), []byte(content), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	run("add", ".")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "add files")
+	return dir
+}
+
+func TestSkipFileStats(t *testing.T) {
+	dir := newBranchRepo(t, 3)
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	for _, skip := range []bool{false, true} {
+		a.SetSkipFileStats(skip)
+		summary, err := a.GetBranchDiff("main")
+		if err != nil {
+			t.Fatalf("GetBranchDiff() error = %v", err)
+		}
+
+		// The totals come from git diff --stat either way
+		if summary.TotalFiles != 3 || summary.Additions != 6 {
+			t.Errorf("skip %v: totals = %d files, %d additions, want 3 files, 6 additions", skip, summary.TotalFiles, summary.Additions)
+		}
+		if len(summary.FileChanges) != 3 {
+			t.Fatalf("skip %v: len(FileChanges) = %d, want 3", skip, len(summary.FileChanges))
+		}
+		additions := 0
+		for _, change := range summary.FileChanges {
+			additions += change.Additions
+		}
+		if want := map[bool]int{false: 6, true: 0}[skip]; additions != want {
+			t.Errorf("skip %v: per-file additions = %d, want %d", skip, additions, want)
+		}
+	}
+}
+
+func BenchmarkGetBranchDiff(b *testing.B) {
+	dir := newBranchRepo(b, 50)
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		b.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	for _, mode := range []struct {
+		name string
+		skip bool
+	}{{name: "file stats", skip: false}, {name: "no-stat", skip: true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			a.SetSkipFileStats(mode.skip)
+			for i := 0; i < b.N; i++ {
+				if _, err := a.GetBranchDiff("main"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// ExcludeCommits drops the commits left out of a PR/MR description: those
+// whose author ("Name <email>") contains one of authors, ignoring case, and
+// those whose subject matches one of the regular expressions in patterns
+func ExcludeCommits(commits []types.CommitInfo, authors, patterns []string) ([]types.CommitInfo, error) {
+	if len(authors) == 0 && len(patterns) == 0 {
+		return commits, nil
+	}
+
+	compiled, err := CompileCommitPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]types.CommitInfo, 0, len(commits))
+	for _, commit := range commits {
+		if !excludedAuthor(commit, authors) && !excludedMessage(commit, compiled) {
+			kept = append(kept, commit)
+		}
+	}
+	return kept, nil
+}
+
+// CompileCommitPatterns compiles the git.exclude_commit_patterns expressions
+func CompileCommitPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit exclusion pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func excludedAuthor(commit types.CommitInfo, authors []string) bool {
+	identity := strings.ToLower(fmt.Sprintf("%s <%s>", commit.Author, commit.Email))
+	for _, author := range authors {
+		if author = strings.ToLower(strings.TrimSpace(author)); author != "" && strings.Contains(identity, author) {
+			return true
+		}
+	}
+	return false
+}
+
+func excludedMessage(commit types.CommitInfo, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(commit.Message) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestExcludeCommits(t *testing.T) {
+	commits := []types.CommitInfo{
+		{Hash: "1", Message: "feat: add export", Author: "Ada", Email: "ada@example.com"},
+		{Hash: "2", Message: "build(deps): bump x/net", Author: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"},
+		{Hash: "3", Message: "chore: release 1.2.0", Author: "Release Bot", Email: "release@example.com"},
+		{Hash: "4", Message: "fix: handle empty input", Author: "Bob", Email: "bob@example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		authors  []string
+		patterns []string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "No exclusions", want: []string{"1", "2", "3", "4"}},
+		{name: "Author by name, ignoring case", authors: []string{"Dependabot"}, want: []string{"1", "3", "4"}},
+		{name: "Author by email", authors: []string{"release@example.com"}, want: []string{"1", "2", "4"}},
+		{name: "Message pattern", patterns: []string{`^chore: release`, `^build\(deps\)`}, want: []string{"1", "4"}},
+		{name: "Invalid pattern", patterns: []string{`(`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExcludeCommits(commits, tt.authors, tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExcludeCommits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var hashes []string
+			for _, commit := range got {
+				hashes = append(hashes, commit.Hash)
+			}
+			if len(hashes) != len(tt.want) {
+				t.Fatalf("ExcludeCommits() = %v, want %v", hashes, tt.want)
+			}
+			for i := range hashes {
+				if hashes[i] != tt.want[i] {
+					t.Errorf("ExcludeCommits() = %v, want %v", hashes, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
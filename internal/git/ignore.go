@@ -0,0 +1,93 @@
+package git
+
+import (
+	"path"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// MatchIgnorePattern reports the first of auto-pr's ignore patterns matching
+// the file path. Patterns ending in "/" match a directory anywhere in the
+// path, patterns containing "/" match the whole path, and any other pattern
+// matches the base name, so "*.log" matches "logs/debug.log".
+func MatchIgnorePattern(filePath string, patterns []string) (string, bool) {
+	filePath = strings.TrimPrefix(filePath, "./")
+	segments := strings.Split(strings.TrimSuffix(filePath, "/"), "/")
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(pattern, "/"):
+			dir := strings.TrimSuffix(pattern, "/")
+			// The last segment is only a directory when git reports it as one
+			dirs := segments[:len(segments)-1]
+			if strings.HasSuffix(filePath, "/") {
+				dirs = segments
+			}
+			for _, segment := range dirs {
+				if matched, _ := path.Match(dir, segment); matched {
+					return pattern, true
+				}
+			}
+		case strings.Contains(pattern, "/"):
+			if matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), filePath); matched {
+				return pattern, true
+			}
+		default:
+			if matched, _ := path.Match(pattern, segments[len(segments)-1]); matched {
+				return pattern, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// ExcludeFileChanges splits off the changes to files matching the patterns,
+// as MatchIgnorePattern matches them, returning the other changes and the
+// paths left out
+func ExcludeFileChanges(changes []types.FileChange, patterns []string) ([]types.FileChange, []string) {
+	if len(patterns) == 0 {
+		return changes, nil
+	}
+
+	var kept []types.FileChange
+	var excluded []string
+	for _, change := range changes {
+		if _, ok := MatchIgnorePattern(change.Path, patterns); ok {
+			excluded = append(excluded, change.Path)
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept, excluded
+}
+
+// ExcludeFromDiff drops the sections of a unified diff for files matching
+// the patterns
+func ExcludeFromDiff(diff string, patterns []string) string {
+	if len(patterns) == 0 || diff == "" {
+		return diff
+	}
+
+	var kept strings.Builder
+	skip := false
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			skip = false
+			// "diff --git a/path b/path" - match the destination path
+			if idx := strings.LastIndex(line, " b/"); idx != -1 {
+				_, skip = MatchIgnorePattern(strings.TrimRight(line[idx+3:], "\n"), patterns)
+			}
+		}
+		if !skip {
+			kept.WriteString(line)
+		}
+	}
+	return kept.String()
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestMatchIgnorePattern(t *testing.T) {
+	patterns := []string{"*.log", "node_modules/", "build/*.tmp"}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantPattern string
+		wantMatch   bool
+	}{
+		{
+			name:        "Base name glob at root",
+			path:        "debug.log",
+			wantPattern: "*.log",
+			wantMatch:   true,
+		},
+		{
+			name:        "Base name glob in subdirectory",
+			path:        "logs/server.log",
+			wantPattern: "*.log",
+			wantMatch:   true,
+		},
+		{
+			name:        "File inside ignored directory",
+			path:        "web/node_modules/react/index.js",
+			wantPattern: "node_modules/",
+			wantMatch:   true,
+		},
+		{
+			name:        "Untracked directory reported by git",
+			path:        "node_modules/",
+			wantPattern: "node_modules/",
+			wantMatch:   true,
+		},
+		{
+			name:      "File named like an ignored directory",
+			path:      "node_modules",
+			wantMatch: false,
+		},
+		{
+			name:        "Pattern with path",
+			path:        "build/out.tmp",
+			wantPattern: "build/*.tmp",
+			wantMatch:   true,
+		},
+		{
+			name:      "Pattern with path does not match elsewhere",
+			path:      "src/build/out.tmp",
+			wantMatch: false,
+		},
+		{
+			name:      "Regular source file",
+			path:      "cmd/root.go",
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, matched := MatchIgnorePattern(tt.path, patterns)
+			if matched != tt.wantMatch || pattern != tt.wantPattern {
+				t.Errorf("MatchIgnorePattern(%q) = (%q, %v), want (%q, %v)",
+					tt.path, pattern, matched, tt.wantPattern, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestExcludeFromContext(t *testing.T) {
+	patterns := []string{"go.sum", "package-lock.json"}
+
+	changes := []types.FileChange{{Path: "main.go"}, {Path: "go.sum"}, {Path: "web/package-lock.json"}}
+	kept, excluded := ExcludeFileChanges(changes, patterns)
+	if len(kept) != 1 || kept[0].Path != "main.go" {
+		t.Errorf("ExcludeFileChanges() kept %v, want only main.go", kept)
+	}
+	if want := []string{"go.sum", "web/package-lock.json"}; !reflect.DeepEqual(excluded, want) {
+		t.Errorf("ExcludeFileChanges() excluded %v, want %v", excluded, want)
+	}
+
+	diff := "diff --git a/go.sum b/go.sum\n+hash\n" +
+		"diff --git a/main.go b/main.go\n+code\n" +
+		"diff --git a/web/package-lock.json b/web/package-lock.json\n+lock\n"
+	got := ExcludeFromDiff(diff, patterns)
+	if got != "diff --git a/main.go b/main.go\n+code\n" {
+		t.Errorf("ExcludeFromDiff() = %q, want only the main.go section", got)
+	}
+	if ExcludeFromDiff(diff, nil) != diff {
+		t.Error("ExcludeFromDiff() changed the diff without patterns")
+	}
+	if strings.Contains(ExcludeFromDiff(diff, []string{"*.go"}), "+code") {
+		t.Error("ExcludeFromDiff() kept a section matching a glob")
+	}
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit creates a commit (or amends the last one) with the given message and
+// returns the hash of the new commit
+func (a *Analyzer) Commit(message string, amend bool) (string, error) {
+	// Read the message from stdin so multi-paragraph bodies and trailers are kept as-is
+	args := []string{"commit", "--file", "-"}
+	if amend {
+		args = []string{"commit", "--amend", "--file", "-"}
+	}
+
+	if output, err := a.runner.RunWithInput(message, args...); err != nil {
+		return "", fmt.Errorf("failed to create commit: %w\nOutput: %s", err, commandOutput(output, err))
+	}
+
+	return a.GetHeadHash()
+}
+
+// GetHeadHash returns the full hash of the HEAD commit
+func (a *Analyzer) GetHeadHash() (string, error) {
+	output, err := a.runner.Run("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Push pushes the current branch, setting the upstream on origin when the
+// branch has not been pushed before
+func (a *Analyzer) Push() error {
+	if firstOutput, firstErr := a.runner.Run("push"); firstErr != nil {
+		// If that fails, try push with set-upstream for new branches
+		if output, err := a.runner.Run("push", "--set-upstream", "origin", "HEAD"); err != nil {
+			// Both attempts can fail for different reasons, so report both
+			return fmt.Errorf("failed to push: %w\nOutput: %s\n%s", err,
+				strings.TrimSpace(commandOutput(firstOutput, firstErr)), strings.TrimSpace(commandOutput(output, err)))
+		}
+	}
+	return nil
+}
+
+// PushTarget returns where Push would send the branch, as remote/branch: its
+// upstream, or origin/<branch> when Push would have to set the upstream
+func (a *Analyzer) PushTarget(branch string) string {
+	if output, err := a.runner.Run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		if upstream := strings.TrimSpace(string(output)); upstream != "" {
+			return upstream
+		}
+	}
+	return "origin/" + branch
+}
+
+// Checkout switches to an existing branch
+func (a *Analyzer) Checkout(branch string) error {
+	if output, err := a.runner.Run("checkout", branch); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w\nOutput: %s", branch, err, commandOutput(output, err))
+	}
+	return nil
+}
+
+// BranchExists reports whether branch exists locally or as a
+// remote-tracking branch on origin
+func (a *Analyzer) BranchExists(branch string) bool {
+	_, err := a.resolveBranchRef(branch)
+	return err == nil
+}
+
+// CreateBranch creates a new branch from HEAD and switches to it
+func (a *Analyzer) CreateBranch(name string) error {
+	if output, err := a.runner.Run("checkout", "-b", name); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w\nOutput: %s", name, err, commandOutput(output, err))
+	}
+	return nil
+}
+
+// StageFiles stages the given paths, including deletions of tracked files
+func (a *Analyzer) StageFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	if output, err := a.runner.Run(append([]string{"add", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("failed to stage changes: %w\nOutput: %s", err, commandOutput(output, err))
+	}
+	return nil
+}
+
+// UnstageAll empties the index of staged changes, leaving the work tree as it is
+func (a *Analyzer) UnstageAll() error {
+	if output, err := a.runner.Run("reset", "-q"); err != nil {
+		return fmt.Errorf("failed to unstage changes: %w\nOutput: %s", err, commandOutput(output, err))
+	}
+	return nil
+}
+
+// GetUntrackedFiles lists untracked files not excluded by .gitignore, listing
+// the files inside untracked directories individually
+func (a *Analyzer) GetUntrackedFiles() ([]string, error) {
+	output, err := a.runner.Run("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
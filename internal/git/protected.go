@@ -0,0 +1,19 @@
+package git
+
+import "path"
+
+// IsProtectedBranch reports whether the branch matches one of the protected
+// branch patterns, which are exact names or globs such as "release/*"
+func IsProtectedBranch(branch string, patterns []string) bool {
+	if branch == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,28 @@
+package git
+
+import "testing"
+
+func TestIsProtectedBranch(t *testing.T) {
+	patterns := []string{"main", "master", "release/*"}
+
+	tests := []struct {
+		name   string
+		branch string
+		want   bool
+	}{
+		{name: "Exact name", branch: "main", want: true},
+		{name: "Glob match", branch: "release/1.2", want: true},
+		{name: "Glob does not cross slashes", branch: "release/1.2/hotfix", want: false},
+		{name: "Feature branch", branch: "feature/login", want: false},
+		{name: "Prefix of a protected name", branch: "main-backup", want: false},
+		{name: "Detached HEAD", branch: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsProtectedBranch(tt.branch, patterns); got != tt.want {
+				t.Errorf("IsProtectedBranch(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
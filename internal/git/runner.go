@@ -0,0 +1,46 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// CommandRunner runs git commands in a repository on behalf of an Analyzer.
+// The default runs the git executable; tests swap in a fake with
+// Analyzer.SetCommandRunner to feed the Analyzer canned git output.
+type CommandRunner interface {
+	// Run runs git with args and returns its standard output
+	Run(args ...string) ([]byte, error)
+
+	// RunWithInput runs git with args, feeding input to its standard input
+	RunWithInput(input string, args ...string) ([]byte, error)
+}
+
+// execRunner runs the git executable in the directory dir. A failed
+// command's error is an *exec.ExitError holding what git wrote to stderr.
+type execRunner struct {
+	dir string
+}
+
+// Run runs git in the runner's directory
+func (r execRunner) Run(args ...string) ([]byte, error) {
+	return exec.Command("git", append([]string{"-C", r.dir}, args...)...).Output()
+}
+
+// RunWithInput runs git in the runner's directory with input on stdin
+func (r execRunner) RunWithInput(input string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.Output()
+}
+
+// commandOutput returns everything a failed command printed, its stdout
+// followed by the stderr carried in the error, for error messages
+func commandOutput(output []byte, err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return string(output) + string(exitErr.Stderr)
+	}
+	return string(output)
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeRunner answers git commands with canned output, keyed by the command's
+// arguments joined with spaces. Commands it has no output for fail.
+type fakeRunner struct {
+	outputs map[string]string
+	calls   []string
+	inputs  []string
+}
+
+func (f *fakeRunner) Run(args ...string) ([]byte, error) {
+	key := strings.Join(args, " ")
+	f.calls = append(f.calls, key)
+	output, ok := f.outputs[key]
+	if !ok {
+		return nil, fmt.Errorf("unexpected command: git %s", key)
+	}
+	return []byte(output), nil
+}
+
+func (f *fakeRunner) RunWithInput(input string, args ...string) ([]byte, error) {
+	f.inputs = append(f.inputs, input)
+	return f.Run(args...)
+}
+
+// newFakeAnalyzer returns an analyzer that runs git through a fakeRunner
+// with the given outputs
+func newFakeAnalyzer(t *testing.T, outputs map[string]string) (*Analyzer, *fakeRunner) {
+	t.Helper()
+	a, err := NewAnalyzer(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	runner := &fakeRunner{outputs: outputs}
+	a.SetCommandRunner(runner)
+	return a, runner
+}
+
+func TestCommitWithRunner(t *testing.T) {
+	message := "feat: add export\n\nExports widgets.\n\nRefs: #12"
+	a, runner := newFakeAnalyzer(t, map[string]string{
+		"commit --file -": "",
+		"rev-parse HEAD":  "abc123\n",
+	})
+
+	hash, err := a.Commit(message, false)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if hash != "abc123" {
+		t.Errorf("Commit() = %q, want abc123", hash)
+	}
+	if len(runner.inputs) != 1 || runner.inputs[0] != message {
+		t.Errorf("Commit() passed %q on stdin, want the message unchanged", runner.inputs)
+	}
+}
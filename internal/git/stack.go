@@ -0,0 +1,123 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StackParent returns the branch that branch is stacked on: of the local
+// branches and base, the one whose fork point with branch is nearest to it.
+// Ties go to base, so a branch forked straight off base targets base.
+func (a *Analyzer) StackParent(branch, base string) (string, error) {
+	locals, err := a.localBranches()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := a.resolveCommit(branch)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{base}
+	for _, local := range locals {
+		if local == branch || local == base {
+			continue
+		}
+		// Branches built on top of this one share its tip as their merge-base
+		if mergeBase, err := a.mergeBase(local, branch); err != nil || mergeBase == head {
+			continue
+		}
+		candidates = append(candidates, local)
+	}
+
+	return a.nearestForkPoint(branch, candidates)
+}
+
+// nearestForkPoint returns the candidate whose merge-base with head leaves
+// the fewest commits on head, preferring earlier candidates on ties
+func (a *Analyzer) nearestForkPoint(head string, candidates []string) (string, error) {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		ref, err := a.resolveBranchRef(candidate)
+		if err != nil {
+			continue
+		}
+
+		mergeBase, err := a.mergeBase(ref, head)
+		if err != nil {
+			continue // Unrelated history
+		}
+
+		distance, err := a.countCommits(mergeBase + ".." + head)
+		if err != nil {
+			continue
+		}
+
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("none of %s share history with %s", strings.Join(candidates, ", "), head)
+	}
+	return best, nil
+}
+
+// localBranches returns the names of all local branches, sorted
+func (a *Analyzer) localBranches() ([]string, error) {
+	output, err := a.runner.Run("for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			branches = append(branches, line)
+		}
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// resolveBranchRef returns branch when it exists locally, or its counterpart
+// on origin when only the remote-tracking branch exists
+func (a *Analyzer) resolveBranchRef(branch string) (string, error) {
+	for _, ref := range []string{branch, "origin/" + branch} {
+		if _, err := a.resolveCommit(ref); err == nil {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("branch %s not found", branch)
+}
+
+// resolveCommit returns the full hash of the commit ref points at
+func (a *Analyzer) resolveCommit(ref string) (string, error) {
+	output, err := a.runner.Run("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// mergeBase returns the best common ancestor of two commits
+func (a *Analyzer) mergeBase(left, right string) (string, error) {
+	output, err := a.runner.Run("merge-base", left, right)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge-base of %s and %s: %w", left, right, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// countCommits returns the number of commits in a revision range
+func (a *Analyzer) countCommits(revRange string) (int, error) {
+	output, err := a.runner.Run("rev-list", "--count", revRange)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits in %s: %w", revRange, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
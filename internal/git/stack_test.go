@@ -0,0 +1,100 @@
+package git
+
+import "testing"
+
+func TestStackParent(t *testing.T) {
+	dir, run := newTestRepo(t)
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+
+	// main <- feature/a <- feature/b <- feature/c, plus an unrelated sibling
+	commit("main work")
+	run("checkout", "-q", "-b", "feature/a")
+	commit("a1")
+	commit("a2")
+	run("checkout", "-q", "-b", "feature/b")
+	commit("b1")
+	run("checkout", "-q", "-b", "feature/c")
+	commit("c1")
+	run("checkout", "-q", "-b", "sibling", "main")
+	commit("s1")
+	run("checkout", "-q", "-b", "fresh", "main")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		want   string
+	}{
+		{name: "Bottom of the stack", branch: "feature/a", want: "main"},
+		{name: "Middle of the stack", branch: "feature/b", want: "feature/a"},
+		{name: "Top of the stack", branch: "feature/c", want: "feature/b"},
+		{name: "Sibling of the stack", branch: "sibling", want: "main"},
+		{name: "Branch with no commits of its own", branch: "fresh", want: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.StackParent(tt.branch, "main")
+			if err != nil {
+				t.Fatalf("StackParent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("StackParent(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferBaseBranch(t *testing.T) {
+	dir, run := newTestRepo(t)
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+
+	// develop forks from main, feature forks from develop, main moves on
+	run("checkout", "-q", "-b", "develop")
+	commit("d1")
+	commit("d2")
+	run("checkout", "-q", "-b", "feature")
+	commit("f1")
+	run("checkout", "-q", "main")
+	commit("m1")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		branch     string
+		candidates []string
+		want       string
+		wantErr    bool
+	}{
+		{name: "Forked from develop", branch: "feature", candidates: []string{"main", "master", "develop"}, want: "develop"},
+		{name: "Develop is not its own base", branch: "develop", candidates: []string{"main", "develop"}, want: "main"},
+		{name: "Tie goes to the first candidate", branch: "main", candidates: []string{"develop", "feature"}, want: "develop"},
+		{name: "No existing candidate", branch: "feature", candidates: []string{"master", "trunk"}, wantErr: true},
+		{name: "No candidates", branch: "feature", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run("checkout", "-q", tt.branch)
+			got, err := a.InferBaseBranch(tt.candidates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InferBaseBranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InferBaseBranch(%v) = %v, want %v", tt.candidates, got, tt.want)
+			}
+		})
+	}
+}
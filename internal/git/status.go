@@ -0,0 +1,198 @@
+package git
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// v1ConflictStates are the porcelain v1 XY codes of unmerged paths
+var v1ConflictStates = map[string]bool{
+	"DD": true, "AU": true, "UD": true, "UA": true, "DU": true, "AA": true, "UU": true,
+}
+
+// getFileStatuses returns the changed paths in the index and work tree. It
+// reads porcelain v2, which names rename sources and marks conflicts and
+// submodules, falling back to v1 for git older than 2.11.
+func (a *Analyzer) getFileStatuses() ([]types.StatusEntry, error) {
+	if output, err := a.runner.Run("status", "--porcelain=v2", "-z"); err == nil {
+		return parsePorcelainV2(string(output)), nil
+	}
+
+	output, err := a.runner.Run("status", "--porcelain=v1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+	return parsePorcelainV1(string(output))
+}
+
+// parsePorcelainV2 parses NUL-separated git status --porcelain=v2 -z output.
+// Ignored paths and header lines are skipped.
+func parsePorcelainV2(output string) []types.StatusEntry {
+	var entries []types.StatusEntry
+
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 2 {
+			continue
+		}
+
+		switch record[0] {
+		case '1':
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			fields := strings.SplitN(record, " ", 9)
+			if len(fields) < 9 {
+				continue
+			}
+			entries = append(entries, changedEntry(fields[1], fields[2], fields[8]))
+		case '2':
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, then
+			// the path it was renamed or copied from as the next record
+			fields := strings.SplitN(record, " ", 10)
+			if len(fields) < 10 {
+				continue
+			}
+			entry := changedEntry(fields[1], fields[2], fields[9])
+			if i+1 < len(records) {
+				i++
+				entry.OrigPath = records[i]
+			}
+			if len(fields[8]) > 1 {
+				entry.Score, _ = strconv.Atoi(fields[8][1:])
+			}
+			entries = append(entries, entry)
+		case 'u':
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			fields := strings.SplitN(record, " ", 11)
+			if len(fields) < 11 {
+				continue
+			}
+			entries = append(entries, types.StatusEntry{
+				Path:      fields[10],
+				Conflict:  true,
+				Submodule: strings.HasPrefix(fields[2], "S"),
+			})
+		case '?':
+			entries = append(entries, types.StatusEntry{Path: record[2:], Unstaged: types.StatusUntracked})
+		}
+	}
+
+	return entries
+}
+
+// changedEntry builds the entry of an ordinary or renamed path from its XY
+// code and submodule field
+func changedEntry(xy, sub, path string) types.StatusEntry {
+	entry := types.StatusEntry{Path: path, Submodule: strings.HasPrefix(sub, "S")}
+	if len(xy) == 2 {
+		entry.Staged = entryStatus(xy[0])
+		entry.Unstaged = entryStatus(xy[1])
+	}
+	return entry
+}
+
+// entryStatus maps a status column to a change, with '.' (porcelain v2) and
+// ' ' (v1) meaning unchanged
+func entryStatus(code byte) types.ChangeStatus {
+	if code == '.' || code == ' ' {
+		return ""
+	}
+	return mapGitStatus(string(code))
+}
+
+// parsePorcelainV1 parses git status --porcelain=v1 output, for git too old
+// to support v2
+func parsePorcelainV1(output string) ([]types.StatusEntry, error) {
+	var entries []types.StatusEntry
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 4 {
+			continue
+		}
+
+		xy, path := line[:2], line[3:]
+		switch {
+		case xy == "??":
+			entries = append(entries, types.StatusEntry{Path: path, Unstaged: types.StatusUntracked})
+		case xy == "!!":
+			continue
+		case v1ConflictStates[xy]:
+			entries = append(entries, types.StatusEntry{Path: path, Conflict: true})
+		default:
+			entry := changedEntry(xy, "N...", path)
+			// Renames and copies are shown as "<from> -> <to>"
+			if xy[0] == 'R' || xy[0] == 'C' {
+				if from, to, found := strings.Cut(path, " -> "); found {
+					entry.OrigPath, entry.Path = from, to
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// fillFileLists sorts the entries into the status's staged, unstaged,
+// untracked and unmerged file lists
+func fillFileLists(status *types.GitStatus, entries []types.StatusEntry) {
+	for _, entry := range entries {
+		switch {
+		case entry.Conflict:
+			status.UnmergedFiles = append(status.UnmergedFiles, entry.Path)
+		case entry.Unstaged == types.StatusUntracked:
+			status.UntrackedFiles = append(status.UntrackedFiles, entry.Path)
+		default:
+			if entry.Staged != "" {
+				status.StagedFiles = append(status.StagedFiles, entry.Path)
+			}
+			if entry.Unstaged != "" {
+				status.UnstagedFiles = append(status.UnstagedFiles, entry.Path)
+			}
+		}
+	}
+}
+
+// WorkTreeHash returns a hash of the uncommitted changes: the index and work
+// tree diffed against HEAD, plus the names and contents of untracked files.
+// It stays the same for as long as the changes do.
+func (a *Analyzer) WorkTreeHash() (string, error) {
+	diff, err := a.runner.Run("diff", "--binary", "--no-ext-diff", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to diff against HEAD: %w", err)
+	}
+
+	hash := sha256.New()
+	hash.Write(diff)
+
+	output, err := a.runner.Run("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var untracked []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			untracked = append(untracked, file)
+		}
+	}
+
+	if len(untracked) > 0 {
+		// hash-object prints one blob hash per file, so renaming or editing
+		// a new file changes the result
+		objects, err := a.runner.Run(append([]string{"hash-object", "--"}, untracked...)...)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash untracked files: %w", err)
+		}
+		fmt.Fprintf(hash, "\x00%s\x00%s", strings.Join(untracked, "\x00"), objects)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
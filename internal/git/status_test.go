@@ -0,0 +1,181 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestParsePorcelainV2(t *testing.T) {
+	output := strings.Join([]string{
+		"1 M. N... 100644 100644 100644 3f1a 3f1b staged.go",
+		"1 .M N... 100644 100644 100644 3f1a 3f1a unstaged.go",
+		"1 AM N... 000000 100644 100644 0000 4c2d dir with spaces/added.go",
+		"2 R. N... 100644 100644 100644 9e8d 9e8d R87 pkg/new name.go",
+		"pkg/old name.go",
+		"2 C. N... 100644 100644 100644 7b6a 7b6a C100 copy.go",
+		"orig.go",
+		"u UU N... 100644 100644 100644 100644 a1 b2 c3 conflicted.go",
+		"1 .M SC.. 160000 160000 160000 d4e5 d4e5 vendor/lib",
+		"? new.go",
+		"! ignored.log",
+		"",
+	}, "\x00")
+
+	want := []types.StatusEntry{
+		{Path: "staged.go", Staged: types.StatusModified},
+		{Path: "unstaged.go", Unstaged: types.StatusModified},
+		{Path: "dir with spaces/added.go", Staged: types.StatusAdded, Unstaged: types.StatusModified},
+		{Path: "pkg/new name.go", OrigPath: "pkg/old name.go", Staged: types.StatusRenamed, Score: 87},
+		{Path: "copy.go", OrigPath: "orig.go", Staged: types.StatusCopied, Score: 100},
+		{Path: "conflicted.go", Conflict: true},
+		{Path: "vendor/lib", Unstaged: types.StatusModified, Submodule: true},
+		{Path: "new.go", Unstaged: types.StatusUntracked},
+	}
+
+	if got := parsePorcelainV2(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePorcelainV2() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParsePorcelainV1(t *testing.T) {
+	output := "M  staged.go\n M unstaged.go\nMM both.go\nR  old.go -> new.go\nUU conflicted.go\n?? docs/\n"
+
+	want := []types.StatusEntry{
+		{Path: "staged.go", Staged: types.StatusModified},
+		{Path: "unstaged.go", Unstaged: types.StatusModified},
+		{Path: "both.go", Staged: types.StatusModified, Unstaged: types.StatusModified},
+		{Path: "new.go", OrigPath: "old.go", Staged: types.StatusRenamed},
+		{Path: "conflicted.go", Conflict: true},
+		{Path: "docs/", Unstaged: types.StatusUntracked},
+	}
+
+	got, err := parsePorcelainV1(output)
+	if err != nil {
+		t.Fatalf("parsePorcelainV1() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePorcelainV1() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestGetFileStatusesFallsBackToV1(t *testing.T) {
+	// The fake runner fails the v2 command, as git before 2.11 does
+	a, runner := newFakeAnalyzer(t, map[string]string{
+		"status --porcelain=v1": "M  staged.go\n?? new.go\n",
+	})
+
+	entries, err := a.getFileStatuses()
+	if err != nil {
+		t.Fatalf("getFileStatuses() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Path != "staged.go" || entries[1].Unstaged != types.StatusUntracked {
+		t.Errorf("getFileStatuses() = %+v, want the v1 entries", entries)
+	}
+	if len(runner.calls) != 2 || runner.calls[0] != "status --porcelain=v2 -z" {
+		t.Errorf("getFileStatuses() ran %v, want v2 then v1", runner.calls)
+	}
+}
+
+func TestGetStatusFileLists(t *testing.T) {
+	dir, run := newTestRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit := func(message string) {
+		t.Helper()
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-am", message)
+	}
+
+	write("conflict.txt", "base\n")
+	write("rename me.txt", strings.Repeat("unchanged line\n", 10))
+	run("add", ".")
+	commit("add files")
+
+	run("checkout", "-q", "-b", "other")
+	write("conflict.txt", "other\n")
+	commit("change on other")
+	run("checkout", "-q", "main")
+	write("conflict.txt", "main\n")
+	commit("change on main")
+	// The merge fails, leaving conflict.txt unmerged
+	_ = exec.Command("git", "-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "merge", "-q", "other").Run()
+
+	run("mv", "rename me.txt", "renamed.txt")
+	write("untracked.txt", "new\n")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	status, err := a.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+
+	if strings.Join(status.UnmergedFiles, ",") != "conflict.txt" {
+		t.Errorf("UnmergedFiles = %v, want conflict.txt", status.UnmergedFiles)
+	}
+	if len(status.UnstagedFiles) != 0 {
+		t.Errorf("UnstagedFiles = %v, want the unmerged file left out", status.UnstagedFiles)
+	}
+	if strings.Join(status.StagedFiles, ",") != "renamed.txt" {
+		t.Errorf("StagedFiles = %v, want only the rename target", status.StagedFiles)
+	}
+	if strings.Join(status.UntrackedFiles, ",") != "untracked.txt" {
+		t.Errorf("UntrackedFiles = %v, want untracked.txt", status.UntrackedFiles)
+	}
+
+	var renamed *types.StatusEntry
+	for i := range status.Entries {
+		if status.Entries[i].Path == "renamed.txt" {
+			renamed = &status.Entries[i]
+		}
+	}
+	if renamed == nil || renamed.OrigPath != "rename me.txt" || renamed.Staged != types.StatusRenamed || renamed.Score != 100 {
+		t.Errorf("rename entry = %+v, want renamed.txt from \"rename me.txt\" with score 100", renamed)
+	}
+}
+
+func TestWorkTreeHash(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	hash := func() string {
+		t.Helper()
+		h, err := a.WorkTreeHash()
+		if err != nil {
+			t.Fatalf("WorkTreeHash() error = %v", err)
+		}
+		return h
+	}
+
+	clean := hash()
+	if err := os.WriteFile(filepath.Join(dir, "new file.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	added := hash()
+	if added == clean {
+		t.Error("WorkTreeHash() unchanged after adding an untracked file")
+	}
+	if again := hash(); again != added {
+		t.Errorf("WorkTreeHash() = %s then %s for the same changes", added, again)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "new file.txt"), []byte("two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if edited := hash(); edited == added {
+		t.Error("WorkTreeHash() unchanged after editing an untracked file")
+	}
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// GetLatestTag returns the most recent tag reachable from HEAD, optionally
+// limited to tags matching a glob pattern such as "v*"
+func (a *Analyzer) GetLatestTag(pattern string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if pattern != "" {
+		args = append(args, "--match", pattern)
+	}
+
+	output, err := a.runner.Run(append(args, a.headRef())...)
+	if err != nil {
+		if pattern != "" {
+			return "", fmt.Errorf("no tag matching %q found before HEAD", pattern)
+		}
+		return "", fmt.Errorf("no tag found before HEAD")
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetCommitsSinceTag returns the commits made after the given tag, newest
+// first, leaving out merge commits. A limit of 0 or less returns every commit.
+func (a *Analyzer) GetCommitsSinceTag(tag string, limit int) ([]types.CommitInfo, error) {
+	args := []string{"log", "--no-merges"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("-%d", limit))
+	}
+
+	// refs/tags/ keeps a branch with the same name from being picked instead
+	output, err := a.runner.Run(append(args,
+		fmt.Sprintf("refs/tags/%s..%s", tag, a.headRef()),
+		commitLogFormat,
+		"--name-only")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits since tag %s: %w", tag, err)
+	}
+
+	if strings.TrimSpace(string(output)) == "" {
+		return []types.CommitInfo{}, nil // Nothing since the tag
+	}
+
+	return a.parseCommitHistory(string(output))
+}
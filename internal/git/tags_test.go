@@ -0,0 +1,84 @@
+package git
+
+import "testing"
+
+func TestGetLatestTag(t *testing.T) {
+	dir, run := newTestRepo(t)
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	if _, err := a.GetLatestTag(""); err == nil {
+		t.Error("GetLatestTag() in a repository without tags returned no error")
+	}
+
+	run("tag", "v1.0.0")
+	commit("feat: first feature")
+	run("tag", "nightly-1")
+	commit("fix: a bug")
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "Any tag", pattern: "", want: "nightly-1"},
+		{name: "Matching pattern", pattern: "v*", want: "v1.0.0"},
+		{name: "No tag matches", pattern: "release-*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.GetLatestTag(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetLatestTag(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetLatestTag(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitsSinceTag(t *testing.T) {
+	dir, run := newTestRepo(t)
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+
+	commit("before the release")
+	run("tag", "v1.0.0")
+	commit("feat: add export")
+	commit("fix: handle empty input")
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	commits, err := a.GetCommitsSinceTag("v1.0.0", 0)
+	if err != nil {
+		t.Fatalf("GetCommitsSinceTag() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Message != "fix: handle empty input" || commits[1].Message != "feat: add export" {
+		t.Errorf("GetCommitsSinceTag() = %+v, want the two commits after the tag, newest first", commits)
+	}
+
+	limited, err := a.GetCommitsSinceTag("v1.0.0", 1)
+	if err != nil {
+		t.Fatalf("GetCommitsSinceTag() with limit error = %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("GetCommitsSinceTag() with limit 1 returned %d commits", len(limited))
+	}
+
+	if _, err := a.GetCommitsSinceTag("v9.9.9", 0); err == nil {
+		t.Error("GetCommitsSinceTag() with an unknown tag returned no error")
+	}
+}
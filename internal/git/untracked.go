@@ -0,0 +1,158 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// maxUntrackedContentSize is the largest untracked file whose contents are
+// shown; larger files are only counted
+const maxUntrackedContentSize = 32 * 1024
+
+// binarySniffLength is how much of a file is checked for NUL bytes, as git does
+const binarySniffLength = 8000
+
+// UntrackedChanges describes untracked files, which have no diff yet: each
+// becomes an untracked file change with its line count as additions, and
+// the returned diff shows the contents of the small text files as additions,
+// cut to maxSize bytes (0 means no limit). Untracked directories are expanded
+// to the files in them that aren't ignored, and binary files are listed
+// without contents.
+func (a *Analyzer) UntrackedChanges(paths []string, maxSize int) ([]types.FileChange, string) {
+	files := a.expandUntracked(paths)
+
+	var changes []types.FileChange
+	var diff strings.Builder
+	for _, file := range files {
+		change, content, ok := a.readUntracked(file)
+		if !ok {
+			continue
+		}
+		changes = append(changes, change)
+
+		fmt.Fprintf(&diff, "diff --git a/%s b/%s\nnew file mode 100644\n", file, file)
+		switch {
+		case change.IsBinary:
+			fmt.Fprintf(&diff, "Binary files /dev/null and b/%s differ\n", file)
+		case content == "":
+			if change.Additions > 0 {
+				fmt.Fprintf(&diff, "(%d lines, contents omitted)\n", change.Additions)
+			}
+		default:
+			fmt.Fprintf(&diff, "--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n", file, change.Additions)
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(content, "\n"), "\n") {
+				diff.WriteString("+" + strings.TrimSuffix(line, "\n") + "\n")
+			}
+		}
+	}
+
+	return changes, TruncateDiff(diff.String(), maxSize)
+}
+
+// expandUntracked lists the untracked, non-ignored files under the given
+// paths, which git status reports as directories when a whole directory is new
+func (a *Analyzer) expandUntracked(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	output, err := a.runner.Run(append([]string{"ls-files", "--others", "--exclude-standard", "-z", "--"}, paths...)...)
+	if err != nil {
+		return paths
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// readUntracked counts the lines of an untracked file and returns its
+// contents when it is a small text file
+func (a *Analyzer) readUntracked(file string) (types.FileChange, string, bool) {
+	change := types.FileChange{Path: file, Status: types.StatusUntracked}
+
+	f, err := os.Open(filepath.Join(a.repoPath, file))
+	if err != nil {
+		return change, "", false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return change, "", false
+	}
+
+	reader := bufio.NewReader(f)
+	head, _ := reader.Peek(binarySniffLength)
+	if a.isBinaryFile(file) || bytes.IndexByte(head, 0) != -1 {
+		change.IsBinary = true
+		return change, "", true
+	}
+
+	if info.Size() > maxUntrackedContentSize {
+		change.Additions = countLines(reader)
+		return change, "", true
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return change, "", false
+	}
+	change.Additions = countLines(bytes.NewReader(content))
+	return change, string(content), true
+}
+
+// countLines counts the lines read from r, including a last line without a
+// trailing newline
+func countLines(r io.Reader) int {
+	lines, last := 0, byte('\n')
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err != nil {
+			break
+		}
+	}
+	if last != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// IgnoredDirs lists the directories, relative to the repository path, that
+// .gitignore leaves out as a whole
+func (a *Analyzer) IgnoredDirs() []string {
+	output, err := a.runner.Run("ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range strings.Split(string(output), "\x00") {
+		if strings.HasSuffix(entry, "/") {
+			dirs = append(dirs, strings.TrimSuffix(entry, "/"))
+		}
+	}
+	return dirs
+}
+
+// IsIgnored reports whether .gitignore leaves out path
+func (a *Analyzer) IsIgnored(path string) bool {
+	_, err := a.runner.Run("check-ignore", "-q", "--", path)
+	return err == nil
+}
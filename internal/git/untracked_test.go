@@ -0,0 +1,103 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUntrackedChanges(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	write := func(name string, content []byte) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("main.go", []byte("package main\n\nfunc main() {}\n"))
+	write("notes.txt", []byte("no trailing newline"))
+	write("pkg/util/util.go", []byte("package util\n"))
+	write("pkg/util/ignored.log", []byte("noise\n"))
+	write(".gitignore", []byte("*.log\n"))
+	write("image.bin", []byte{0x89, 'P', 'N', 'G', 0x00, 0x01})
+	write("large.txt", []byte(strings.Repeat("line\n", maxUntrackedContentSize/5+10)))
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	// git status reports the new directory rather than its files
+	changes, diff := a.UntrackedChanges([]string{".gitignore", "image.bin", "large.txt", "main.go", "notes.txt", "pkg/"}, 0)
+
+	got := make(map[string]int)
+	for _, change := range changes {
+		got[change.Path] = change.Additions
+		if change.IsBinary != (change.Path == "image.bin") {
+			t.Errorf("%s IsBinary = %v", change.Path, change.IsBinary)
+		}
+	}
+	want := map[string]int{
+		".gitignore":       1,
+		"image.bin":        0,
+		"large.txt":        maxUntrackedContentSize/5 + 10,
+		"main.go":          3,
+		"notes.txt":        1,
+		"pkg/util/util.go": 1,
+	}
+	for path, lines := range want {
+		if additions, ok := got[path]; !ok || additions != lines {
+			t.Errorf("UntrackedChanges() %s additions = %d (listed: %v), want %d", path, additions, ok, lines)
+		}
+	}
+	if _, ok := got["pkg/util/ignored.log"]; ok {
+		t.Error("UntrackedChanges() listed an ignored file")
+	}
+
+	for _, wantDiff := range []string{
+		"+++ b/main.go\n@@ -0,0 +1,3 @@\n+package main\n+\n+func main() {}\n",
+		"+no trailing newline\n",
+		"Binary files /dev/null and b/image.bin differ\n",
+		"diff --git a/large.txt b/large.txt\nnew file mode 100644\n(",
+	} {
+		if !strings.Contains(diff, wantDiff) {
+			t.Errorf("UntrackedChanges() diff missing %q", wantDiff)
+		}
+	}
+
+	if _, limited := a.UntrackedChanges([]string{"main.go", "notes.txt"}, 60); !strings.Contains(limited, "diff truncated") {
+		t.Errorf("UntrackedChanges() with a size limit = %q, want it truncated", limited)
+	}
+}
+
+func TestIgnoredDirs(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	for _, name := range []string{".gitignore", "dist/app.js", "web/build/index.html", "web/src/app.ts", "debug.log"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("dist/\nbuild/\n*.log\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	// Ignored files are left to the caller; only whole directories are listed
+	if got, want := a.IgnoredDirs(), []string{"dist", "web/build"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("IgnoredDirs() = %v, want %v", got, want)
+	}
+	if !a.IsIgnored(filepath.Join(dir, "web", "build")) || a.IsIgnored(filepath.Join(dir, "web", "src")) {
+		t.Error("IsIgnored() doesn't follow .gitignore")
+	}
+}
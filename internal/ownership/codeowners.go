@@ -0,0 +1,161 @@
+package ownership
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Rule is a CODEOWNERS line: a path pattern and the owners of matching files
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Section string // GitLab section, lowercased; empty before any section header
+	regex   *regexp.Regexp
+}
+
+// sectionHeader matches GitLab section headers such as "[Docs]",
+// "^[Optional]" or "[Backend][2] @org/backend" with default owners
+var sectionHeader = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// Codeowners holds the parsed rules of a CODEOWNERS file in file order
+type Codeowners struct {
+	Rules []Rule
+}
+
+// ParseCodeowners parses a CODEOWNERS file. Blank lines and comments are
+// skipped, as are rules whose pattern can't be compiled. GitLab section
+// headers start a new section, whose default owners apply to its rules
+// that list none.
+func ParseCodeowners(r io.Reader) (*Codeowners, error) {
+	codeowners := &Codeowners{}
+	section, sectionOwners := "", []string(nil)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Drop trailing comments
+		if idx := strings.Index(line, " #"); idx != -1 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		if match := sectionHeader.FindStringSubmatch(line); match != nil {
+			section = strings.ToLower(strings.TrimSpace(match[1]))
+			sectionOwners = strings.Fields(match[2])
+			continue
+		}
+
+		fields := strings.Fields(line)
+		regex, err := patternToRegexp(fields[0])
+		if err != nil {
+			continue
+		}
+
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = sectionOwners
+		}
+		codeowners.Rules = append(codeowners.Rules, Rule{
+			Pattern: fields[0],
+			Owners:  owners,
+			Section: section,
+			regex:   regex,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+
+	return codeowners, nil
+}
+
+// Owners returns the owners of a file. As on GitHub and GitLab the last
+// matching rule wins, and a matching rule without owners clears ownership.
+// With GitLab sections the last match wins within each section, and the
+// owners from every section are combined in file order.
+func (c *Codeowners) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+
+	var matches []Rule
+	matched := make(map[string]bool)
+	for i := len(c.Rules) - 1; i >= 0; i-- {
+		rule := c.Rules[i]
+		if matched[rule.Section] || !rule.regex.MatchString(path) {
+			continue
+		}
+		matched[rule.Section] = true
+		matches = append(matches, rule)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil
+	case 1:
+		return matches[0].Owners
+	}
+
+	var owners []string
+	seen := make(map[string]bool)
+	for i := len(matches) - 1; i >= 0; i-- {
+		for _, owner := range matches[i].Owners {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// patternToRegexp converts a gitignore-style CODEOWNERS pattern to a regular
+// expression. Patterns containing a non-trailing "/" are anchored to the
+// repository root, a trailing "/" only matches directories, and a match on a
+// directory covers everything inside it.
+func patternToRegexp(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var builder strings.Builder
+	if anchored {
+		builder.WriteString("^")
+	} else {
+		builder.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				builder.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				builder.WriteString(".*")
+				i++
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		builder.WriteString("/.*$")
+	} else {
+		builder.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(builder.String())
+}
@@ -0,0 +1,121 @@
+package ownership
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCodeowners = `# Default owners
+*                 @org/core
+
+# Docs are owned by the docs team
+docs/             @org/docs   # trailing comment
+*.md              @writer
+/cmd/             @cli-owner @org/core
+internal/**/ai/   @ml-owner
+/vendor/
+`
+
+func TestCodeownersOwners(t *testing.T) {
+	codeowners, err := ParseCodeowners(strings.NewReader(testCodeowners))
+	if err != nil {
+		t.Fatalf("ParseCodeowners() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{
+			name: "Catch-all rule",
+			path: "main.go",
+			want: []string{"@org/core"},
+		},
+		{
+			name: "Unanchored directory at any depth",
+			path: "web/docs/guide.html",
+			want: []string{"@org/docs"},
+		},
+		{
+			name: "Later rule wins",
+			path: "docs/README.md",
+			want: []string{"@writer"},
+		},
+		{
+			name: "Anchored directory",
+			path: "cmd/root.go",
+			want: []string{"@cli-owner", "@org/core"},
+		},
+		{
+			name: "Anchored directory does not match nested",
+			path: "tools/cmd/root.go",
+			want: []string{"@org/core"},
+		},
+		{
+			name: "Double star",
+			path: "internal/x/y/ai/claude.go",
+			want: []string{"@ml-owner"},
+		},
+		{
+			name: "Rule without owners clears ownership",
+			path: "vendor/lib/lib.go",
+			want: []string{},
+		},
+		{
+			name: "Directory pattern does not match a file",
+			path: "docs",
+			want: []string{"@org/core"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := codeowners.Owners(tt.path)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeownersSections(t *testing.T) {
+	const sections = `* @org/core
+
+[Docs] @org/docs
+*.md
+/docs/internal/ @internal-writer
+
+^[Backend][2] @org/backend
+/internal/
+/internal/ai/ @ml-owner
+`
+	codeowners, err := ParseCodeowners(strings.NewReader(sections))
+	if err != nil {
+		t.Fatalf("ParseCodeowners() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "Only the default section matches", path: "main.go", want: []string{"@org/core"}},
+		{name: "Section default owners", path: "README.md", want: []string{"@org/core", "@org/docs"}},
+		{name: "Last match wins within a section", path: "docs/internal/setup.md", want: []string{"@org/core", "@internal-writer"}},
+		{name: "Sections combine", path: "internal/ai/README.md", want: []string{"@org/core", "@org/docs", "@ml-owner"}},
+		{name: "Optional section with approvals", path: "internal/git/diff.go", want: []string{"@org/core", "@org/backend"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := codeowners.Owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
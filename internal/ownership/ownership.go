@@ -0,0 +1,194 @@
+// Package ownership recommends reviewers from who owns or recently touched
+// the changed files, using CODEOWNERS with git history as a fallback
+package ownership
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"auto-pr/internal/git"
+	"auto-pr/pkg/types"
+)
+
+// DefaultCodeownersPaths are the locations GitHub and GitLab look for a
+// CODEOWNERS file, in order
+var DefaultCodeownersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// historyAuthorLimit is how many recent authors git history contributes
+const historyAuthorLimit = 5
+
+// Suggester recommends reviewers for a set of file changes
+type Suggester struct {
+	repoPath       string
+	codeownersPath string
+}
+
+// NewSuggester creates a suggester for the repository. codeownersPath is
+// relative to the repository root; when empty the default locations are used.
+func NewSuggester(repoPath, codeownersPath string) *Suggester {
+	return &Suggester{
+		repoPath:       repoPath,
+		codeownersPath: codeownersPath,
+	}
+}
+
+// SuggestReviewers returns the owners of the changed files, most files owned
+// first. Without a CODEOWNERS file, or when it names no owner for the
+// changes, it falls back to the recent authors of the changed files.
+func (s *Suggester) SuggestReviewers(changes []types.FileChange) []string {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if codeowners := s.loadCodeowners(); codeowners != nil {
+		if reviewers := ownersByFileCount(codeowners, changes); len(reviewers) > 0 {
+			return reviewers
+		}
+	}
+
+	return s.recentAuthors(changes)
+}
+
+// CodeOwners returns the owners CODEOWNERS assigns to the changed files,
+// most files owned first, without falling back to git history. It is empty
+// when there is no CODEOWNERS file or it names no owner for the changes.
+func (s *Suggester) CodeOwners(changes []types.FileChange) []string {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	codeowners := s.loadCodeowners()
+	if codeowners == nil {
+		return nil
+	}
+	return ownersByFileCount(codeowners, changes)
+}
+
+// loadCodeowners reads the configured CODEOWNERS file, or the first one found
+// in the default locations
+func (s *Suggester) loadCodeowners() *Codeowners {
+	paths := DefaultCodeownersPaths
+	if s.codeownersPath != "" {
+		paths = []string{s.codeownersPath}
+	}
+
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.repoPath, path)
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		codeowners, err := ParseCodeowners(file)
+		file.Close()
+		if err == nil {
+			return codeowners
+		}
+	}
+
+	return nil
+}
+
+// ownersByFileCount collects the owners of the changed files, ordered by how
+// many of the files they own and then by name
+func ownersByFileCount(codeowners *Codeowners, changes []types.FileChange) []string {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		for _, owner := range codeowners.Owners(change.Path) {
+			if handle, ok := ownerHandle(owner); ok {
+				counts[handle]++
+			}
+		}
+	}
+
+	owners := make([]string, 0, len(counts))
+	for owner := range counts {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if counts[owners[i]] != counts[owners[j]] {
+			return counts[owners[i]] > counts[owners[j]]
+		}
+		return owners[i] < owners[j]
+	})
+
+	return owners
+}
+
+// ownerHandle strips the "@" from user and team owners so they can be passed
+// straight to gh/glab. Email owners can't be requested as reviewers by the
+// CLIs, so they are left out.
+func ownerHandle(owner string) (string, bool) {
+	if !strings.HasPrefix(owner, "@") {
+		return "", false
+	}
+	handle := strings.TrimPrefix(owner, "@")
+	return handle, handle != ""
+}
+
+// recentAuthors returns the platform handles of recent authors of the changed
+// files, excluding the current git user. Only authors committing with a
+// GitHub or GitLab noreply address have a known handle; others are skipped.
+func (s *Suggester) recentAuthors(changes []types.FileChange) []string {
+	analyzer, err := git.NewAnalyzer(s.repoPath)
+	if err != nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+
+	authors, err := analyzer.GetRecentAuthors(paths, historyAuthorLimit)
+	if err != nil {
+		return nil
+	}
+
+	var reviewers []string
+	for _, author := range authors {
+		// Authors come back as "Name <email>"
+		start := strings.LastIndex(author, "<")
+		end := strings.LastIndex(author, ">")
+		if start == -1 || end <= start+1 {
+			continue
+		}
+		if handle, ok := handleFromEmail(author[start+1 : end]); ok {
+			reviewers = append(reviewers, handle)
+		}
+	}
+	return reviewers
+}
+
+// handleFromEmail extracts the username from GitHub ("123+user@users.noreply.github.com")
+// and GitLab ("123-user@users.noreply.gitlab.com") noreply addresses
+func handleFromEmail(email string) (string, bool) {
+	local, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok {
+		return "", false
+	}
+
+	switch domain {
+	case "users.noreply.github.com":
+		if _, handle, found := strings.Cut(local, "+"); found {
+			local = handle
+		}
+	case "users.noreply.gitlab.com":
+		if _, handle, found := strings.Cut(local, "-"); found {
+			local = handle
+		}
+	default:
+		return "", false
+	}
+
+	return local, local != ""
+}
@@ -0,0 +1,62 @@
+package ownership
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestSuggestReviewers(t *testing.T) {
+	dir := t.TempDir()
+	codeowners := "*        @org/core\n/cmd/    @cli-owner @org/core\n*.yml    ops@example.com\n"
+	if err := os.WriteFile(filepath.Join(dir, "OWNERS"), []byte(codeowners), 0644); err != nil {
+		t.Fatalf("failed to write CODEOWNERS: %v", err)
+	}
+
+	suggester := NewSuggester(dir, "OWNERS")
+	changes := []types.FileChange{
+		{Path: "cmd/root.go"},
+		{Path: "cmd/create.go"},
+		{Path: "main.go"},
+		{Path: "deploy.yml"},
+	}
+
+	want := []string{"org/core", "cli-owner"}
+	if got := suggester.SuggestReviewers(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestReviewers() = %v, want %v", got, want)
+	}
+	if got := suggester.CodeOwners(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("CodeOwners() = %v, want %v", got, want)
+	}
+
+	// Without a CODEOWNERS file there are no owners, rather than recent authors
+	if got := NewSuggester(dir, "MISSING").CodeOwners(changes); len(got) != 0 {
+		t.Errorf("CodeOwners() without CODEOWNERS = %v, want none", got)
+	}
+}
+
+func TestHandleFromEmail(t *testing.T) {
+	tests := []struct {
+		email  string
+		want   string
+		wantOK bool
+	}{
+		{email: "12345+octocat@users.noreply.github.com", want: "octocat", wantOK: true},
+		{email: "octocat@users.noreply.github.com", want: "octocat", wantOK: true},
+		{email: "678-tanuki@users.noreply.gitlab.com", want: "tanuki", wantOK: true},
+		{email: "dev@example.com", wantOK: false},
+		{email: "not-an-email", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, ok := handleFromEmail(tt.email)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("handleFromEmail(%q) = (%q, %v), want (%q, %v)", tt.email, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
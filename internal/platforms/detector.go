@@ -60,6 +60,15 @@ func cleanRemoteURL(remoteURL string) string {
 	return remoteURL
 }
 
+// ExtractHost extracts the hostname from a remote URL
+func ExtractHost(remoteURL string) string {
+	parsedURL, err := url.Parse(cleanRemoteURL(remoteURL))
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Hostname()
+}
+
 // ExtractRepoInfo extracts owner and repository name from a remote URL
 func ExtractRepoInfo(remoteURL string) (owner, repo string, err error) {
 	cleanURL := cleanRemoteURL(remoteURL)
@@ -0,0 +1,135 @@
+package platforms
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors a platform CLI failure is classified as, so callers can react to
+// the cause instead of parsing messages
+var (
+	ErrNotAuthenticated = errors.New("not authenticated")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+
+	ErrPRAlreadyExists = errors.New("a PR/MR already exists for this branch")
+	ErrBranchNotPushed = errors.New("the branch has not been pushed")
+	ErrNoCommits       = errors.New("no commits between the base and head branches")
+)
+
+// defaultRateLimitWait is how long to back off when the CLI doesn't say;
+// GitHub asks for at least a minute after a secondary rate limit
+const defaultRateLimitWait = time.Minute
+
+// RateLimitError reports that the platform API refused a request for
+// exceeding a rate limit. It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	RetryAfter time.Duration // Suggested wait before trying again
+	Message    string        // What the CLI reported
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry in %s: %s", e.RetryAfter, e.Message)
+}
+
+// Is makes errors.Is(err, ErrRateLimited) true for any RateLimitError
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+var (
+	rateLimitPattern  = regexp.MustCompile(`(?i)rate limit|too many requests|\b429\b|abuse detection`)
+	retryAfterPattern = regexp.MustCompile(`(?i)(?:retry[- ]after:?|try again in|wait)\s+(\d+)\s*(s|sec|seconds?|m|min|minutes?)?\b`)
+	authPattern       = regexp.MustCompile(`(?i)\b401\b|unauthorized|authentication (?:required|failed)|not logged in|bad credentials|(?:gh|glab) auth login`)
+	notFoundPattern   = regexp.MustCompile(`(?i)\b404\b|not found|could not resolve to a`)
+	conflictPattern   = regexp.MustCompile(`(?i)\b409\b|conflict|already exists`)
+
+	alreadyExistsPattern = regexp.MustCompile(`(?i)(?:pull|merge) request .*already exists|already exists for this source branch`)
+	notPushedPattern     = regexp.MustCompile(`(?i)must first push|head sha can't be blank|head ref must be a branch|source branch .*(?:does not exist|not found)`)
+	noCommitsPattern     = regexp.MustCompile(`(?i)no commits between`)
+)
+
+// classifyError maps the stderr and exit code of a failed gh or glab command
+// to a typed error, or returns nil when the failure isn't recognized
+func classifyError(stderr string, exitCode int) error {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" || exitCode == 0 {
+		return nil
+	}
+	message := firstLine(stderr)
+
+	// The specific causes of a failed create come before the generic statuses
+	// their messages also contain
+	switch {
+	case alreadyExistsPattern.MatchString(stderr):
+		return fmt.Errorf("%w: %s", ErrPRAlreadyExists, message)
+	case notPushedPattern.MatchString(stderr):
+		return fmt.Errorf("%w: %s", ErrBranchNotPushed, message)
+	case noCommitsPattern.MatchString(stderr):
+		return fmt.Errorf("%w: %s", ErrNoCommits, message)
+	case rateLimitPattern.MatchString(stderr):
+		return &RateLimitError{RetryAfter: retryAfter(stderr), Message: message}
+	case authPattern.MatchString(stderr):
+		return fmt.Errorf("%w: %s", ErrNotAuthenticated, message)
+	case notFoundPattern.MatchString(stderr):
+		return fmt.Errorf("%w: %s", ErrNotFound, message)
+	case conflictPattern.MatchString(stderr):
+		return fmt.Errorf("%w: %s", ErrConflict, message)
+	}
+	return nil
+}
+
+// retryAfter returns the wait a rate limit message asks for, or the default
+func retryAfter(stderr string) time.Duration {
+	match := retryAfterPattern.FindStringSubmatch(stderr)
+	if match == nil {
+		return defaultRateLimitWait
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n <= 0 {
+		return defaultRateLimitWait
+	}
+	if strings.HasPrefix(strings.ToLower(match[2]), "m") {
+		return time.Duration(n) * time.Minute
+	}
+	return time.Duration(n) * time.Second
+}
+
+// cliError describes a failed platform CLI command: classified from what it
+// printed when the cause is recognized, and otherwise with the CLI's own
+// message attached. output is the combined output of commands run with
+// CombinedOutput, whose exit error carries no stderr; pass nil otherwise.
+func cliError(action string, err error, output []byte) error {
+	stderr := strings.TrimSpace(string(output))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if len(exitErr.Stderr) > 0 {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		if classified := classifyError(stderr, exitErr.ExitCode()); classified != nil {
+			return fmt.Errorf("%s: %w", action, classified)
+		}
+	}
+
+	if stderr == "" {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	return fmt.Errorf("%s: %w\n%s", action, err, stderr)
+}
+
+// firstLine returns the first non-empty line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
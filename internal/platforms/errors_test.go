@@ -0,0 +1,87 @@
+package platforms
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		stderr    string
+		exitCode  int
+		want      error
+		wantRetry time.Duration
+	}{
+		{
+			name:      "GitHub secondary rate limit",
+			stderr:    "HTTP 403: You have exceeded a secondary rate limit. Please wait a few minutes before you try again.",
+			exitCode:  1,
+			want:      ErrRateLimited,
+			wantRetry: time.Minute,
+		},
+		{
+			name:      "Rate limit with retry hint",
+			stderr:    "429 Too Many Requests\nRetry-After: 30",
+			exitCode:  1,
+			want:      ErrRateLimited,
+			wantRetry: 30 * time.Second,
+		},
+		{
+			name:      "Rate limit with wait in minutes",
+			stderr:    "API rate limit exceeded, try again in 5 minutes",
+			exitCode:  1,
+			want:      ErrRateLimited,
+			wantRetry: 5 * time.Minute,
+		},
+		{name: "GitHub not logged in", stderr: "To get started with GitHub CLI, please run:  gh auth login", exitCode: 4, want: ErrNotAuthenticated},
+		{name: "GitLab unauthorized", stderr: "ERROR: 401 Unauthorized", exitCode: 1, want: ErrNotAuthenticated},
+		{name: "Missing repository", stderr: "GraphQL: Could not resolve to a Repository with the name 'o/r'.", exitCode: 1, want: ErrNotFound},
+		{name: "Conflict", stderr: "HTTP 409: Conflict", exitCode: 1, want: ErrConflict},
+		{name: "GitHub PR already exists", stderr: "a pull request for branch \"feature\" into branch \"main\" already exists:\nhttps://github.com/o/r/pull/1", exitCode: 1, want: ErrPRAlreadyExists},
+		{name: "GitLab MR already exists", stderr: "409 Conflict: Another open merge request already exists for this source branch: !12", exitCode: 1, want: ErrPRAlreadyExists},
+		{name: "Branch not pushed", stderr: "GraphQL: Head sha can't be blank, Base sha can't be blank, No commits between main and feature, Head ref must be a branch (createPullRequest)", exitCode: 1, want: ErrBranchNotPushed},
+		{name: "No commits between", stderr: "GraphQL: No commits between main and feature (createPullRequest)", exitCode: 1, want: ErrNoCommits},
+		{name: "Unrecognized", stderr: "something broke", exitCode: 1, want: nil},
+		{name: "No stderr", stderr: "", exitCode: 1, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.stderr, tt.exitCode)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("classifyError() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("classifyError() = %v, want %v", err, tt.want)
+			}
+
+			var rateLimit *RateLimitError
+			if errors.As(err, &rateLimit) && rateLimit.RetryAfter != tt.wantRetry {
+				t.Errorf("classifyError() RetryAfter = %v, want %v", rateLimit.RetryAfter, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestCLIErrorIncludesStderr(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	_, err := exec.Command("sh", "-c", "echo 'could not parse response' >&2; exit 1").Output()
+	got := cliError("failed to list labels", err, nil)
+	if want := "failed to list labels: exit status 1\ncould not parse response"; got.Error() != want {
+		t.Errorf("cliError() = %q, want %q", got, want)
+	}
+
+	output, err := exec.Command("sh", "-c", "echo 'HTTP 404: Not Found' >&2; exit 1").CombinedOutput()
+	if got := cliError("failed to create pull request", err, output); !errors.Is(got, ErrNotFound) {
+		t.Errorf("cliError() = %v, want %v", got, ErrNotFound)
+	}
+}
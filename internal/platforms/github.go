@@ -1,9 +1,14 @@
 package platforms
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"auto-pr/pkg/types"
@@ -15,6 +20,8 @@ type GitHubClient struct {
 	repoOwner string
 	repoName  string
 	repoURL   string
+	host      string
+	version   *cliVersion
 }
 
 // NewGitHubClient creates a new GitHub client
@@ -25,6 +32,12 @@ func NewGitHubClient(repoURL string) (*GitHubClient, error) {
 		return nil, fmt.Errorf("GitHub CLI (gh) not found in PATH: %w", err)
 	}
 
+	// Make sure gh is new enough for the JSON output we rely on
+	version, err := checkCLIVersion("gh", cliPath, minGitHubCLIVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract repo info
 	owner, repo, err := ExtractRepoInfo(repoURL)
 	if err != nil {
@@ -36,11 +49,22 @@ func NewGitHubClient(repoURL string) (*GitHubClient, error) {
 		repoOwner: owner,
 		repoName:  repo,
 		repoURL:   repoURL,
+		host:      ExtractHost(repoURL),
+		version:   version,
 	}
 
 	return client, nil
 }
 
+// repoSpec returns the repository as HOST/OWNER/REPO for --repo, so gh
+// targets the remote's host even when the user's default host differs
+func (g *GitHubClient) repoSpec() string {
+	if g.host == "" {
+		return g.repoOwner + "/" + g.repoName
+	}
+	return fmt.Sprintf("%s/%s/%s", g.host, g.repoOwner, g.repoName)
+}
+
 // DetectPlatform returns GitHub platform type
 func (g *GitHubClient) DetectPlatform(repoURL string) (types.PlatformType, error) {
 	return DetectPlatform(repoURL)
@@ -48,21 +72,30 @@ func (g *GitHubClient) DetectPlatform(repoURL string) (types.PlatformType, error
 
 // IsAuthenticated checks if user is authenticated with GitHub
 func (g *GitHubClient) IsAuthenticated() bool {
-	cmd := exec.Command(g.cliPath, "auth", "status")
+	cmd := exec.Command(g.cliPath, "auth", "status", "--hostname", g.host)
 	return cmd.Run() == nil
 }
 
+// Login runs the interactive `gh auth login` for the repository host
+func (g *GitHubClient) Login() error {
+	cmd := exec.Command(g.cliPath, "auth", "login", "--hostname", g.host)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // ValidateRepository checks if the repository is accessible
 func (g *GitHubClient) ValidateRepository() error {
 	if !g.IsAuthenticated() {
-		return fmt.Errorf("not authenticated with GitHub. Run: gh auth login")
+		return fmt.Errorf("not authenticated with GitHub on %s. Run: gh auth login --hostname %s", g.host, g.host)
 	}
 
 	// Check repository access
-	cmd := exec.Command(g.cliPath, "repo", "view", fmt.Sprintf("%s/%s", g.repoOwner, g.repoName), "--json", "name")
+	cmd := exec.Command(g.cliPath, "repo", "view", g.repoSpec(), "--json", "name")
 	output, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("cannot access repository %s/%s: %w", g.repoOwner, g.repoName, err)
+		return cliError(fmt.Sprintf("cannot access repository %s/%s", g.repoOwner, g.repoName), err, nil)
 	}
 
 	var repoInfo struct {
@@ -81,11 +114,18 @@ func (g *GitHubClient) CreatePullRequest(req *types.PullRequestRequest) (*types.
 		return nil, err
 	}
 
+	// Cross-repository PRs reference the fork as owner:branch
+	head := req.HeadBranch
+	if req.HeadRepo != "" {
+		head = strings.SplitN(req.HeadRepo, "/", 2)[0] + ":" + req.HeadBranch
+	}
+
 	args := []string{
 		"pr", "create",
+		"--repo", g.repoSpec(),
 		"--title", req.Title,
 		"--body", req.Body,
-		"--head", req.HeadBranch,
+		"--head", head,
 		"--base", req.BaseBranch,
 	}
 
@@ -118,7 +158,7 @@ func (g *GitHubClient) CreatePullRequest(req *types.PullRequestRequest) (*types.
 	cmd := exec.Command(g.cliPath, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pull request: %w\nOutput: %s\nArgs: %v", err, string(output), args)
+		return nil, cliError("failed to create pull request", err, output)
 	}
 
 	// Parse the PR URL from output
@@ -131,12 +171,13 @@ func (g *GitHubClient) CreatePullRequest(req *types.PullRequestRequest) (*types.
 // GetExistingPR finds existing PR for the given branch
 func (g *GitHubClient) GetExistingPR(branch string) (*types.PullRequest, error) {
 	cmd := exec.Command(g.cliPath, "pr", "list",
+		"--repo", g.repoSpec(),
 		"--head", branch,
-		"--json", "number,title,body,state,url,headRefName,baseRefName,author,labels,milestone,createdAt,updatedAt")
+		"--json", "number,title,body,state,isDraft,url,headRefName,baseRefName,author,reviewRequests,labels,milestone,createdAt,updatedAt")
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		return nil, cliError("failed to list pull requests", err, nil)
 	}
 
 	var prs []struct {
@@ -144,12 +185,17 @@ func (g *GitHubClient) GetExistingPR(branch string) (*types.PullRequest, error)
 		Title       string `json:"title"`
 		Body        string `json:"body"`
 		State       string `json:"state"`
+		IsDraft     bool   `json:"isDraft"`
 		URL         string `json:"url"`
 		HeadRefName string `json:"headRefName"`
 		BaseRefName string `json:"baseRefName"`
 		Author      struct {
 			Login string `json:"login"`
 		} `json:"author"`
+		ReviewRequests []struct {
+			Login string `json:"login"`
+			Slug  string `json:"slug"` // Set for team review requests
+		} `json:"reviewRequests"`
 		Labels []struct {
 			Name string `json:"name"`
 		} `json:"labels"`
@@ -176,16 +222,27 @@ func (g *GitHubClient) GetExistingPR(branch string) (*types.PullRequest, error)
 		labels[i] = label.Name
 	}
 
+	// Extract requested reviewers, users by login and teams by slug
+	reviewers := make([]string, len(pr.ReviewRequests))
+	for i, request := range pr.ReviewRequests {
+		reviewers[i] = request.Login
+		if reviewers[i] == "" {
+			reviewers[i] = request.Slug
+		}
+	}
+
 	return &types.PullRequest{
 		ID:         pr.Number,
 		Number:     pr.Number,
 		Title:      pr.Title,
 		Body:       pr.Body,
 		State:      mapGitHubState(pr.State),
+		Draft:      pr.IsDraft,
 		URL:        pr.URL,
 		HeadBranch: pr.HeadRefName,
 		BaseBranch: pr.BaseRefName,
 		Author:     pr.Author.Login,
+		Reviewers:  reviewers,
 		Labels:     labels,
 		Milestone:  pr.Milestone.Title,
 		CreatedAt:  pr.CreatedAt,
@@ -193,6 +250,85 @@ func (g *GitHubClient) GetExistingPR(branch string) (*types.PullRequest, error)
 	}, nil
 }
 
+// UpdatePullRequest updates the title and/or body of an existing pull request
+func (g *GitHubClient) UpdatePullRequest(number int, update *types.PullRequestUpdate) (*types.PullRequest, error) {
+	args := []string{"pr", "edit", strconv.Itoa(number),
+		"--repo", g.repoSpec()}
+
+	if update.Title != "" {
+		args = append(args, "--title", update.Title)
+	}
+	if update.Body != "" {
+		args = append(args, "--body", update.Body)
+	}
+
+	cmd := exec.Command(g.cliPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, cliError("failed to update pull request", err, output)
+	}
+
+	return g.getPRByNumber(strconv.Itoa(number))
+}
+
+// AddReviewers requests reviews from users or teams on an existing pull request
+func (g *GitHubClient) AddReviewers(number int, reviewers []string) error {
+	return g.editPR(number, "--add-reviewer", reviewers, "failed to add reviewers")
+}
+
+// AddLabels adds labels to an existing pull request
+func (g *GitHubClient) AddLabels(number int, labels []string) error {
+	return g.editPR(number, "--add-label", labels, "failed to add labels")
+}
+
+// AddComment posts a comment on an existing pull request
+func (g *GitHubClient) AddComment(number int, body string) error {
+	cmd := exec.Command(g.cliPath, "pr", "comment", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		"--body", body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return cliError("failed to comment on pull request", err, output)
+	}
+	return nil
+}
+
+// ClosePullRequest closes a pull request without merging it
+func (g *GitHubClient) ClosePullRequest(number int) error {
+	cmd := exec.Command(g.cliPath, "pr", "close", strconv.Itoa(number),
+		"--repo", g.repoSpec())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return cliError("failed to close pull request", err, output)
+	}
+	return nil
+}
+
+// RetargetPullRequest changes the base branch of an existing pull request
+func (g *GitHubClient) RetargetPullRequest(number int, base string) (*types.PullRequest, error) {
+	cmd := exec.Command(g.cliPath, "pr", "edit", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		"--base", base)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, cliError("failed to change the base branch of the pull request", err, output)
+	}
+
+	return g.getPRByNumber(strconv.Itoa(number))
+}
+
+// editPR runs gh pr edit with a flag taking a comma-separated list of values
+func (g *GitHubClient) editPR(number int, flag string, values []string, message string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command(g.cliPath, "pr", "edit", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		flag, strings.Join(values, ","))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return cliError(message, err, output)
+	}
+	return nil
+}
+
 // GetCLIPath returns the path to GitHub CLI
 func (g *GitHubClient) GetCLIPath() string {
 	return g.cliPath
@@ -200,13 +336,17 @@ func (g *GitHubClient) GetCLIPath() string {
 
 // ListLabels returns all label names defined in the repository.
 func (g *GitHubClient) ListLabels() ([]string, error) {
+	if g.version != nil && !g.version.AtLeast(ghLabelListJSONVersion) {
+		return nil, fmt.Errorf("listing labels requires gh >= %s (found %s)", ghLabelListJSONVersion, g.version)
+	}
+
 	cmd := exec.Command(g.cliPath, "label", "list",
-		"--repo", fmt.Sprintf("%s/%s", g.repoOwner, g.repoName),
+		"--repo", g.repoSpec(),
 		"--json", "name",
 		"--limit", "100")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list labels: %w", err)
+		return nil, cliError("failed to list labels", err, nil)
 	}
 
 	var items []struct {
@@ -223,6 +363,134 @@ func (g *GitHubClient) ListLabels() ([]string, error) {
 	return names, nil
 }
 
+// ListReviewers returns the logins of the repository's collaborators, who are
+// the users a review can be requested from
+func (g *GitHubClient) ListReviewers() ([]string, error) {
+	cmd := exec.Command(g.cliPath, "api", "--hostname", g.host,
+		fmt.Sprintf("repos/%s/%s/collaborators?per_page=100", g.repoOwner, g.repoName))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, cliError("failed to list collaborators", err, nil)
+	}
+
+	var items []struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse collaborator list: %w", err)
+	}
+
+	logins := make([]string, len(items))
+	for i, item := range items {
+		logins[i] = item.Login
+	}
+	return logins, nil
+}
+
+// GetIssue returns the repository's issue with the given number
+func (g *GitHubClient) GetIssue(number int) (*types.Issue, error) {
+	cmd := exec.Command(g.cliPath, "issue", "view", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		"--json", "number,title,body,state,url,labels")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, cliError("failed to get issue", err, nil)
+	}
+	return parseGitHubIssue(output)
+}
+
+// parseGitHubIssue decodes the JSON gh issue view prints
+func parseGitHubIssue(output []byte) (*types.Issue, error) {
+	var issue struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		State  string `json:"state"`
+		URL    string `json:"url"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	}
+
+	labels := make([]string, len(issue.Labels))
+	for i, label := range issue.Labels {
+		labels[i] = label.Name
+	}
+
+	return &types.Issue{
+		Number: issue.Number,
+		Title:  issue.Title,
+		Body:   issue.Body,
+		State:  strings.ToLower(issue.State),
+		URL:    issue.URL,
+		Labels: labels,
+	}, nil
+}
+
+// CheckRun represents a single check run on a commit
+type CheckRun struct {
+	Name       string
+	Status     string
+	Conclusion string
+	URL        string
+}
+
+// IsFailing reports whether the check run completed unsuccessfully
+func (c CheckRun) IsFailing() bool {
+	switch c.Conclusion {
+	case "failure", "timed_out", "action_required", "startup_failure":
+		return true
+	default:
+		return false
+	}
+}
+
+// GetChecksStatus returns the check runs for the head commit of the given
+// branch in headRepo (owner/name), the repository holding the branch such as
+// a fork; an empty headRepo means the client's own repository
+func (g *GitHubClient) GetChecksStatus(headRepo, branch string) ([]CheckRun, error) {
+	if headRepo == "" {
+		headRepo = g.repoOwner + "/" + g.repoName
+	}
+	// --paginate follows every page, printing one JSON object per page
+	cmd := exec.Command(g.cliPath, "api", "--hostname", g.host, "--paginate",
+		fmt.Sprintf("repos/%s/commits/%s/check-runs?per_page=100", headRepo, url.PathEscape(branch)))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, cliError("failed to get check runs", err, nil)
+	}
+
+	var checks []CheckRun
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var page struct {
+			CheckRuns []struct {
+				Name       string `json:"name"`
+				Status     string `json:"status"`
+				Conclusion string `json:"conclusion"`
+				HTMLURL    string `json:"html_url"`
+			} `json:"check_runs"`
+		}
+		if err := decoder.Decode(&page); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse check runs: %w", err)
+		}
+		for _, run := range page.CheckRuns {
+			checks = append(checks, CheckRun{
+				Name:       run.Name,
+				Status:     run.Status,
+				Conclusion: run.Conclusion,
+				URL:        run.HTMLURL,
+			})
+		}
+	}
+	return checks, nil
+}
+
 // getPRDetails gets detailed information about a PR from its URL
 func (g *GitHubClient) getPRDetails(prURL string) (*types.PullRequest, error) {
 	// Extract PR number from URL
@@ -230,14 +498,18 @@ func (g *GitHubClient) getPRDetails(prURL string) (*types.PullRequest, error) {
 	if len(parts) < 2 {
 		return nil, fmt.Errorf("invalid PR URL: %s", prURL)
 	}
-	prNumber := parts[len(parts)-1]
+	return g.getPRByNumber(parts[len(parts)-1])
+}
 
+// getPRByNumber gets detailed information about a PR from its number
+func (g *GitHubClient) getPRByNumber(prNumber string) (*types.PullRequest, error) {
 	cmd := exec.Command(g.cliPath, "pr", "view", prNumber,
+		"--repo", g.repoSpec(),
 		"--json", "number,title,body,state,url,headRefName,baseRefName,author,labels,milestone,createdAt,updatedAt,isDraft")
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get PR details: %w", err)
+		return nil, cliError("failed to get PR details", err, nil)
 	}
 
 	var pr struct {
@@ -0,0 +1,140 @@
+package platforms
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckRunIsFailing(t *testing.T) {
+	tests := []struct {
+		name       string
+		conclusion string
+		want       bool
+	}{
+		{name: "Success", conclusion: "success", want: false},
+		{name: "Skipped", conclusion: "skipped", want: false},
+		{name: "Still running", conclusion: "", want: false},
+		{name: "Failure", conclusion: "failure", want: true},
+		{name: "Timed out", conclusion: "timed_out", want: true},
+		{name: "Action required", conclusion: "action_required", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := CheckRun{Name: "build", Conclusion: tt.conclusion}
+			if got := check.IsFailing(); got != tt.want {
+				t.Errorf("IsFailing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubRepoSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "GitHub",
+			got:  (&GitHubClient{host: "github.com", repoOwner: "owner", repoName: "repo"}).repoSpec(),
+			want: "github.com/owner/repo",
+		},
+		{
+			name: "GitHub Enterprise",
+			got:  (&GitHubClient{host: "github.example.com", repoOwner: "owner", repoName: "repo"}).repoSpec(),
+			want: "github.example.com/owner/repo",
+		},
+		{
+			name: "GitHub without host",
+			got:  (&GitHubClient{repoOwner: "owner", repoName: "repo"}).repoSpec(),
+			want: "owner/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("repoSpec() = %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGitHubIssue(t *testing.T) {
+	output := []byte(`{"number":42,"title":"Export reports as CSV","body":"- [ ] Adds an export button","state":"OPEN",` +
+		`"url":"https://github.com/acme/widgets/issues/42","labels":[{"name":"feature"},{"name":"reports"}]}`)
+
+	issue, err := parseGitHubIssue(output)
+	if err != nil {
+		t.Fatalf("parseGitHubIssue() error = %v", err)
+	}
+	if issue.Number != 42 || issue.Title != "Export reports as CSV" || issue.Body != "- [ ] Adds an export button" {
+		t.Errorf("parseGitHubIssue() = %+v", issue)
+	}
+	if issue.State != "open" {
+		t.Errorf("State = %q, want open", issue.State)
+	}
+	if issue.URL != "https://github.com/acme/widgets/issues/42" {
+		t.Errorf("URL = %q", issue.URL)
+	}
+	if len(issue.Labels) != 2 || issue.Labels[0] != "feature" || issue.Labels[1] != "reports" {
+		t.Errorf("Labels = %v, want [feature reports]", issue.Labels)
+	}
+
+	if _, err := parseGitHubIssue([]byte("not json")); err == nil {
+		t.Error("parseGitHubIssue() accepted invalid JSON")
+	}
+}
+
+func TestGetChecksStatus(t *testing.T) {
+	// The fake gh records its arguments and prints two pages as --paginate does
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := `#!/bin/sh
+printf '%s\n' "$@" > "` + argsFile + `"
+printf '{"total_count":3,"check_runs":[{"name":"build","conclusion":"success"},{"name":"lint","conclusion":"failure"}]}'
+printf '{"total_count":3,"check_runs":[{"name":"test","status":"in_progress"}]}'
+`
+	cliPath := filepath.Join(dir, "gh")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := &GitHubClient{cliPath: cliPath, host: "github.com", repoOwner: "acme", repoName: "widgets"}
+
+	tests := []struct {
+		name     string
+		headRepo string
+		want     string
+	}{
+		{name: "Own repository", want: "repos/acme/widgets/commits/feature%2Fexport/check-runs?per_page=100"},
+		{name: "Fork", headRepo: "octocat/widgets", want: "repos/octocat/widgets/commits/feature%2Fexport/check-runs?per_page=100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := client.GetChecksStatus(tt.headRepo, "feature/export")
+			if err != nil {
+				t.Fatalf("GetChecksStatus() error = %v", err)
+			}
+			if len(checks) != 3 || !checks[1].IsFailing() || checks[2].Name != "test" {
+				t.Errorf("GetChecksStatus() = %+v, want the check runs from both pages", checks)
+			}
+
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+			if !slices.Contains(lines, "--paginate") {
+				t.Errorf("gh args = %q, want --paginate", lines)
+			}
+			if got := lines[len(lines)-1]; got != tt.want {
+				t.Errorf("gh endpoint = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
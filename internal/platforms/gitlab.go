@@ -3,7 +3,10 @@ package platforms
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"auto-pr/pkg/types"
@@ -15,6 +18,7 @@ type GitLabClient struct {
 	projectID string
 	baseURL   string
 	repoURL   string
+	host      string
 }
 
 // NewGitLabClient creates a new GitLab client
@@ -25,6 +29,11 @@ func NewGitLabClient(repoURL string) (*GitLabClient, error) {
 		return nil, fmt.Errorf("GitLab CLI (glab) not found in PATH: %w", err)
 	}
 
+	// Make sure glab is new enough for the JSON output we rely on
+	if _, err := checkCLIVersion("glab", cliPath, minGitLabCLIVersion); err != nil {
+		return nil, err
+	}
+
 	// Extract project info
 	owner, repo, err := ExtractRepoInfo(repoURL)
 	if err != nil {
@@ -33,25 +42,38 @@ func NewGitLabClient(repoURL string) (*GitLabClient, error) {
 
 	projectID := fmt.Sprintf("%s/%s", owner, repo)
 
-	// Determine base URL
-	baseURL := "https://gitlab.com"
-	if strings.Contains(repoURL, "://") {
-		parts := strings.Split(strings.Split(repoURL, "://")[1], "/")
-		if len(parts) > 0 && !strings.Contains(parts[0], "gitlab.com") {
-			baseURL = "https://" + parts[0]
-		}
+	// The instance comes from the remote, for SSH and HTTPS remotes alike
+	host := ExtractHost(repoURL)
+	if host == "" {
+		host = "gitlab.com"
 	}
 
 	client := &GitLabClient{
 		cliPath:   cliPath,
 		projectID: projectID,
-		baseURL:   baseURL,
+		baseURL:   "https://" + host,
 		repoURL:   repoURL,
+		host:      host,
 	}
 
 	return client, nil
 }
 
+// repoSpec returns the project as HOST/GROUP/PROJECT for --repo, so glab
+// targets the remote's instance
+func (g *GitLabClient) repoSpec() string {
+	return g.host + "/" + g.projectID
+}
+
+// command prepares a glab command against the remote's instance. GITLAB_HOST
+// is set as well because commands without --repo otherwise use the user's
+// default host.
+func (g *GitLabClient) command(args ...string) *exec.Cmd {
+	cmd := exec.Command(g.cliPath, args...)
+	cmd.Env = append(os.Environ(), "GITLAB_HOST="+g.baseURL)
+	return cmd
+}
+
 // DetectPlatform returns GitLab platform type
 func (g *GitLabClient) DetectPlatform(repoURL string) (types.PlatformType, error) {
 	return DetectPlatform(repoURL)
@@ -59,21 +81,30 @@ func (g *GitLabClient) DetectPlatform(repoURL string) (types.PlatformType, error
 
 // IsAuthenticated checks if user is authenticated with GitLab
 func (g *GitLabClient) IsAuthenticated() bool {
-	cmd := exec.Command(g.cliPath, "auth", "status")
+	cmd := g.command("auth", "status", "--hostname", g.host)
 	return cmd.Run() == nil
 }
 
+// Login runs the interactive `glab auth login` for the repository host
+func (g *GitLabClient) Login() error {
+	cmd := g.command("auth", "login", "--hostname", g.host)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // ValidateRepository checks if the repository is accessible
 func (g *GitLabClient) ValidateRepository() error {
 	if !g.IsAuthenticated() {
-		return fmt.Errorf("not authenticated with GitLab. Run: glab auth login")
+		return fmt.Errorf("not authenticated with GitLab on %s. Run: glab auth login --hostname %s", g.host, g.host)
 	}
 
 	// Check repository access
-	cmd := exec.Command(g.cliPath, "repo", "view", g.projectID, "--json")
+	cmd := g.command("repo", "view", g.repoSpec(), "--json")
 	_, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("cannot access repository %s: %w", g.projectID, err)
+		return cliError("cannot access repository "+g.projectID, err, nil)
 	}
 
 	return nil
@@ -87,12 +118,18 @@ func (g *GitLabClient) CreatePullRequest(req *types.PullRequestRequest) (*types.
 
 	args := []string{
 		"mr", "create",
+		"--repo", g.repoSpec(),
 		"--title", req.Title,
 		"--description", req.Body,
 		"--source-branch", req.HeadBranch,
 		"--target-branch", req.BaseBranch,
 	}
 
+	// Cross-project MRs name the fork holding the source branch
+	if req.HeadRepo != "" {
+		args = append(args, "--head", req.HeadRepo)
+	}
+
 	// Add draft flag
 	if req.Draft {
 		args = append(args, "--draft")
@@ -114,14 +151,15 @@ func (g *GitLabClient) CreatePullRequest(req *types.PullRequestRequest) (*types.
 	}
 
 	// Execute command
-	cmd := exec.Command(g.cliPath, args...)
-	output, err := cmd.Output()
+	cmd := g.command(args...)
+	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create merge request: %w", err)
+		return nil, cliError("failed to create merge request", err, output)
 	}
 
-	// Parse the MR URL from output
-	mrURL := strings.TrimSpace(string(output))
+	// Parse the MR URL from output, which ends with it after any progress lines
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	mrURL := strings.TrimSpace(lines[len(lines)-1])
 
 	// Get detailed MR information
 	return g.getMRDetails(mrURL)
@@ -129,13 +167,14 @@ func (g *GitLabClient) CreatePullRequest(req *types.PullRequestRequest) (*types.
 
 // GetExistingPR finds existing MR for the given branch
 func (g *GitLabClient) GetExistingPR(branch string) (*types.PullRequest, error) {
-	cmd := exec.Command(g.cliPath, "mr", "list",
+	cmd := g.command("mr", "list",
+		"--repo", g.repoSpec(),
 		"--source-branch", branch,
 		"--json")
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+		return nil, cliError("failed to list merge requests", err, nil)
 	}
 
 	var mrs []struct {
@@ -149,6 +188,9 @@ func (g *GitLabClient) GetExistingPR(branch string) (*types.PullRequest, error)
 		Author       struct {
 			Username string `json:"username"`
 		} `json:"author"`
+		Assignees []struct {
+			Username string `json:"username"`
+		} `json:"assignees"`
 		Labels    []string `json:"labels"`
 		Milestone struct {
 			Title string `json:"title"`
@@ -168,6 +210,12 @@ func (g *GitLabClient) GetExistingPR(branch string) (*types.PullRequest, error)
 
 	mr := mrs[0] // Get the first (most recent) MR
 
+	// Reviewers are assigned on GitLab, as in CreatePullRequest
+	reviewers := make([]string, len(mr.Assignees))
+	for i, assignee := range mr.Assignees {
+		reviewers[i] = assignee.Username
+	}
+
 	return &types.PullRequest{
 		ID:         mr.IID,
 		Number:     mr.IID,
@@ -179,6 +227,7 @@ func (g *GitLabClient) GetExistingPR(branch string) (*types.PullRequest, error)
 		HeadBranch: mr.SourceBranch,
 		BaseBranch: mr.TargetBranch,
 		Author:     mr.Author.Username,
+		Reviewers:  reviewers,
 		Labels:     mr.Labels,
 		Milestone:  mr.Milestone.Title,
 		CreatedAt:  mr.CreatedAt,
@@ -186,6 +235,89 @@ func (g *GitLabClient) GetExistingPR(branch string) (*types.PullRequest, error)
 	}, nil
 }
 
+// UpdatePullRequest updates the title and/or description of an existing merge request
+func (g *GitLabClient) UpdatePullRequest(number int, update *types.PullRequestUpdate) (*types.PullRequest, error) {
+	args := []string{"mr", "update", strconv.Itoa(number), "--repo", g.repoSpec()}
+
+	if update.Title != "" {
+		args = append(args, "--title", update.Title)
+	}
+	if update.Body != "" {
+		args = append(args, "--description", update.Body)
+	}
+
+	cmd := g.command(args...)
+	if _, err := cmd.Output(); err != nil {
+		return nil, cliError("failed to update merge request", err, nil)
+	}
+
+	return g.getMRByIID(strconv.Itoa(number))
+}
+
+// AddReviewers assigns users to an existing merge request, keeping the
+// current assignees (GitLab uses assignees instead of reviewers)
+func (g *GitLabClient) AddReviewers(number int, reviewers []string) error {
+	// A + prefix adds to the assignees instead of replacing them
+	added := make([]string, len(reviewers))
+	for i, reviewer := range reviewers {
+		added[i] = "+" + reviewer
+	}
+	return g.updateMR(number, "--assignee", added, "failed to add assignees")
+}
+
+// AddLabels adds labels to an existing merge request
+func (g *GitLabClient) AddLabels(number int, labels []string) error {
+	return g.updateMR(number, "--label", labels, "failed to add labels")
+}
+
+// AddComment posts a note on an existing merge request
+func (g *GitLabClient) AddComment(number int, body string) error {
+	cmd := g.command("mr", "note", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		"--message", body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return cliError("failed to comment on merge request", err, output)
+	}
+	return nil
+}
+
+// ClosePullRequest closes a merge request without merging it
+func (g *GitLabClient) ClosePullRequest(number int) error {
+	cmd := g.command("mr", "close", strconv.Itoa(number),
+		"--repo", g.repoSpec())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return cliError("failed to close merge request", err, output)
+	}
+	return nil
+}
+
+// RetargetPullRequest changes the target branch of an existing merge request
+func (g *GitLabClient) RetargetPullRequest(number int, base string) (*types.PullRequest, error) {
+	cmd := g.command("mr", "update", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		"--target-branch", base)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, cliError("failed to change the target branch of the merge request", err, output)
+	}
+
+	return g.getMRByIID(strconv.Itoa(number))
+}
+
+// updateMR runs glab mr update with a flag taking a comma-separated list of values
+func (g *GitLabClient) updateMR(number int, flag string, values []string, message string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	cmd := g.command("mr", "update", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		flag, strings.Join(values, ","))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return cliError(message, err, output)
+	}
+	return nil
+}
+
 // GetCLIPath returns the path to GitLab CLI
 func (g *GitLabClient) GetCLIPath() string {
 	return g.cliPath
@@ -193,12 +325,12 @@ func (g *GitLabClient) GetCLIPath() string {
 
 // ListLabels returns all label names defined in the repository.
 func (g *GitLabClient) ListLabels() ([]string, error) {
-	cmd := exec.Command(g.cliPath, "label", "list",
-		"--repo", g.projectID,
+	cmd := g.command("label", "list",
+		"--repo", g.repoSpec(),
 		"--output", "json")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list labels: %w", err)
+		return nil, cliError("failed to list labels", err, nil)
 	}
 
 	var items []struct {
@@ -215,6 +347,115 @@ func (g *GitLabClient) ListLabels() ([]string, error) {
 	return names, nil
 }
 
+// ListReviewers returns the usernames of the project's members, including
+// those inherited from parent groups
+func (g *GitLabClient) ListReviewers() ([]string, error) {
+	cmd := g.command("api", "--hostname", g.host,
+		fmt.Sprintf("projects/%s/members/all?per_page=100", url.PathEscape(g.projectID)))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, cliError("failed to list project members", err, nil)
+	}
+
+	var items []struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse project member list: %w", err)
+	}
+
+	usernames := make([]string, len(items))
+	for i, item := range items {
+		usernames[i] = item.Username
+	}
+	return usernames, nil
+}
+
+// GetIssue returns the project's issue with the given IID
+func (g *GitLabClient) GetIssue(number int) (*types.Issue, error) {
+	cmd := g.command("issue", "view", strconv.Itoa(number),
+		"--repo", g.repoSpec(),
+		"--output", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, cliError("failed to get issue", err, nil)
+	}
+	return parseGitLabIssue(output)
+}
+
+// parseGitLabIssue decodes the JSON glab issue view prints
+func parseGitLabIssue(output []byte) (*types.Issue, error) {
+	var issue struct {
+		IID         int      `json:"iid"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		State       string   `json:"state"`
+		WebURL      string   `json:"web_url"`
+		Labels      []string `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	}
+
+	// GitLab says "opened" where GitHub says "open"
+	state := issue.State
+	if state == "opened" {
+		state = "open"
+	}
+
+	return &types.Issue{
+		Number: issue.IID,
+		Title:  issue.Title,
+		Body:   issue.Description,
+		State:  state,
+		URL:    issue.WebURL,
+		Labels: issue.Labels,
+	}, nil
+}
+
+// Pipeline represents the latest CI pipeline for a branch
+type Pipeline struct {
+	ID     int
+	Status string
+	URL    string
+}
+
+// GetPipeline returns the latest CI pipeline for the given branch
+func (g *GitLabClient) GetPipeline(branch string) (*Pipeline, error) {
+	cmd := g.command("ci", "get",
+		"--repo", g.repoSpec(),
+		"--branch", branch,
+		"--output", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, cliError("failed to get pipeline", err, nil)
+	}
+
+	var pipeline struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+		WebURL string `json:"web_url"`
+	}
+	if err := json.Unmarshal(output, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline: %w", err)
+	}
+
+	return &Pipeline{
+		ID:     pipeline.ID,
+		Status: strings.ToLower(pipeline.Status),
+		URL:    pipeline.WebURL,
+	}, nil
+}
+
+// GetPipelineStatus returns the status of the latest CI pipeline for the given branch
+func (g *GitLabClient) GetPipelineStatus(branch string) (string, error) {
+	pipeline, err := g.GetPipeline(branch)
+	if err != nil {
+		return "", err
+	}
+	return pipeline.Status, nil
+}
+
 // getMRDetails gets detailed information about an MR from its URL
 func (g *GitLabClient) getMRDetails(mrURL string) (*types.PullRequest, error) {
 	// Extract MR IID from URL
@@ -222,13 +463,16 @@ func (g *GitLabClient) getMRDetails(mrURL string) (*types.PullRequest, error) {
 	if len(parts) < 2 {
 		return nil, fmt.Errorf("invalid MR URL: %s", mrURL)
 	}
-	mrIID := parts[len(parts)-1]
+	return g.getMRByIID(parts[len(parts)-1])
+}
 
-	cmd := exec.Command(g.cliPath, "mr", "view", mrIID, "--json")
+// getMRByIID gets detailed information about an MR from its IID
+func (g *GitLabClient) getMRByIID(mrIID string) (*types.PullRequest, error) {
+	cmd := g.command("mr", "view", mrIID, "--repo", g.repoSpec(), "--json")
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get MR details: %w", err)
+		return nil, cliError("failed to get MR details", err, nil)
 	}
 
 	var mr struct {
@@ -0,0 +1,47 @@
+package platforms
+
+import "testing"
+
+func TestGitLabRepoSpec(t *testing.T) {
+	client := &GitLabClient{host: "gitlab.example.com", projectID: "group/project"}
+	if got, want := client.repoSpec(), "gitlab.example.com/group/project"; got != want {
+		t.Errorf("repoSpec() = %q, want %q", got, want)
+	}
+}
+
+func TestGitLabCommandSetsHost(t *testing.T) {
+	client := &GitLabClient{cliPath: "glab", baseURL: "https://gitlab.example.com", host: "gitlab.example.com"}
+
+	cmd := client.command("mr", "list")
+	found := false
+	for _, env := range cmd.Env {
+		if env == "GITLAB_HOST=https://gitlab.example.com" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("command() did not set GITLAB_HOST to the remote's instance")
+	}
+}
+
+func TestParseGitLabIssue(t *testing.T) {
+	output := []byte(`{"iid":7,"title":"Retry flaky uploads","description":"Uploads should retry twice","state":"opened",` +
+		`"web_url":"https://gitlab.com/group/project/-/issues/7","labels":["bug"]}`)
+
+	issue, err := parseGitLabIssue(output)
+	if err != nil {
+		t.Fatalf("parseGitLabIssue() error = %v", err)
+	}
+	if issue.Number != 7 || issue.Title != "Retry flaky uploads" || issue.Body != "Uploads should retry twice" {
+		t.Errorf("parseGitLabIssue() = %+v", issue)
+	}
+	if issue.State != "open" {
+		t.Errorf("State = %q, want open", issue.State)
+	}
+	if issue.URL != "https://gitlab.com/group/project/-/issues/7" {
+		t.Errorf("URL = %q", issue.URL)
+	}
+	if len(issue.Labels) != 1 || issue.Labels[0] != "bug" {
+		t.Errorf("Labels = %v, want [bug]", issue.Labels)
+	}
+}
@@ -10,12 +10,33 @@ type PlatformClient interface {
 	// IsAuthenticated checks if the user is authenticated with the platform
 	IsAuthenticated() bool
 
+	// Login runs the platform CLI's interactive login for the repository host
+	Login() error
+
 	// CreatePullRequest creates a new pull request or merge request
 	CreatePullRequest(req *types.PullRequestRequest) (*types.PullRequest, error)
 
 	// GetExistingPR finds an existing PR/MR for the given branch
 	GetExistingPR(branch string) (*types.PullRequest, error)
 
+	// UpdatePullRequest updates an existing pull request or merge request
+	UpdatePullRequest(number int, update *types.PullRequestUpdate) (*types.PullRequest, error)
+
+	// AddReviewers requests reviews on an existing PR/MR, keeping current reviewers
+	AddReviewers(number int, reviewers []string) error
+
+	// AddLabels adds labels to an existing PR/MR, keeping current labels
+	AddLabels(number int, labels []string) error
+
+	// AddComment posts a comment on an existing PR/MR
+	AddComment(number int, body string) error
+
+	// ClosePullRequest closes an open PR/MR without merging it
+	ClosePullRequest(number int) error
+
+	// RetargetPullRequest changes the base branch an existing PR/MR merges into
+	RetargetPullRequest(number int, base string) (*types.PullRequest, error)
+
 	// ValidateRepository checks if the repository is accessible and valid
 	ValidateRepository() error
 
@@ -24,6 +45,12 @@ type PlatformClient interface {
 
 	// ListLabels returns all label names defined in the repository
 	ListLabels() ([]string, error)
+
+	// ListReviewers returns the usernames that can be requested as reviewers
+	ListReviewers() ([]string, error)
+
+	// GetIssue returns the repository's issue with the given number
+	GetIssue(number int) (*types.Issue, error)
 }
 
 // FilterExistingLabels returns only those labels from candidates that exist in the repository.
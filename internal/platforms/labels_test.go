@@ -12,23 +12,38 @@ type stubClient struct {
 	err    error
 }
 
-func (s *stubClient) DetectPlatform(repoURL string) (types.PlatformType, error) { return types.PlatformGitHub, nil }
-func (s *stubClient) IsAuthenticated() bool                                      { return true }
+func (s *stubClient) DetectPlatform(repoURL string) (types.PlatformType, error) {
+	return types.PlatformGitHub, nil
+}
+func (s *stubClient) IsAuthenticated() bool { return true }
+func (s *stubClient) Login() error          { return nil }
 func (s *stubClient) CreatePullRequest(req *types.PullRequestRequest) (*types.PullRequest, error) {
 	return nil, nil
 }
 func (s *stubClient) GetExistingPR(branch string) (*types.PullRequest, error) { return nil, nil }
-func (s *stubClient) ValidateRepository() error                                { return nil }
-func (s *stubClient) GetCLIPath() string                                       { return "" }
-func (s *stubClient) ListLabels() ([]string, error)                            { return s.labels, s.err }
+func (s *stubClient) UpdatePullRequest(number int, update *types.PullRequestUpdate) (*types.PullRequest, error) {
+	return nil, nil
+}
+func (s *stubClient) AddReviewers(number int, reviewers []string) error { return nil }
+func (s *stubClient) AddLabels(number int, labels []string) error       { return nil }
+func (s *stubClient) ClosePullRequest(number int) error                 { return nil }
+func (s *stubClient) RetargetPullRequest(number int, base string) (*types.PullRequest, error) {
+	return nil, nil
+}
+func (s *stubClient) AddComment(number int, body string) error  { return nil }
+func (s *stubClient) ValidateRepository() error                 { return nil }
+func (s *stubClient) GetCLIPath() string                        { return "" }
+func (s *stubClient) ListLabels() ([]string, error)             { return s.labels, s.err }
+func (s *stubClient) ListReviewers() ([]string, error)          { return nil, nil }
+func (s *stubClient) GetIssue(number int) (*types.Issue, error) { return nil, nil }
 
 func TestFilterExistingLabels(t *testing.T) {
 	tests := []struct {
-		name           string
-		repoLabels     []string
-		candidates     []string
-		want           []string
-		wantErr        bool
+		name       string
+		repoLabels []string
+		candidates []string
+		want       []string
+		wantErr    bool
 	}{
 		{
 			name:       "keeps only labels that exist",
@@ -0,0 +1,81 @@
+package platforms
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+// cliVersion is a parsed major.minor.patch version of a platform CLI
+type cliVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var (
+	// minGitHubCLIVersion is the oldest gh release with the --json output used here
+	minGitHubCLIVersion = cliVersion{Major: 2, Minor: 0, Patch: 0}
+	// ghLabelListJSONVersion is the first gh release supporting `gh label list --json`
+	ghLabelListJSONVersion = cliVersion{Major: 2, Minor: 23, Patch: 0}
+	// minGitLabCLIVersion is the oldest glab release with the JSON output used here
+	minGitLabCLIVersion = cliVersion{Major: 1, Minor: 22, Patch: 0}
+)
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// String returns the version in major.minor.patch form
+func (v cliVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is the same as or newer than other
+func (v cliVersion) AtLeast(other cliVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+// parseCLIVersion extracts the first version number from `--version` output,
+// such as "gh version 2.40.1 (2023-12-13)" or "glab 1.36.0 (abc123)"
+func parseCLIVersion(output string) (cliVersion, error) {
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return cliVersion{}, fmt.Errorf("no version found in %q", output)
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch := 0
+	if match[3] != "" {
+		patch, _ = strconv.Atoi(match[3])
+	}
+
+	return cliVersion{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// checkCLIVersion runs `<cli> --version` and returns an error when the CLI is
+// older than minVersion. An unparseable version is not treated as an error so
+// that unusual builds keep working.
+func checkCLIVersion(name, cliPath string, minVersion cliVersion) (*cliVersion, error) {
+	output, err := exec.Command(cliPath, "--version").Output()
+	if err != nil {
+		return nil, nil
+	}
+
+	version, err := parseCLIVersion(string(output))
+	if err != nil {
+		return nil, nil
+	}
+
+	if !version.AtLeast(minVersion) {
+		return &version, fmt.Errorf("auto-pr requires %s >= %s (found %s). Please upgrade %s", name, minVersion, version, name)
+	}
+
+	return &version, nil
+}
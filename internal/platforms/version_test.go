@@ -0,0 +1,71 @@
+package platforms
+
+import "testing"
+
+func TestParseCLIVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    cliVersion
+		wantErr bool
+	}{
+		{
+			name:   "gh version output",
+			output: "gh version 2.40.1 (2023-12-13)\nhttps://github.com/cli/cli/releases/tag/v2.40.1\n",
+			want:   cliVersion{Major: 2, Minor: 40, Patch: 1},
+		},
+		{
+			name:   "glab version output",
+			output: "glab version 1.36.0 (2024-01-11)\n",
+			want:   cliVersion{Major: 1, Minor: 36, Patch: 0},
+		},
+		{
+			name:   "glab short output",
+			output: "glab 1.22.0 (a1b2c3d)",
+			want:   cliVersion{Major: 1, Minor: 22, Patch: 0},
+		},
+		{
+			name:   "Version without patch",
+			output: "gh version 2.5",
+			want:   cliVersion{Major: 2, Minor: 5, Patch: 0},
+		},
+		{
+			name:    "No version",
+			output:  "gh development build",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCLIVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCLIVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCLIVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIVersionAtLeast(t *testing.T) {
+	minVersion := cliVersion{Major: 2, Minor: 23, Patch: 0}
+
+	tests := []struct {
+		version cliVersion
+		want    bool
+	}{
+		{cliVersion{Major: 2, Minor: 23, Patch: 0}, true},
+		{cliVersion{Major: 2, Minor: 40, Patch: 1}, true},
+		{cliVersion{Major: 3, Minor: 0, Patch: 0}, true},
+		{cliVersion{Major: 2, Minor: 22, Patch: 9}, false},
+		{cliVersion{Major: 1, Minor: 99, Patch: 0}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.version.AtLeast(minVersion); got != tt.want {
+			t.Errorf("%s.AtLeast(%s) = %v, want %v", tt.version, minVersion, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"auto-pr/internal/git"
+)
+
+// cacheFileName is the file inside .git holding cached repository analysis
+const cacheFileName = "auto-pr-cache"
+
+// repoCache is the cached analysis, keyed on a fingerprint of the remote refs
+type repoCache struct {
+	RefsFingerprint string `json:"refs_fingerprint"`
+	BranchPattern   string `json:"branch_pattern"`
+}
+
+// cachedBranchPattern returns the branch pattern cached in .git/auto-pr-cache
+// while the set of remote branches is unchanged, recomputing it otherwise.
+// Worktrees share the cache through the common git directory.
+func cachedBranchPattern(ctx context.Context, repoPath string) (string, error) {
+	gitDir, err := commonGitDir(repoPath)
+	if err != nil {
+		return analyzeExistingBranchPatterns(ctx, repoPath)
+	}
+	fingerprint, err := remoteRefsFingerprint(gitDir)
+	if err != nil {
+		// Unusual layouts just skip the cache
+		return analyzeExistingBranchPatterns(ctx, repoPath)
+	}
+
+	cachePath := filepath.Join(gitDir, cacheFileName)
+	if cache, err := readRepoCache(cachePath); err == nil &&
+		cache.RefsFingerprint == fingerprint && cache.BranchPattern != "" {
+		return cache.BranchPattern, nil
+	}
+
+	pattern, err := analyzeExistingBranchPatterns(ctx, repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	// A failed write only means the pattern is recomputed next time
+	_ = writeRepoCache(cachePath, &repoCache{RefsFingerprint: fingerprint, BranchPattern: pattern})
+
+	return pattern, nil
+}
+
+// commonGitDir resolves the git directory holding the refs of repoPath
+func commonGitDir(repoPath string) (string, error) {
+	gitAnalyzer, err := git.NewAnalyzer(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return gitAnalyzer.CommonGitDir()
+}
+
+// remoteRefsFingerprint summarizes the remote ref set without listing it: the
+// packed-refs file and the modification times of the refs/remotes directories,
+// which change whenever a remote branch is added or removed
+func remoteRefsFingerprint(gitDir string) (string, error) {
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", gitDir)
+	}
+
+	hash := sha256.New()
+	if info, err := os.Stat(filepath.Join(gitDir, "packed-refs")); err == nil {
+		fmt.Fprintf(hash, "packed-refs %d %d\n", info.Size(), info.ModTime().UnixNano())
+	}
+
+	err = filepath.WalkDir(filepath.Join(gitDir, "refs", "remotes"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s %d\n", path, info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func readRepoCache(path string) (*repoCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache repoCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+func writeRepoCache(path string, cache *repoCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRepoWithRemoteBranches creates a repository whose packed-refs lists the
+// given remote branches, all pointing at a single commit
+func newRepoWithRemoteBranches(tb testing.TB, branches []string) string {
+	tb.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		tb.Skip("git not available")
+	}
+
+	dir := tb.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			tb.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	run("init", "-q")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	head := run("rev-parse", "HEAD")
+
+	writePackedRefs(tb, dir, head, branches)
+	return dir
+}
+
+func writePackedRefs(tb testing.TB, dir, hash string, branches []string) {
+	tb.Helper()
+
+	var builder strings.Builder
+	builder.WriteString("# pack-refs with: peeled fully-peeled sorted \n")
+	for _, branch := range branches {
+		fmt.Fprintf(&builder, "%s refs/remotes/origin/%s\n", hash, branch)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "packed-refs"), []byte(builder.String()), 0644); err != nil {
+		tb.Fatalf("failed to write packed-refs: %v", err)
+	}
+}
+
+func syntheticBranches(n int) []string {
+	prefixes := []string{"feature", "fix", "chore", "docs"}
+	branches := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		branches = append(branches, fmt.Sprintf("%s/branch-%05d", prefixes[i%len(prefixes)], i))
+	}
+	return branches
+}
+
+func TestCachedBranchPattern(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"fix/a", "fix/b", "feature/c"})
+
+	pattern, err := cachedBranchPattern(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("cachedBranchPattern() error = %v", err)
+	}
+	if pattern != "fix/" {
+		t.Errorf("cachedBranchPattern() = %q, want %q", pattern, "fix/")
+	}
+
+	cache, err := readRepoCache(filepath.Join(dir, ".git", cacheFileName))
+	if err != nil {
+		t.Fatalf("cache was not written: %v", err)
+	}
+	if cache.BranchPattern != "fix/" {
+		t.Errorf("cached pattern = %q, want %q", cache.BranchPattern, "fix/")
+	}
+
+	// Changing the remote branches invalidates the cache
+	head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse failed: %v", err)
+	}
+	writePackedRefs(t, dir, strings.TrimSpace(string(head)), []string{"docs/a", "docs/b", "docs/c", "fix/d"})
+
+	pattern, err = cachedBranchPattern(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("cachedBranchPattern() error = %v", err)
+	}
+	if pattern != "docs/" {
+		t.Errorf("cachedBranchPattern() after refs changed = %q, want %q", pattern, "docs/")
+	}
+}
+
+func BenchmarkMostCommonBranchPrefix(b *testing.B) {
+	branches := syntheticBranches(10000)
+	for i := range branches {
+		branches[i] = "  origin/" + branches[i]
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mostCommonBranchPrefix(branches)
+	}
+}
+
+func BenchmarkBranchPatternUncached(b *testing.B) {
+	dir := newRepoWithRemoteBranches(b, syntheticBranches(10000))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := analyzeExistingBranchPatterns(context.Background(), dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBranchPatternCached(b *testing.B) {
+	dir := newRepoWithRemoteBranches(b, syntheticBranches(10000))
+	if _, err := cachedBranchPattern(context.Background(), dir); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cachedBranchPattern(context.Background(), dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
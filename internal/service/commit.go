@@ -0,0 +1,734 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/editor"
+	"auto-pr/internal/git"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// CommitOptions configures Commit
+type CommitOptions struct {
+	RepoPath        string
+	StageAll        bool
+	Message         string // Custom commit message; generated with AI when empty
+	Amend           bool
+	KeepSubject     bool // When amending, keep the subject and regenerate only the body
+	Push            bool // Push after committing; without Push or NoPush, git.auto_push decides
+	NoPush          bool
+	Force           bool // Allow pushing a protected branch
+	CoAuthors       []string
+	DetectCoAuthors bool
+	Interactive     bool // Pick co-authors among the people who recently changed the staged files
+	Detailed        bool
+	NoStat          bool   // Skip the per-file line counts, which are slow to gather in very large repositories
+	Type            string // Conventional commit type (feat, fix, ...) the generated message must use
+	Edit            bool   // Open the message in $EDITOR before committing
+	HookFile        string // Write the message to git's message file (prepare-commit-msg hook) instead of committing
+	WIP             bool   // Stage everything and commit a timestamped checkpoint message without the AI
+	Group           string // CommitGroupFile or CommitGroupDir to make one commit per file or top-level directory
+	DryRun          bool
+	In              io.Reader // Answers for interactive prompts; defaults to standard input
+	Out             io.Writer
+}
+
+// coAuthorWindow is how far back Interactive looks for people who changed the
+// staged files, as likely pairing partners
+const coAuthorWindow = 14 * 24 * time.Hour
+
+// wipPrefix starts the message of a checkpoint commit made with WIP
+const wipPrefix = "wip: checkpoint"
+
+// commitEditHint is shown below the message opened with --edit
+const commitEditHint = `Edit the commit message above. Lines starting with '#' are ignored,
+and an empty message aborts the commit.`
+
+// CommitResult describes the commit that was created. With Group it is the
+// last of the commits, which are all listed in Commits.
+type CommitResult struct {
+	Hash    string
+	Message string
+	Commits []CommitResult
+}
+
+// Commit stages (optionally) and commits changes, generating the commit
+// message with AI when none is given. In dry-run mode the result has no hash.
+func Commit(opts CommitOptions) (*CommitResult, error) {
+	out := output(opts.Out)
+
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	gitAnalyzer.SetSkipFileStats(opts.NoStat)
+
+	coAuthors := opts.CoAuthors
+	for _, coAuthor := range coAuthors {
+		if !isValidCoAuthor(coAuthor) {
+			return nil, fmt.Errorf("invalid co-author %q, expected \"Name <email>\"", coAuthor)
+		}
+	}
+
+	if err := ValidateCommitType(opts.Type); err != nil {
+		return nil, err
+	}
+
+	if err := validateCommitGroup(opts); err != nil {
+		return nil, err
+	}
+
+	// A checkpoint is meant to be folded into a real commit later, so it
+	// skips the AI and everything that shapes the message
+	if opts.WIP {
+		if opts.Message != "" || opts.Type != "" || opts.Detailed || opts.KeepSubject || opts.Edit || opts.HookFile != "" {
+			return nil, fmt.Errorf("--wip can't be combined with -m, --type, --detailed, --keep-subject, --edit or --hook")
+		}
+		opts.StageAll = true
+		opts.Message = wipMessage(time.Now())
+	}
+
+	if opts.KeepSubject && (!opts.Amend || opts.Message != "") {
+		return nil, fmt.Errorf("--keep-subject only applies when amending with a generated message")
+	}
+
+	// Git is already committing and owns staging, the editor and what follows
+	if opts.HookFile != "" {
+		if opts.StageAll || opts.Amend || opts.Push || opts.Edit || opts.Interactive || opts.Message != "" {
+			return nil, fmt.Errorf("--hook can't be combined with --all, --amend, --push, --edit, --interactive or -m")
+		}
+		return prepareMessageFile(opts, gitAnalyzer, coAuthors)
+	}
+
+	if opts.Push, err = resolvePush(opts.Push, opts.NoPush, commitPushesByDefault); err != nil {
+		return nil, err
+	}
+
+	// Get repository status first
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+
+	// Committing now would record the conflict markers
+	if len(status.UnmergedFiles) > 0 {
+		return nil, unmergedFilesError(status.UnmergedFiles)
+	}
+
+	// Changes reach a protected branch through PRs/MRs, not direct commits
+	if !opts.Force {
+		cfg, err := config.LoadConfigWithViper()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if git.IsProtectedBranch(status.CurrentBranch, cfg.Git.ProtectedBranches) {
+			if opts.Push {
+				return nil, protectedBranchError(status.CurrentBranch)
+			}
+			return nil, protectedCommitError(status.CurrentBranch)
+		}
+	}
+
+	if opts.Group != "" {
+		return commitInGroups(opts, gitAnalyzer, status, out)
+	}
+
+	// The files whose history suggests co-authors, including those a dry run
+	// would only have staged
+	staged := status.StagedFiles
+
+	// Stage files if requested
+	if opts.StageAll {
+		toStage, skipped, err := filesToStage(gitAnalyzer, status)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.DryRun {
+			fmt.Fprintf(out, "%s Would stage %d files\n", ui.Sync, len(toStage))
+			for _, file := range toStage {
+				fmt.Fprintf(out, "   %s\n", file)
+			}
+			printSkippedFiles(out, skipped)
+			staged = removeDuplicates(append(append([]string{}, staged...), toStage...))
+		} else {
+			fmt.Fprintf(out, "%s Staging all changes...\n", ui.Sync)
+			printSkippedFiles(out, skipped)
+			if err := gitAnalyzer.StageFiles(toStage); err != nil {
+				return nil, err
+			}
+			// Refresh status after staging
+			status, err = gitAnalyzer.GetStatus()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get updated repository status: %w", err)
+			}
+			fmt.Fprintf(out, "%s Changes staged\n", ui.Success)
+			staged = status.StagedFiles
+		}
+	}
+
+	if len(status.StagedFiles) == 0 && !opts.Amend && !opts.StageAll {
+		return nil, fmt.Errorf("no changes staged for commit. Use --all to stage all changes")
+	}
+
+	commitMessage := opts.Message
+	if commitMessage == "" {
+		// The message being amended is a starting point for the new one
+		var previous string
+		if opts.Amend {
+			if previous, err = gitAnalyzer.GetLastCommitMessage(); err != nil {
+				return nil, err
+			}
+			// A checkpoint's message says nothing about the changes it holds
+			if isWIPMessage(previous) && !opts.KeepSubject {
+				previous = ""
+			}
+		}
+
+		fmt.Fprintf(out, "%s Generating commit message with AI...\n", ui.Robot)
+
+		// Generate AI commit message
+		commitMessage, err = generateCommitMessage(gitAnalyzer, status, opts.Amend, previous, opts.Type, opts.Detailed || opts.KeepSubject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate commit message: %w", err)
+		}
+
+		if opts.KeepSubject {
+			commitMessage = keepSubject(previous, commitMessage)
+		}
+	}
+
+	if opts.Interactive {
+		coAuthors = append(coAuthors, pickCoAuthors(opts, out, gitAnalyzer, staged, coAuthors)...)
+	} else if opts.DetectCoAuthors {
+		detected, err := gitAnalyzer.GetRecentAuthors(staged, 20)
+		if err != nil {
+			fmt.Fprintf(out, "%s Failed to detect co-authors: %v\n", ui.Warning, err)
+		}
+		coAuthors = append(coAuthors, detected...)
+	}
+
+	commitMessage = appendCoAuthorTrailers(commitMessage, coAuthors)
+
+	// There is nothing to commit the edited message to in a dry run
+	if opts.Edit && !opts.DryRun {
+		commitMessage, err = editor.Edit(commitMessage, "auto-pr-commit-*.txt", commitEditHint)
+		if err != nil {
+			return nil, err
+		}
+		if commitMessage == "" {
+			return nil, fmt.Errorf("aborting commit due to empty commit message")
+		}
+	}
+
+	fmt.Fprintf(out, "%s Commit message:\n%s\n\n", ui.Note, commitMessage)
+
+	if opts.DryRun {
+		fmt.Fprintf(out, "%s Dry run - would commit with above message\n", ui.Search)
+		if opts.Push {
+			fmt.Fprintf(out, "%s Dry run - would push to %s\n", ui.Search, gitAnalyzer.PushTarget(status.CurrentBranch))
+		}
+		return &CommitResult{Message: commitMessage}, nil
+	}
+
+	// Create the commit
+	fmt.Fprintf(out, "%s Creating commit...\n", ui.Save)
+	commitHash, err := gitAnalyzer.Commit(commitMessage, opts.Amend)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(out, "%s Commit %s created successfully!\n", ui.Success, git.ShortHash(commitHash))
+	if opts.WIP {
+		fmt.Fprintf(out, "%s Fold this checkpoint into a real commit with: auto-pr commit --all --amend\n", ui.Tip)
+	}
+
+	// Push if requested
+	if opts.Push {
+		fmt.Fprintf(out, "%s Pushing to remote...\n", ui.Rocket)
+		if err := gitAnalyzer.Push(); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(out, "%s Changes pushed!\n", ui.Success)
+	}
+
+	return &CommitResult{Hash: commitHash, Message: commitMessage}, nil
+}
+
+// prepareMessageFile writes a generated message for the staged changes into
+// the message file git passes to a prepare-commit-msg hook, above the comments
+// git put there. A message already in the file, such as one given with -m, a
+// merge message or the commit being amended, is left alone. The hook must not
+// stop the commit, so a failed generation only warns and leaves the file.
+func prepareMessageFile(opts CommitOptions, gitAnalyzer *git.Analyzer, coAuthors []string) (*CommitResult, error) {
+	out := output(opts.Out)
+
+	content, err := os.ReadFile(opts.HookFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit message file: %w", err)
+	}
+	if existing := messageInFile(string(content)); existing != "" {
+		return &CommitResult{Message: existing}, nil
+	}
+
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if len(status.StagedFiles) == 0 {
+		return &CommitResult{}, nil
+	}
+
+	fmt.Fprintf(out, "%s Generating commit message with AI...\n", ui.Robot)
+	message, err := generateCommitMessage(gitAnalyzer, status, false, "", opts.Type, opts.Detailed)
+	if err != nil {
+		fmt.Fprintf(out, "%s Failed to generate commit message, write it yourself: %v\n", ui.Warning, err)
+		return &CommitResult{}, nil
+	}
+
+	if opts.DetectCoAuthors {
+		detected, err := gitAnalyzer.GetRecentAuthors(status.StagedFiles, 20)
+		if err != nil {
+			fmt.Fprintf(out, "%s Failed to detect co-authors: %v\n", ui.Warning, err)
+		}
+		coAuthors = append(coAuthors, detected...)
+	}
+	message = appendCoAuthorTrailers(message, coAuthors)
+
+	if opts.DryRun {
+		fmt.Fprintf(out, "%s Dry run - would write to %s:\n%s\n", ui.Search, opts.HookFile, message)
+		return &CommitResult{Message: message}, nil
+	}
+
+	if err := os.WriteFile(opts.HookFile, []byte(message+"\n"+string(content)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write commit message file: %w", err)
+	}
+	return &CommitResult{Message: message}, nil
+}
+
+// messageInFile returns the message in a commit message file without git's
+// comment lines and anything below the scissors line, or "" when there is none
+func messageInFile(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// filesToStage returns the changed and untracked files to stage, leaving out
+// those matching auto-pr's ignore patterns (reported as "path (pattern)")
+func filesToStage(gitAnalyzer *git.Analyzer, status *types.GitStatus) (toStage, skipped []string, err error) {
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// List untracked files individually so patterns apply inside new directories
+	untracked, err := gitAnalyzer.GetUntrackedFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range append(append([]string{}, status.UnstagedFiles...), untracked...) {
+		if pattern, ok := git.MatchIgnorePattern(file, cfg.Git.IgnorePatterns); ok {
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", file, pattern))
+			continue
+		}
+		toStage = append(toStage, file)
+	}
+
+	return toStage, skipped, nil
+}
+
+// printSkippedFiles warns about files left unstaged by the ignore patterns
+func printSkippedFiles(out io.Writer, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "%s Skipping %d file(s) matching ignore_patterns:\n", ui.Warning, len(skipped))
+	for _, file := range skipped {
+		fmt.Fprintf(out, "   - %s\n", file)
+	}
+}
+
+// pickCoAuthors offers the people who changed the staged files in the last
+// coAuthorWindow, other than those already given, as co-authors and returns
+// the ones picked. They start preselected with DetectCoAuthors.
+func pickCoAuthors(opts CommitOptions, out io.Writer, gitAnalyzer *git.Analyzer, staged, given []string) []string {
+	recent, err := gitAnalyzer.GetRecentAuthorsSince(staged, time.Now().Add(-coAuthorWindow), 100)
+	if err != nil {
+		fmt.Fprintf(out, "%s Failed to detect co-authors: %v\n", ui.Warning, err)
+		return nil
+	}
+
+	candidates := missingValues(given, recent)
+	var preselected []string
+	if opts.DetectCoAuthors {
+		preselected = candidates
+	}
+	return pickMany(bufio.NewReader(input(opts.In)), out, fmt.Sprintf("%s Add co-authors who recently changed these files", ui.People), candidates, preselected)
+}
+
+// isValidCoAuthor checks that a co-author is in "Name <email>" form
+func isValidCoAuthor(coAuthor string) bool {
+	start := strings.Index(coAuthor, "<")
+	end := strings.LastIndex(coAuthor, ">")
+	return start > 0 && end > start+1 && strings.TrimSpace(coAuthor[:start]) != ""
+}
+
+// appendCoAuthorTrailers appends Co-authored-by trailers after a blank line,
+// leaving the generated message intact and skipping duplicates
+func appendCoAuthorTrailers(message string, coAuthors []string) string {
+	var trailers []string
+	for _, coAuthor := range removeDuplicates(coAuthors) {
+		trailer := "Co-authored-by: " + strings.TrimSpace(coAuthor)
+		if !strings.Contains(message, trailer) {
+			trailers = append(trailers, trailer)
+		}
+	}
+
+	if len(trailers) == 0 {
+		return message
+	}
+
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(trailers, "\n")
+}
+
+// wipMessage returns the message of a checkpoint commit made at t
+func wipMessage(t time.Time) string {
+	return fmt.Sprintf("%s %s", wipPrefix, t.Format("2006-01-02 15:04:05"))
+}
+
+// isWIPMessage reports whether message is that of a checkpoint commit
+func isWIPMessage(message string) bool {
+	return strings.HasPrefix(message, wipPrefix)
+}
+
+// keepSubject returns the body of a generated message under the subject of
+// the previous one, or the previous message when nothing new was generated
+func keepSubject(previous, generated string) string {
+	subject, _, _ := strings.Cut(previous, "\n")
+	_, body, _ := strings.Cut(generated, "\n\n")
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return previous
+	}
+	return strings.TrimSpace(subject) + "\n\n" + body
+}
+
+// generateCommitMessage asks the AI for a message describing the staged
+// changes or, when amending, the amended commit, as a refined version of
+// previous (the message being amended) when that is set. A commitType
+// replaces the type the AI would pick.
+func generateCommitMessage(gitAnalyzer *git.Analyzer, status *types.GitStatus, amend bool, previous, commitType string, detailed bool) (string, error) {
+	// Load configuration
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	detailed = detailed || cfg.Git.DetailedCommits
+	gitAnalyzer.SetDiffContext(cfg.Git.DiffContext)
+
+	// Create AI client
+	client, err := ai.NewClient(cfg.AI)
+	if err != nil {
+		return "", fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	var diffSummary, diffContent string
+	var fileChanges []types.FileChange
+	if amend {
+		// An amended commit holds the last commit's changes plus the staged ones
+		diffContent, err = gitAnalyzer.GetAmendDiff()
+		if err != nil {
+			return "", err
+		}
+
+		fileChanges = git.ParseUnifiedDiff(diffContent)
+		additions, deletions := 0, 0
+		for _, change := range fileChanges {
+			additions += change.Additions
+			deletions += change.Deletions
+		}
+		diffSummary = fmt.Sprintf("%d files changed, %d additions, %d deletions", len(fileChanges), additions, deletions)
+	} else {
+		// Get diff for staged files
+		diffSummary, err = getStagedDiff(gitAnalyzer.RepoPath())
+		if err != nil {
+			return "", fmt.Errorf("failed to get diff: %w", err)
+		}
+
+		// Include the actual staged changes so the message reflects the code
+		diffContent, err = gitAnalyzer.GetDiff(true)
+		if err != nil {
+			return "", fmt.Errorf("failed to get staged diff: %w", err)
+		}
+
+		fileChanges, err = gitAnalyzer.GetStagedFileChanges()
+		if err != nil {
+			return "", fmt.Errorf("failed to get staged file changes: %w", err)
+		}
+	}
+
+	// Lockfiles are committed, but their diffs tell the AI nothing
+	diffContent = git.ExcludeFromDiff(diffContent, cfg.Git.ContextExcludePatterns)
+	fileChanges, excluded := git.ExcludeFileChanges(fileChanges, cfg.Git.ContextExcludePatterns)
+	if note := excludedNote(excluded); note != "" {
+		diffSummary = strings.TrimRight(diffSummary, "\n") + "\n" + note
+	}
+
+	// Build AI context
+	context := &ai.AIContext{
+		DiffSummary: diffSummary,
+		DiffContent: git.TruncateDiff(diffContent, cfg.Git.MaxDiffSize),
+		FileChanges: fileChanges,
+		BranchInfo: types.BranchInfo{
+			Name:       status.CurrentBranch,
+			BaseBranch: status.BaseBranch,
+		},
+	}
+
+	// Generate commit message, with the team's own prompt when configured
+	prompt, err := commitPrompt(cfg.Git.CommitPromptTemplate, gitAnalyzer.RepoPath(), context)
+	if err != nil {
+		return "", err
+	}
+	if commitType != "" {
+		prompt += fmt.Sprintf(`
+
+This is a %[1]s change: the message must start with "%[1]s: " or "%[1]s(scope): ".
+Choose only the scope and the description.`, commitType)
+	}
+	if detailed {
+		prompt += `
+
+Also write a commit body in the "body" field:
+- Explain WHY the change was made and any notable trade-offs
+- Use plain text paragraphs or "- " bullet points, no markdown headers
+- Keep it to a few short paragraphs`
+	}
+	if previous != "" {
+		prompt += `
+
+The commit is being amended and currently has the message below. Use it as a
+starting point: keep what is still accurate, correct what the diff contradicts,
+and cover anything it leaves out.
+
+` + previous
+	}
+
+	response, err := client.GenerateContent(context, prompt)
+	if err != nil {
+		return "", fmt.Errorf("AI generation failed: %w", err)
+	}
+
+	// Extract just the commit message (first line of the response)
+	subject := response.Title
+	lines := strings.Split(strings.TrimSpace(response.Title), "\n")
+	if len(lines) > 0 {
+		subject = strings.TrimSpace(lines[0])
+	}
+
+	// The AI may still pick its own type, so enforce the chosen one
+	subject = withCommitType(subject, commitType)
+
+	body := strings.TrimSpace(response.Body)
+	if !detailed || body == "" {
+		return subject, nil
+	}
+
+	return subject + "\n\n" + wrapText(body, 72), nil
+}
+
+// commitTypes are the conventional commit types --type accepts
+var commitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// ValidateCommitType rejects a commit type that isn't a conventional one;
+// an empty type leaves the choice to the AI
+func ValidateCommitType(commitType string) error {
+	if commitType == "" {
+		return nil
+	}
+	for _, known := range commitTypes {
+		if commitType == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid commit type %q, expected one of: %s", commitType, strings.Join(commitTypes, ", "))
+}
+
+// withCommitType gives a commit message the chosen conventional type,
+// replacing the one its subject has but keeping the scope, breaking-change
+// marker and description; subjects without a type get the prefix added
+func withCommitType(message, commitType string) string {
+	if commitType == "" {
+		return message
+	}
+
+	subject, rest, hasBody := strings.Cut(message, "\n")
+	if match := conventionalSubject.FindStringSubmatch(subject); match == nil {
+		subject = commitType + ": " + subject
+	} else {
+		prefix := commitType
+		if match[2] != "" {
+			prefix += "(" + match[2] + ")"
+		}
+		subject = prefix + match[3] + ": " + match[4]
+	}
+
+	if !hasBody {
+		return subject
+	}
+	return subject + "\n" + rest
+}
+
+// defaultCommitPrompt is the commit message prompt used unless
+// git.commit_prompt_template replaces it
+const defaultCommitPrompt = `Generate a concise, clear commit message for these changes.
+
+Rules:
+- Use conventional commit format (feat:, fix:, docs:, refactor:, etc.)
+- First line should be 50 characters or less
+- Be specific about what changed
+- Don't include explanations, just the action
+
+Example formats:
+- feat: add user authentication
+- fix: resolve memory leak in parser
+- docs: update API documentation
+- refactor: simplify error handling
+
+Focus on WHAT changed, not HOW or WHY.`
+
+// commitPromptData is what a commit prompt template can refer to
+type commitPromptData struct {
+	Branch  string // {{.Branch}}: the current branch
+	Summary string // {{.Summary}}: e.g. "3 files changed, 10 additions, 2 deletions"
+	Files   string // {{.Files}}: the changed files, one "- path (status)" per line
+	Diff    string // {{.Diff}}: the (possibly truncated) diff
+}
+
+// commitPrompt returns the prompt for a commit message: the built-in one, or
+// the configured template (a file path, relative to the repository root or
+// ~, or the template itself) rendered with the staged changes
+func commitPrompt(promptTemplate, repoPath string, ctx *ai.AIContext) (string, error) {
+	if strings.TrimSpace(promptTemplate) == "" {
+		return defaultCommitPrompt, nil
+	}
+
+	source := promptTemplate
+	if file := promptTemplateFile(promptTemplate, repoPath); file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read git.commit_prompt_template: %w", err)
+		}
+		source = string(content)
+	}
+
+	tmpl, err := template.New("commit_prompt").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid git.commit_prompt_template: %w", err)
+	}
+
+	files := make([]string, len(ctx.FileChanges))
+	for i, change := range ctx.FileChanges {
+		files[i] = fmt.Sprintf("- %s (%s)", change.Path, change.Status)
+	}
+	data := commitPromptData{
+		Branch:  ctx.BranchInfo.Name,
+		Summary: ctx.DiffSummary,
+		Files:   strings.Join(files, "\n"),
+		Diff:    ctx.DiffContent,
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("invalid git.commit_prompt_template: %w", err)
+	}
+	return strings.TrimSpace(prompt.String()), nil
+}
+
+// promptTemplateFile returns the file a prompt template setting names, or ""
+// when the setting is the template itself
+func promptTemplateFile(value, repoPath string) string {
+	if strings.ContainsAny(value, "\n{") {
+		return ""
+	}
+
+	file := value
+	if strings.HasPrefix(file, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		file = filepath.Join(home, file[2:])
+	} else if !filepath.IsAbs(file) {
+		file = filepath.Join(repoPath, file)
+	}
+
+	if info, err := os.Stat(file); err != nil || info.IsDir() {
+		return ""
+	}
+	return file
+}
+
+// wrapText wraps each line of text at the given width, keeping blank lines
+// and indenting continuation lines of "- " bullet points
+func wrapText(text string, width int) string {
+	var wrapped []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " ")
+		if len(line) <= width {
+			wrapped = append(wrapped, line)
+			continue
+		}
+
+		indent := ""
+		if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			indent = "  "
+		}
+
+		current := ""
+		for _, word := range strings.Fields(line) {
+			switch {
+			case current == "":
+				current = word
+			case len(current)+1+len(word) > width:
+				wrapped = append(wrapped, current)
+				current = indent + word
+			default:
+				current += " " + word
+			}
+		}
+		wrapped = append(wrapped, current)
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+func getStagedDiff(repoPath string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "diff", "--cached", "--stat")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
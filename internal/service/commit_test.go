@@ -0,0 +1,429 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+
+	"github.com/spf13/viper"
+)
+
+func TestAppendCoAuthorTrailers(t *testing.T) {
+	message := "feat: add thing\n\nCo-authored-by: Ada <ada@example.com>"
+	got := appendCoAuthorTrailers(message, []string{
+		"Ada <ada@example.com>",
+		"Bob <bob@example.com>",
+		"Bob <bob@example.com>",
+	})
+
+	want := message + "\n\nCo-authored-by: Bob <bob@example.com>"
+	if got != want {
+		t.Errorf("appendCoAuthorTrailers() = %q, want %q", got, want)
+	}
+}
+
+func TestMessageInFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "Only git's comments",
+			content: "\n# Please enter the commit message for your changes.\n# On branch main\n",
+			want:    "",
+		},
+		{
+			name:    "Merge message",
+			content: "Merge branch 'feature'\n\n# Please enter a commit message to explain why this merge is necessary.\n",
+			want:    "Merge branch 'feature'",
+		},
+		{
+			name:    "Diff below the scissors line",
+			content: "\n# On branch main\n# ------------------------ >8 ------------------------\ndiff --git a/a.go b/a.go\n+code\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageInFile(tt.content); got != tt.want {
+				t.Errorf("messageInFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepSubject(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  string
+		generated string
+		want      string
+	}{
+		{
+			name:      "Replaces the body",
+			previous:  "fix: handle empty input\n\nOld body.",
+			generated: "fix: guard against empty input\n\nNew body.",
+			want:      "fix: handle empty input\n\nNew body.",
+		},
+		{
+			name:      "Adds a body",
+			previous:  "fix: handle empty input",
+			generated: "fix: guard against empty input\n\nNew body.",
+			want:      "fix: handle empty input\n\nNew body.",
+		},
+		{
+			name:      "Keeps the previous message without a new body",
+			previous:  "fix: handle empty input\n\nOld body.",
+			generated: "fix: guard against empty input",
+			want:      "fix: handle empty input\n\nOld body.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keepSubject(tt.previous, tt.generated); got != tt.want {
+				t.Errorf("keepSubject() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCommitType(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		commitType string
+		want       string
+	}{
+		{name: "No type chosen", message: "feat: add parser", want: "feat: add parser"},
+		{name: "Replaces the type", message: "feat: handle empty input", commitType: "fix", want: "fix: handle empty input"},
+		{name: "Keeps scope and breaking marker", message: "feat(api)!: drop v1", commitType: "refactor", want: "refactor(api)!: drop v1"},
+		{name: "Adds a missing type", message: "Update README", commitType: "docs", want: "docs: Update README"},
+		{name: "Keeps the body", message: "feat: add parser\n\nWhy.", commitType: "fix", want: "fix: add parser\n\nWhy."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withCommitType(tt.message, tt.commitType); got != tt.want {
+				t.Errorf("withCommitType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommitType(t *testing.T) {
+	for _, valid := range []string{"", "feat", "fix", "docs", "chore"} {
+		if err := ValidateCommitType(valid); err != nil {
+			t.Errorf("ValidateCommitType(%q) error = %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"feature", "FIX", "bugfix"} {
+		if err := ValidateCommitType(invalid); err == nil {
+			t.Errorf("ValidateCommitType(%q) accepted an unknown type", invalid)
+		}
+	}
+}
+
+func TestCommitPrompt(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "commit-prompt.txt"), []byte("Gitmoji for {{.Branch}}:\n{{.Files}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &ai.AIContext{
+		DiffSummary: "2 files changed, 5 additions, 1 deletions",
+		DiffContent: "+added line",
+		FileChanges: []types.FileChange{
+			{Path: "main.go", Status: types.StatusModified},
+			{Path: "docs/new.md", Status: types.StatusAdded},
+		},
+		BranchInfo: types.BranchInfo{Name: "PROJ-42-export"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Built-in default", template: "", want: defaultCommitPrompt},
+		{
+			name:     "Inline template",
+			template: "Prefix the subject with the ticket in {{.Branch}}. {{.Summary}}",
+			want:     "Prefix the subject with the ticket in PROJ-42-export. 2 files changed, 5 additions, 1 deletions",
+		},
+		{
+			name:     "Template file relative to the repository",
+			template: "commit-prompt.txt",
+			want:     "Gitmoji for PROJ-42-export:\n- main.go (modified)\n- docs/new.md (added)",
+		},
+		{
+			name:     "Text that isn't a file is used as is",
+			template: "Write the subject in German",
+			want:     "Write the subject in German",
+		},
+		{name: "Unknown placeholder", template: "{{.Ticket}}", wantErr: true},
+		{name: "Malformed template", template: "{{.Diff", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commitPrompt(tt.template, repo, ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("commitPrompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("commitPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitDryRunPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote, dir := filepath.Join(root, "remote.git"), filepath.Join(root, "work")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("init", "-q", "--bare", remote)
+	run("init", "-q", "-b", "main", dir)
+	run("-C", dir, "remote", "add", "origin", remote)
+	run("-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	run("-C", dir, "checkout", "-q", "-b", "feature/push")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("-C", dir, "add", "file.txt")
+	head := run("-C", dir, "rev-parse", "HEAD")
+
+	tests := []struct {
+		name  string
+		setup func()
+		want  string
+	}{
+		{name: "New branch", setup: func() {}, want: "would push to origin/feature/push"},
+		{name: "Tracked branch", setup: func() {
+			run("-C", dir, "push", "-q", "--set-upstream", "origin", "HEAD:shared")
+		}, want: "would push to origin/shared"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			refs := run("-C", remote, "for-each-ref")
+
+			var out bytes.Buffer
+			_, err := Commit(CommitOptions{RepoPath: dir, Message: "feat: add file", Push: true, Force: true, DryRun: true, Out: &out})
+			if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("Commit() output missing %q:\n%s", tt.want, out.String())
+			}
+			if got := run("-C", dir, "rev-parse", "HEAD"); got != head {
+				t.Error("Commit() created a commit in a dry run")
+			}
+			if got := run("-C", remote, "for-each-ref"); got != refs {
+				t.Errorf("Commit() pushed in a dry run: %s", got)
+			}
+		})
+	}
+}
+
+func TestCommitWIP(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("checkout", "-q", "-b", "feature/wip")
+	write := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "feat: add widgets"})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	if _, err := Commit(CommitOptions{RepoPath: dir, WIP: true, Message: "mine", Out: io.Discard}); err == nil {
+		t.Error("Commit() accepted --wip with -m")
+	}
+
+	// The checkpoint stages everything and skips the AI
+	write("first.txt")
+	result, err := Commit(CommitOptions{RepoPath: dir, WIP: true, Out: io.Discard})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if !isWIPMessage(result.Message) || run("log", "-1", "--format=%s") != result.Message {
+		t.Errorf("Commit() message = %q, want a committed checkpoint", result.Message)
+	}
+	if run("status", "--porcelain") != "" {
+		t.Error("Commit() left changes unstaged")
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("Commit() made %d AI calls for a checkpoint, want 0", len(calls))
+	}
+
+	// Amending folds it into a real commit, described from the whole diff
+	write("second.txt")
+	result, err = Commit(CommitOptions{RepoPath: dir, StageAll: true, Amend: true, Out: io.Discard})
+	if err != nil {
+		t.Fatalf("Commit() amend error = %v", err)
+	}
+	if result.Message != "feat: add widgets" || run("rev-list", "--count", "HEAD") != "2" {
+		t.Errorf("Commit() amend = %q, want the checkpoint replaced by the generated message", result.Message)
+	}
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Commit() amend made %d AI calls, want 1", len(calls))
+	}
+	if strings.Contains(calls[0].Prompt, wipPrefix) {
+		t.Error("Commit() amend asked the AI to refine the checkpoint message")
+	}
+	for _, file := range []string{"first.txt", "second.txt"} {
+		if !strings.Contains(calls[0].Context.DiffContent, file) {
+			t.Errorf("AI context diff missing %s", file)
+		}
+	}
+}
+
+func TestCommitInteractiveCoAuthors(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	if output, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "feature/shared").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v\n%s", err, output)
+	}
+	commitAs := func(author, date, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "shared.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		name, email, _ := strings.Cut(author, " <")
+		cmd := exec.Command("git", "-C", dir, "-c", "user.name="+name, "-c", "user.email="+strings.TrimSuffix(email, ">"),
+			"commit", "-q", "-m", "change shared", "--", "shared.txt")
+		// git log --since goes by the commit date
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if output, err := exec.Command("git", "-C", dir, "add", "shared.txt").CombinedOutput(); err != nil {
+			t.Fatalf("git add failed: %v\n%s", err, output)
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, output)
+		}
+	}
+
+	// Bob changed the file too long ago to be pairing on it
+	commitAs("Bob <bob@example.com>", time.Now().Add(-60*24*time.Hour).Format(time.RFC3339), "bob\n")
+	commitAs("Ada <ada@example.com>", time.Now().Format(time.RFC3339), "ada\n")
+	commitAs("Cy <cy@example.com>", time.Now().Format(time.RFC3339), "cy\n")
+	if err := os.WriteFile(filepath.Join(dir, "shared.txt"), []byte("mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		detect bool
+		answer string
+		want   []string
+	}{
+		{"pick one", false, "1\n", []string{"Cy <cy@example.com>"}},
+		{"enter adds none", false, "\n", nil},
+		{"enter keeps detected", true, "\n", []string{"Cy <cy@example.com>", "Ada <ada@example.com>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			result, err := Commit(CommitOptions{
+				RepoPath:        dir,
+				StageAll:        true,
+				Message:         "update shared",
+				CoAuthors:       []string{"Zoe <zoe@example.com>"},
+				DetectCoAuthors: tt.detect,
+				Interactive:     true,
+				DryRun:          true,
+				In:              strings.NewReader(tt.answer),
+				Out:             &out,
+			})
+			if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+
+			if strings.Contains(out.String(), "bob@example.com") {
+				t.Errorf("Commit() offered an author from outside the window:\n%s", out.String())
+			}
+			want := "update shared\n\nCo-authored-by: Zoe <zoe@example.com>"
+			for _, coAuthor := range tt.want {
+				want += "\nCo-authored-by: " + coAuthor
+			}
+			if result.Message != want {
+				t.Errorf("Commit() message = %q, want %q", result.Message, want)
+			}
+		})
+	}
+}
+
+func TestCommitRefusesProtectedBranch(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"trunk"})
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("checkout", "-q", "-b", "trunk")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(viper.Reset)
+	viper.Set("git.protected_branches", []string{"trunk"})
+
+	// Committing without pushing is refused too
+	_, err := Commit(CommitOptions{RepoPath: dir, StageAll: true, Message: "chore: change", NoPush: true, Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "refusing to commit") {
+		t.Fatalf("Commit() error = %v, want a refusal to commit to trunk", err)
+	}
+	if got := run("rev-list", "--count", "HEAD"); got != "1" {
+		t.Errorf("Commit() made a commit on the protected branch: %s commits", got)
+	}
+
+	if _, err := Commit(CommitOptions{RepoPath: dir, StageAll: true, Message: "chore: change", NoPush: true, Force: true, Out: io.Discard}); err != nil {
+		t.Fatalf("Commit() with --force error = %v", err)
+	}
+	if got := run("rev-list", "--count", "HEAD"); got != "2" {
+		t.Errorf("Commit() with --force didn't commit: %s commits", got)
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"auto-pr/internal/git"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// Ways CommitOptions.Group splits the changes into commits
+const (
+	CommitGroupFile = "file" // One commit per changed file
+	CommitGroupDir  = "dir"  // One commit per top-level directory
+)
+
+// commitGroup is the set of changes one commit of a grouped run records
+type commitGroup struct {
+	Name  string   // The file, or the top-level directory ("." for files at the root)
+	Paths []string // Paths to stage, including the sources of renames
+}
+
+// validateCommitGroup rejects an unknown Group and the options that only make
+// sense for a single commit
+func validateCommitGroup(opts CommitOptions) error {
+	switch opts.Group {
+	case "":
+		return nil
+	case CommitGroupFile, CommitGroupDir:
+	default:
+		return fmt.Errorf("invalid commit grouping %q, expected %q or %q", opts.Group, CommitGroupFile, CommitGroupDir)
+	}
+
+	conflicts := []struct {
+		set  bool
+		flag string
+	}{
+		{opts.Message != "", "-m"},
+		{opts.Amend, "--amend"},
+		{opts.KeepSubject, "--keep-subject"},
+		{opts.WIP, "--wip"},
+		{opts.Edit, "--edit"},
+		{opts.Interactive, "--interactive"},
+		{opts.HookFile != "", "--hook"},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("--per-file and --group-by-dir make several commits and can't be combined with %s", conflict.flag)
+		}
+	}
+	return nil
+}
+
+// commitGroupName returns the group path belongs to
+func commitGroupName(path, groupBy string) string {
+	if groupBy == CommitGroupFile {
+		return path
+	}
+	if dir, _, found := strings.Cut(path, "/"); found {
+		return dir
+	}
+	return "."
+}
+
+// groupChanges splits the changed files into commit groups, sorted by name.
+// renamedFrom maps a renamed file to its old path, which is staged with it.
+func groupChanges(files []string, renamedFrom map[string]string, groupBy string) []commitGroup {
+	byName := make(map[string]*commitGroup)
+	var names []string
+	for _, file := range files {
+		name := commitGroupName(file, groupBy)
+		group, ok := byName[name]
+		if !ok {
+			group = &commitGroup{Name: name}
+			byName[name] = group
+			names = append(names, name)
+		}
+		group.Paths = append(group.Paths, file)
+		if from := renamedFrom[file]; from != "" {
+			group.Paths = append(group.Paths, from)
+		}
+	}
+
+	sort.Strings(names)
+	groups := make([]commitGroup, len(names))
+	for i, name := range names {
+		groups[i] = *byName[name]
+	}
+	return groups
+}
+
+// commitInGroups commits the staged changes (and, with StageAll, all the
+// others) as one commit per file or top-level directory, each with its own
+// generated message. It empties the index and stages one group at a time, so
+// a partly staged file is refused unless StageAll stages the rest of it too.
+// When a commit fails, the groups not yet committed are staged again and the
+// commits already made are returned with the error.
+func commitInGroups(opts CommitOptions, gitAnalyzer *git.Analyzer, status *types.GitStatus, out io.Writer) (*CommitResult, error) {
+	files := append([]string{}, status.StagedFiles...)
+	renamedFrom := make(map[string]string)
+	for _, entry := range status.Entries {
+		if entry.Staged == "" {
+			continue
+		}
+		if entry.OrigPath != "" {
+			renamedFrom[entry.Path] = entry.OrigPath
+		}
+		// Restaging the file would take in the changes left out of the index
+		if entry.Unstaged != "" && !opts.StageAll {
+			return nil, fmt.Errorf("%s is only partly staged, which --per-file and --group-by-dir can't keep apart. Stage all of it or use --all", entry.Path)
+		}
+	}
+
+	if opts.StageAll {
+		toStage, skipped, err := filesToStage(gitAnalyzer, status)
+		if err != nil {
+			return nil, err
+		}
+		printSkippedFiles(out, skipped)
+		files = removeDuplicates(append(files, toStage...))
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no changes staged for commit. Use --all to stage all changes")
+	}
+
+	groups := groupChanges(files, renamedFrom, opts.Group)
+	fmt.Fprintf(out, "%s Planned %d commit(s):\n", ui.Note, len(groups))
+	for i, group := range groups {
+		fmt.Fprintf(out, "   %d. %s\n", i+1, group.Name)
+		if opts.Group == CommitGroupDir {
+			for _, path := range group.Paths {
+				fmt.Fprintf(out, "      %s\n", path)
+			}
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(out, "%s Dry run - would commit each group with its own generated message\n", ui.Search)
+		if opts.Push {
+			fmt.Fprintf(out, "%s Dry run - would push to %s\n", ui.Search, gitAnalyzer.PushTarget(status.CurrentBranch))
+		}
+		return &CommitResult{}, nil
+	}
+
+	if err := gitAnalyzer.UnstageAll(); err != nil {
+		return nil, err
+	}
+
+	result := &CommitResult{}
+	for i, group := range groups {
+		commit, err := commitGroupChanges(opts, out, gitAnalyzer, group)
+		if err != nil {
+			if restageErr := restageGroups(gitAnalyzer, groups[i:]); restageErr != nil {
+				return result, fmt.Errorf("%w\n%d of %d commits were made; the index was reset and restaging the remaining changes failed: %v", err, i, len(groups), restageErr)
+			}
+			if i > 0 {
+				return result, fmt.Errorf("%w\n%d of %d commits were made; the remaining changes are staged again but left uncommitted", err, i, len(groups))
+			}
+			return result, err
+		}
+		fmt.Fprintf(out, "%s [%d/%d] %s %s\n", ui.Success, i+1, len(groups), git.ShortHash(commit.Hash), strings.SplitN(commit.Message, "\n", 2)[0])
+		result.Commits = append(result.Commits, *commit)
+		result.Hash, result.Message = commit.Hash, commit.Message
+	}
+
+	if opts.Push {
+		fmt.Fprintf(out, "%s Pushing to remote...\n", ui.Rocket)
+		if err := gitAnalyzer.Push(); err != nil {
+			return result, err
+		}
+		fmt.Fprintf(out, "%s Changes pushed!\n", ui.Success)
+	}
+
+	return result, nil
+}
+
+// restageGroups stages the paths of the groups a failed run didn't commit, so
+// the index it emptied holds the uncommitted changes again
+func restageGroups(gitAnalyzer *git.Analyzer, groups []commitGroup) error {
+	var paths []string
+	for _, group := range groups {
+		paths = append(paths, group.Paths...)
+	}
+	return gitAnalyzer.StageFiles(paths)
+}
+
+// commitGroupChanges stages one group's paths and commits them with a
+// message generated from their diff alone
+func commitGroupChanges(opts CommitOptions, out io.Writer, gitAnalyzer *git.Analyzer, group commitGroup) (*CommitResult, error) {
+	if err := gitAnalyzer.StageFiles(group.Paths); err != nil {
+		return nil, err
+	}
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+
+	message, err := generateCommitMessage(gitAnalyzer, status, false, "", opts.Type, opts.Detailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate commit message for %s: %w", group.Name, err)
+	}
+
+	coAuthors := opts.CoAuthors
+	if opts.DetectCoAuthors {
+		detected, err := gitAnalyzer.GetRecentAuthors(status.StagedFiles, 20)
+		if err != nil {
+			fmt.Fprintf(out, "%s Failed to detect co-authors: %v\n", ui.Warning, err)
+		}
+		coAuthors = append(append([]string{}, coAuthors...), detected...)
+	}
+	message = appendCoAuthorTrailers(message, coAuthors)
+
+	hash, err := gitAnalyzer.Commit(message, false)
+	if err != nil {
+		return nil, err
+	}
+	return &CommitResult{Hash: hash, Message: message}, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+func TestGroupChanges(t *testing.T) {
+	files := []string{"cmd/root.go", "README.md", "internal/git/status.go", "cmd/commit.go", "internal/service/new.go"}
+	renamedFrom := map[string]string{"internal/service/new.go": "internal/service/old.go"}
+
+	tests := []struct {
+		name    string
+		groupBy string
+		want    []commitGroup
+	}{
+		{
+			name:    "per file",
+			groupBy: CommitGroupFile,
+			want: []commitGroup{
+				{Name: "README.md", Paths: []string{"README.md"}},
+				{Name: "cmd/commit.go", Paths: []string{"cmd/commit.go"}},
+				{Name: "cmd/root.go", Paths: []string{"cmd/root.go"}},
+				{Name: "internal/git/status.go", Paths: []string{"internal/git/status.go"}},
+				{Name: "internal/service/new.go", Paths: []string{"internal/service/new.go", "internal/service/old.go"}},
+			},
+		},
+		{
+			name:    "by top-level directory",
+			groupBy: CommitGroupDir,
+			want: []commitGroup{
+				{Name: ".", Paths: []string{"README.md"}},
+				{Name: "cmd", Paths: []string{"cmd/root.go", "cmd/commit.go"}},
+				{Name: "internal", Paths: []string{"internal/git/status.go", "internal/service/new.go", "internal/service/old.go"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupChanges(files, renamedFrom, tt.groupBy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommitGroup(t *testing.T) {
+	if err := validateCommitGroup(CommitOptions{Group: CommitGroupDir, StageAll: true, Push: true, Type: "fix"}); err != nil {
+		t.Errorf("validateCommitGroup() rejected compatible options: %v", err)
+	}
+	if err := validateCommitGroup(CommitOptions{Group: "module"}); err == nil {
+		t.Error("validateCommitGroup() accepted an unknown grouping")
+	}
+	err := validateCommitGroup(CommitOptions{Group: CommitGroupFile, Amend: true})
+	if err == nil || !strings.Contains(err.Error(), "--amend") {
+		t.Errorf("validateCommitGroup() error = %v, want one naming --amend", err)
+	}
+}
+
+func TestCommitPerFile(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("checkout", "-q", "-b", "feature/per-file")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "feat: add file"})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	// A dry run plans the commits without staging or asking the AI
+	if _, err := Commit(CommitOptions{RepoPath: dir, StageAll: true, Group: CommitGroupFile, DryRun: true, Out: io.Discard}); err != nil {
+		t.Fatalf("Commit() dry run error = %v", err)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("Commit() dry run made %d AI calls, want 0", len(calls))
+	}
+	if run("diff", "--cached", "--name-only") != "" {
+		t.Error("Commit() dry run staged changes")
+	}
+
+	result, err := Commit(CommitOptions{RepoPath: dir, StageAll: true, Group: CommitGroupFile, Out: io.Discard})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if len(result.Commits) != 2 || run("rev-list", "--count", "HEAD") != "3" {
+		t.Fatalf("Commit() made %d commits, want one per file", len(result.Commits))
+	}
+	if run("status", "--porcelain") != "" {
+		t.Error("Commit() left changes uncommitted")
+	}
+
+	// Each commit holds one file, and its message was generated from that file alone
+	calls := mock.Calls()
+	for i, name := range []string{"a.txt", "b.txt"} {
+		if files := run("show", "--name-only", "--format=", result.Commits[i].Hash); files != name {
+			t.Errorf("commit %d changed %q, want %s", i+1, files, name)
+		}
+		if i < len(calls) && len(calls[i].Context.FileChanges) != 1 {
+			t.Errorf("AI call %d saw %d files, want 1", i+1, len(calls[i].Context.FileChanges))
+		}
+	}
+	if len(calls) != 2 {
+		t.Errorf("Commit() made %d AI calls, want 2", len(calls))
+	}
+}
+
+func TestCommitPerFileFailureRestages(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("checkout", "-q", "-b", "feature/per-file")
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("add", "a.txt", "b.txt", "c.txt")
+
+	// The hook rejects the second commit
+	hook := "#!/bin/sh\ngit diff --cached --name-only | grep -q b.txt && exit 1\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(dir, ".git", "hooks", "pre-commit"), []byte(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "feat: add file"})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	result, err := Commit(CommitOptions{RepoPath: dir, Group: CommitGroupFile, Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 commits were made") {
+		t.Fatalf("Commit() error = %v, want one counting the commits made", err)
+	}
+	if result == nil || len(result.Commits) != 1 || run("rev-list", "--count", "HEAD") != "2" {
+		t.Fatalf("Commit() result = %+v, want the one commit made", result)
+	}
+	if staged := run("diff", "--cached", "--name-only"); staged != "b.txt\nc.txt" {
+		t.Errorf("Commit() left %q staged, want the uncommitted b.txt and c.txt", staged)
+	}
+}
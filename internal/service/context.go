@@ -0,0 +1,276 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/git"
+	"auto-pr/internal/platforms"
+	"auto-pr/internal/templates"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// ContextOptions configures PRContext
+type ContextOptions struct {
+	RepoPath   string
+	MaxCommits *int     // Commits included (0 means unlimited); nil uses git.commit_limit
+	Paths      []string // Limit the analysis to these paths
+	// IncludeGenerated keeps files .gitattributes marks as generated
+	IncludeGenerated bool
+}
+
+// PRContext assembles the context CreatePR sends to the AI, without calling
+// any provider
+func PRContext(opts ContextOptions) (*ai.AIContext, error) {
+	aiContext, _, _, err := loadPRContext(opts)
+	return aiContext, err
+}
+
+// ChangeAnalysis is the PR context together with the metadata create derives
+// from it, for callers that open the PR/MR themselves
+type ChangeAnalysis struct {
+	Context            *ai.AIContext
+	ChangeType         string   // Kind of change, e.g. feature or bugfix
+	SuggestedLabels    []string // The template and size labels create would add
+	BaseBranchInferred bool     // The base came from the branch's history rather than the configured default
+}
+
+// AnalyzeChanges assembles the PR context like PRContext and adds the change
+// type and labels create would pick, without calling any provider
+func AnalyzeChanges(opts ContextOptions) (*ChangeAnalysis, error) {
+	aiContext, cfg, inferred, err := loadPRContext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	if template := templates.SelectTemplateByContext(aiContext); template != "" {
+		labels = append(labels, template)
+	}
+	if sizeLabel := computeSizeLabel(aiContext.DiffSummary, cfg.Platforms.Labels.SizeThresholds); sizeLabel != "" {
+		labels = append(labels, sizeLabel)
+	}
+
+	return &ChangeAnalysis{
+		Context:            aiContext,
+		ChangeType:         templates.DetectChangeType(aiContext),
+		SuggestedLabels:    labels,
+		BaseBranchInferred: inferred,
+	}, nil
+}
+
+// loadPRContext builds the PR context of the repository at opts.RepoPath and
+// returns it with the configuration it used, reporting whether the base
+// branch was inferred from the history
+func loadPRContext(opts ContextOptions) (*ai.AIContext, *types.Config, bool, error) {
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	detectedBase := status.BaseBranch
+	inferBaseBranch(gitAnalyzer, status)
+
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	commitLimit := cfg.Git.CommitLimit
+	if opts.MaxCommits != nil {
+		commitLimit = *opts.MaxCommits
+	}
+	if opts.IncludeGenerated {
+		cfg.Git.IncludeGenerated = true
+	}
+
+	// The platform is only informational here, so an unknown remote is fine
+	platform, err := platforms.DetectPlatform(gitAnalyzer.GetRemoteURL())
+	if err != nil {
+		platform = ""
+	}
+
+	aiContext, err := buildPRContext(gitAnalyzer, status, platform, cfg.Git, commitLimit, opts.Paths)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return aiContext, cfg, status.BaseBranch != detectedBase, nil
+}
+
+// inferBaseBranch replaces the detected base branch with the common base
+// branch the current branch was actually forked from, so the diff does not
+// pick up commits that only exist on another base
+func inferBaseBranch(gitAnalyzer *git.Analyzer, status *types.GitStatus) {
+	candidates := removeDuplicates(append([]string{status.BaseBranch}, gitAnalyzer.BaseBranchCandidates()...))
+	if base, err := gitAnalyzer.InferBaseBranch(candidates); err == nil {
+		setBaseBranch(gitAnalyzer, status, base)
+	}
+}
+
+// setBaseBranch points the analysis at base, refreshing the commit counts
+func setBaseBranch(gitAnalyzer *git.Analyzer, status *types.GitStatus, base string) {
+	if base == status.BaseBranch {
+		return
+	}
+	status.BaseBranch = base
+	status.CommitsAhead, status.CommitsBehind, _ = gitAnalyzer.CommitCounts(base)
+}
+
+// buildPRContext gathers the commits and file changes since the base branch.
+// Commits excluded by the git config are left out of the history, but their
+// changes still show in the diff.
+func buildPRContext(gitAnalyzer *git.Analyzer, status *types.GitStatus, platform types.PlatformType, gitCfg types.GitConfig, commitLimit int, paths []string) (*ai.AIContext, error) {
+	commits, err := commitsSinceBase(gitAnalyzer, status.BaseBranch, gitCfg, commitLimit, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	diffSummary, err := gitAnalyzer.GetBranchDiffForPaths(status.BaseBranch, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff summary: %w", err)
+	}
+
+	if len(paths) > 0 && len(commits) == 0 && len(diffSummary.FileChanges) == 0 {
+		return nil, fmt.Errorf("no changes under %s since %s", strings.Join(paths, ", "), status.BaseBranch)
+	}
+
+	// Generated files still count in the totals but only add noise as changes
+	summary := fmt.Sprintf("%d files changed, %d additions, %d deletions",
+		diffSummary.TotalFiles, diffSummary.Additions, diffSummary.Deletions)
+	fileChanges := diffSummary.FileChanges
+	if !gitCfg.IncludeGenerated {
+		var generated int
+		fileChanges, generated = withoutGeneratedFiles(gitAnalyzer, fileChanges)
+		if generated > 0 {
+			summary += fmt.Sprintf(" (%d generated files not listed)", generated)
+		}
+	}
+	// Lockfiles still count in the totals, but their changes only waste context
+	fileChanges, excluded := git.ExcludeFileChanges(fileChanges, gitCfg.ContextExcludePatterns)
+	if note := excludedNote(excluded); note != "" {
+		summary += fmt.Sprintf(" (%s)", note)
+	}
+
+	return &ai.AIContext{
+		CommitHistory: commits,
+		DiffSummary:   summary,
+		FileChanges:   fileChanges,
+		BranchInfo: types.BranchInfo{
+			Name:         status.CurrentBranch,
+			BaseBranch:   status.BaseBranch,
+			CommitsAhead: status.CommitsAhead,
+		},
+		Platform: platform,
+	}, nil
+}
+
+// excludedNote tells the AI which files git.context_exclude_patterns left
+// out of the context, or returns "" when none were
+func excludedNote(excluded []string) string {
+	const maxListed = 5
+	if len(excluded) == 0 {
+		return ""
+	}
+	if len(excluded) > maxListed {
+		return fmt.Sprintf("lockfiles updated: %s and %d more", strings.Join(excluded[:maxListed], ", "), len(excluded)-maxListed)
+	}
+	return "lockfiles updated: " + strings.Join(excluded, ", ")
+}
+
+// withoutGeneratedFiles leaves out the changes to files .gitattributes marks
+// linguist-generated, returning the rest and how many were left out. The
+// changes are kept as they are when .gitattributes can't be read.
+func withoutGeneratedFiles(gitAnalyzer *git.Analyzer, changes []types.FileChange) ([]types.FileChange, int) {
+	paths := make([]string, len(changes))
+	for i, change := range changes {
+		paths[i] = change.Path
+	}
+
+	generated, err := gitAnalyzer.GeneratedFiles(paths)
+	if err != nil || len(generated) == 0 {
+		return changes, 0
+	}
+
+	kept := make([]types.FileChange, 0, len(changes)-len(generated))
+	for _, change := range changes {
+		if !generated[change.Path] {
+			kept = append(kept, change)
+		}
+	}
+	return kept, len(changes) - len(kept)
+}
+
+// commitsSinceBase returns up to limit commits since the base branch (0 means
+// unlimited), after leaving out those excluded by the git config
+func commitsSinceBase(gitAnalyzer *git.Analyzer, baseBranch string, gitCfg types.GitConfig, limit int, paths []string) ([]types.CommitInfo, error) {
+	excluding := len(gitCfg.ExcludeCommitAuthors) > 0 || len(gitCfg.ExcludeCommitPatterns) > 0
+
+	// Exclusions must apply before the limit, or they would shrink it
+	fetchLimit := limit
+	if excluding {
+		fetchLimit = 0
+	}
+
+	commits, err := gitAnalyzer.GetCommitsSinceBaseForPaths(baseBranch, fetchLimit, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit history: %w", err)
+	}
+	if !excluding {
+		return commits, nil
+	}
+
+	commits, err = git.ExcludeCommits(commits, gitCfg.ExcludeCommitAuthors, gitCfg.ExcludeCommitPatterns)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, nil
+}
+
+// PrintAIContext writes the AI context in readable form
+func PrintAIContext(w io.Writer, ctx *ai.AIContext) {
+	fmt.Fprintf(w, "%s AI Context\n", ui.Brain)
+	fmt.Fprintln(w, "=============")
+
+	fmt.Fprintf(w, "%s Branch: %s -> %s (%d commits ahead)\n", ui.Branch,
+		ctx.BranchInfo.Name, ctx.BranchInfo.BaseBranch, ctx.BranchInfo.CommitsAhead)
+	if ctx.Platform != "" {
+		fmt.Fprintf(w, "%s Platform: %s\n", ui.Globe, ctx.Platform)
+	}
+	if ctx.DiffSummary != "" {
+		fmt.Fprintf(w, "%s Summary: %s\n", ui.Chart, ctx.DiffSummary)
+	}
+
+	fmt.Fprintf(w, "\n%s Commits (%d):\n", ui.Note, len(ctx.CommitHistory))
+	for _, commit := range ctx.CommitHistory {
+		fmt.Fprintf(w, "   %s %s (%s)\n", git.ShortHash(commit.Hash), commit.Message, commit.Author)
+	}
+
+	fmt.Fprintf(w, "\n%s Files (%d):\n", ui.Folder, len(ctx.FileChanges))
+	for _, fc := range ctx.FileChanges {
+		fmt.Fprintf(w, "   %s (%s): +%d -%d\n", fc.Path, fc.Status, fc.Additions, fc.Deletions)
+	}
+
+	project := ctx.ProjectContext
+	if project.Language != "" {
+		fmt.Fprintf(w, "\n%s Project: %s", ui.Building, project.Language)
+		if project.Framework != "" {
+			fmt.Fprintf(w, " (%s)", project.Framework)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if ctx.DiffContent != "" {
+		fmt.Fprintf(w, "\n%s Diff (%d bytes):\n%s\n", ui.File, len(ctx.DiffContent), ctx.DiffContent)
+	}
+}
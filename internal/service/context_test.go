@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/git"
+	"auto-pr/pkg/types"
+)
+
+func TestPrintAIContext(t *testing.T) {
+	ctx := &ai.AIContext{
+		CommitHistory: []types.CommitInfo{
+			{Hash: "4eabff4c0b1e2d3f", Message: "feat: add export", Author: "Alice"},
+		},
+		DiffSummary: "1 files changed, 10 additions, 2 deletions",
+		FileChanges: []types.FileChange{
+			{Path: "export.go", Status: types.StatusModified, Additions: 10, Deletions: 2},
+		},
+		BranchInfo: types.BranchInfo{Name: "feature/export", BaseBranch: "main", CommitsAhead: 1},
+		Platform:   types.PlatformGitHub,
+	}
+
+	var buf bytes.Buffer
+	PrintAIContext(&buf, ctx)
+	got := buf.String()
+
+	for _, want := range []string{
+		"🌿 Branch: feature/export -> main (1 commits ahead)",
+		"🌐 Platform: github",
+		"📊 Summary: 1 files changed, 10 additions, 2 deletions",
+		"📝 Commits (1):\n   4eabff4c feat: add export (Alice)",
+		"📁 Files (1):\n   export.go (modified): +10 -2",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("PrintAIContext() output missing %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "Diff (") {
+		t.Errorf("PrintAIContext() printed an empty diff section:\n%s", got)
+	}
+}
+
+func TestWithoutGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.pb.go linguist-generated\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+	gitAnalyzer, err := git.NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+
+	changes := []types.FileChange{{Path: "api.go"}, {Path: "api/types.pb.go"}, {Path: "main.go"}}
+	kept, generated := withoutGeneratedFiles(gitAnalyzer, changes)
+
+	if generated != 1 || len(kept) != 2 || kept[0].Path != "api.go" || kept[1].Path != "main.go" {
+		t.Errorf("withoutGeneratedFiles() = %v, %d; want api.go and main.go with 1 left out", kept, generated)
+	}
+}
+
+func TestExcludedNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		excluded []string
+		want     string
+	}{
+		{name: "None", want: ""},
+		{name: "Listed", excluded: []string{"go.sum", "web/yarn.lock"}, want: "lockfiles updated: go.sum, web/yarn.lock"},
+		{name: "Many", excluded: []string{"a/go.sum", "b/go.sum", "c/go.sum", "d/go.sum", "e/go.sum", "f/go.sum", "g/go.sum"},
+			want: "lockfiles updated: a/go.sum, b/go.sum, c/go.sum, d/go.sum, e/go.sum and 2 more"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excludedNote(tt.excluded); got != tt.want {
+				t.Errorf("excludedNote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Keep the user's config out of the run
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	run("checkout", "-q", "-b", "fix/empty-input")
+	if err := os.WriteFile(filepath.Join(dir, "parse.go"), []byte("package parse\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "parse.go")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "fix: handle empty input")
+
+	analysis, err := AnalyzeChanges(ContextOptions{RepoPath: dir})
+	if err != nil {
+		t.Fatalf("AnalyzeChanges() error = %v", err)
+	}
+
+	if analysis.ChangeType != "bugfix" {
+		t.Errorf("ChangeType = %q, want bugfix", analysis.ChangeType)
+	}
+	if want := []string{"bugfix", "size/S"}; !reflect.DeepEqual(analysis.SuggestedLabels, want) {
+		t.Errorf("SuggestedLabels = %v, want %v", analysis.SuggestedLabels, want)
+	}
+	if analysis.BaseBranchInferred {
+		t.Error("BaseBranchInferred = true, want false when the default base is right")
+	}
+	if got := analysis.Context.BranchInfo.BaseBranch; got != "main" {
+		t.Errorf("BaseBranch = %q, want main", got)
+	}
+	if len(analysis.Context.CommitHistory) != 1 || len(analysis.Context.FileChanges) != 1 {
+		t.Errorf("Context = %d commits, %d files; want 1 of each",
+			len(analysis.Context.CommitHistory), len(analysis.Context.FileChanges))
+	}
+}
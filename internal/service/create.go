@@ -0,0 +1,1106 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/git"
+	"auto-pr/internal/ownership"
+	"auto-pr/internal/platforms"
+	"auto-pr/internal/templates"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// CreatePROptions configures CreatePR
+type CreatePROptions struct {
+	RepoPath             string
+	Template             string
+	Reviewers            []string
+	SuggestReviewers     bool // Replace the AI's reviewer suggestions with code owners
+	CodeownerReviewers   bool // Add the CODEOWNERS owners of the changed files as reviewers
+	Draft                bool
+	AutoMerge            bool
+	MaxCommits           *int     // Commits fed to the AI (0 means unlimited); nil uses git.commit_limit
+	Head                 string   // Head branch, as branch or owner:branch for a fork; a local branch is described instead of HEAD
+	Upstream             string   // Remote whose repository the PR/MR targets
+	Paths                []string // Limit the analysis to these paths (e.g. a monorepo subproject)
+	IncludeGenerated     bool     // Keep files .gitattributes marks as generated in the AI context
+	NoStat               bool     // Skip the per-file line counts, which are slow to gather in very large repositories
+	AutoLogin            bool
+	AmendPR              bool
+	SyncMetadata         bool      // Add missing labels and reviewers to an existing PR/MR
+	Recreate             bool      // Close an existing PR/MR and open a new one in its place
+	Yes                  bool      // Recreate without asking for confirmation
+	UseRepoTemplate      bool      // Fill the repository's own PR/MR template instead of a built-in one
+	Stacked              bool      // Target the parent branch in a stack instead of the base branch
+	Chain                bool      // First create PRs/MRs for the branches below this one in the stack; implies Stacked
+	Interactive          bool      // Pick labels and reviewers and confirm before creating
+	Push                 bool      // Push the branch without asking if it isn't on the remote yet
+	Refine               bool      // Have the AI critique and improve its first draft (ai.refine_iterations rounds)
+	PostDetails          bool      // Post the file list and any cut-off description as the first comment
+	NoAI                 bool      // Describe the changes from commits and file changes without calling the AI
+	Fill                 bool      // Take the title and body straight from the commit messages, with no AI or template
+	SinceTag             string    // Describe everything since the latest tag matching this pattern as release notes
+	Title                string    // Use this title instead of the generated one
+	TitlePrefix          string    // Put this in front of the title, e.g. a ticket key like [JIRA-123]
+	Type                 string    // Conventional commit type (feat, fix, ...) that picks the template instead of detection
+	Issue                string    // Issue the PR/MR addresses (123, #123 or its URL), whose description the AI checks the changes against
+	In                   io.Reader // Answers for interactive prompts; defaults to standard input
+	RequirePassingCI     bool
+	RequirePassingChecks bool
+	DryRun               bool
+	PreviewFormat        string // Dry-run preview format: plain (default) or markdown
+	Verbose              bool
+	Out                  io.Writer
+}
+
+// CreatePRResult describes the outcome of CreatePR. PullRequest is nil in
+// dry-run mode; Existing is set when a PR/MR was already open for the branch.
+type CreatePRResult struct {
+	PullRequest *types.PullRequest
+	Content     *ai.AIResponse
+	Existing    bool
+}
+
+// CreatePR analyzes the branch and creates a PR/MR with AI-generated content,
+// or with AmendPR appends new commits to the existing one
+func CreatePR(opts CreatePROptions) (*CreatePRResult, error) {
+	out := output(opts.Out)
+	verbose := opts.Verbose
+
+	// Reject a bad format before spending an AI call on the preview
+	if err := ValidatePreviewFormat(opts.PreviewFormat); err != nil {
+		return nil, err
+	}
+	if err := ValidateCommitType(opts.Type); err != nil {
+		return nil, err
+	}
+	if err := validateFill(opts); err != nil {
+		return nil, err
+	}
+	if opts.Recreate && (opts.AmendPR || opts.SyncMetadata) {
+		return nil, fmt.Errorf("--recreate replaces the existing PR/MR and can't be combined with --amend-pr or --sync-metadata")
+	}
+	issueNumber := 0
+	if opts.Issue != "" {
+		var err error
+		if issueNumber, err = parseIssueNumber(opts.Issue); err != nil {
+			return nil, err
+		}
+	}
+
+	if verbose {
+		fmt.Fprintln(out, "Starting Auto PR creation...")
+	}
+
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	gitAnalyzer.SetSkipFileStats(opts.NoStat)
+
+	// Detect platform (GitHub/GitLab)
+	platform, err := platforms.DetectPlatform(gitAnalyzer.GetRemoteURL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "Detected platform: %s\n", platform)
+	}
+
+	// Describe a local branch other than the checked-out one from its commits
+	headBranch := localHeadBranch(opts.Head)
+	if headBranch != "" {
+		if opts.Chain {
+			return nil, fmt.Errorf("--chain checks out the branches below the current one; run it on %s instead of passing --head", headBranch)
+		}
+		if err := gitAnalyzer.SetHeadBranch(headBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get repository status
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	checkedOut := status.CurrentBranch
+	if headBranch != "" {
+		// Uncommitted changes, and where HEAD is, don't matter for another branch
+		status.CurrentBranch, status.DetachedHead = headBranch, false
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "Repository status: %+v\n", status)
+	}
+
+	// A PR/MR needs a branch to push and open it from
+	if status.DetachedHead {
+		return nil, git.ErrDetachedHead
+	}
+
+	// Target the branch this one is stacked on, or else the base it forked from
+	if opts.Stacked || opts.Chain {
+		if opts.Chain {
+			if err := createStackParents(opts, gitAnalyzer, status); err != nil {
+				return nil, err
+			}
+		}
+
+		parent, err := gitAnalyzer.StackParent(status.CurrentBranch, status.BaseBranch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the parent of %s: %w", status.CurrentBranch, err)
+		}
+		if parent != status.BaseBranch {
+			fmt.Fprintf(out, "%s Stacked on: %s\n", ui.Branch, parent)
+		}
+		setBaseBranch(gitAnalyzer, status, parent)
+	} else {
+		inferBaseBranch(gitAnalyzer, status)
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "Base branch: %s\n", status.BaseBranch)
+	}
+
+	// Work out which repository the PR/MR targets and where the head lives
+	target, err := resolvePRTarget(gitAnalyzer, status, opts.Head, opts.Upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail here rather than with the platform's opaque error after the AI call
+	if err := checkPRBranches(target, status.BaseBranch); err != nil {
+		return nil, err
+	}
+	if headBranch != "" && status.CommitsAhead == 0 {
+		return nil, fmt.Errorf("%s has no commits ahead of %s, so there is nothing to open a PR/MR for", headBranch, status.BaseBranch)
+	}
+
+	// Refresh an existing PR/MR instead of creating a new one
+	if opts.AmendPR {
+		return amendPR(opts, platform, status, gitAnalyzer, target)
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Create AI client, describing the changes without one when it's unavailable
+	var aiClient ai.AIClient
+	if opts.NoAI || opts.Fill {
+		aiClient = newDeterministicClient()
+	} else if aiClient, err = ai.NewClient(cfg.AI); err != nil {
+		fmt.Fprintf(out, "%s AI unavailable (%v), describing the changes from the commits instead\n", ui.Warning, err)
+		aiClient = newDeterministicClient()
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "Using AI provider: %s\n", aiClient.GetProvider())
+	}
+
+	// Get commit history and changes for AI context
+	commitLimit := cfg.Git.CommitLimit
+	if opts.MaxCommits != nil {
+		commitLimit = *opts.MaxCommits
+	}
+	if opts.Fill {
+		// The title comes from the first commit, so every commit is needed
+		commitLimit = 0
+	}
+	if opts.IncludeGenerated {
+		cfg.Git.IncludeGenerated = true
+	}
+
+	// A release PR/MR covers everything since the last tag, not just the branch
+	contextStatus, releaseTag := status, ""
+	if opts.SinceTag != "" {
+		releaseTag, err = gitAnalyzer.GetLatestTag(opts.SinceTag)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(out, "%s Describing changes since tag: %s\n", ui.Label, releaseTag)
+		contextStatus = sinceTagStatus(gitAnalyzer, status, releaseTag)
+	}
+
+	aiContext, err := buildPRContext(gitAnalyzer, contextStatus, platform, cfg.Git, commitLimit, opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	aiContext.ExtraFields = cfg.AI.ExtraFields
+	aiContext.ChangeType = opts.Type
+	if issueNumber > 0 {
+		aiContext.Issue = fetchIssue(out, platform, target.RemoteURL, issueNumber)
+	}
+
+	if verbose && len(opts.Paths) > 0 {
+		fmt.Fprintf(out, "Scoped analysis to: %s\n", strings.Join(opts.Paths, ", "))
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "AI Context: %d commits, %d file changes\n",
+			len(aiContext.CommitHistory), len(aiContext.FileChanges))
+	}
+
+	// Respect the team's own template when asked, falling back to ours
+	repoTemplatePath, repoTemplate := "", ""
+	if opts.UseRepoTemplate {
+		repoTemplatePath, repoTemplate = templates.FindRepoTemplate(gitAnalyzer.RepoPath())
+		if repoTemplatePath == "" {
+			fmt.Fprintf(out, "%s No PR/MR template found in the repository, using the built-in templates\n", ui.Warning)
+		}
+	}
+
+	// Generate PR content using AI
+	prompt := "Generate a comprehensive pull request title and description based on the provided git changes and commit history."
+	if releaseTag != "" {
+		prompt = releasePrompt(releaseTag)
+	}
+	if repoTemplate != "" {
+		prompt += "\n\nStructure the description with the same markdown headings as this repository's PR template:\n\n" + repoTemplate
+	}
+	var aiResponse *ai.AIResponse
+	if opts.Fill {
+		if aiResponse, err = fillFromCommits(aiContext.CommitHistory); err != nil {
+			return nil, err
+		}
+	} else if aiResponse, err = aiClient.GenerateContent(aiContext, prompt); err != nil {
+		return nil, fmt.Errorf("failed to generate AI content: %w", err)
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "AI generated content (confidence: %.2f)\n", aiResponse.Confidence)
+	}
+
+	// Trade extra AI calls for a draft that has been critiqued and improved
+	if opts.Refine && aiClient.GetProvider() != types.AIProviderNone {
+		draft := aiResponse
+		fmt.Fprintf(out, "%s Refining the description (up to %d rounds)...\n", ui.Robot, cfg.AI.RefineIterations)
+		aiResponse, err = ai.Refine(aiClient, aiContext, draft, cfg.AI.RefineIterations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refine AI content: %w", err)
+		}
+		if verbose {
+			printRefinement(out, draft, aiResponse)
+		}
+	}
+
+	// The AI has no real signal about ownership, so prefer CODEOWNERS and history
+	if opts.SuggestReviewers {
+		suggester := ownership.NewSuggester(gitAnalyzer.RepoPath(), cfg.Git.CodeownersPath)
+		if suggested := suggester.SuggestReviewers(aiContext.FileChanges); len(suggested) > 0 {
+			aiResponse.Reviewers = suggested
+		} else if verbose {
+			fmt.Fprintln(out, "No code owners found for the changed files, keeping AI suggestions")
+		}
+	}
+
+	// Fill in the team's own template, whose headings the AI was asked to use
+	if repoTemplate != "" {
+		aiResponse.Body = templates.FillRepoTemplate(repoTemplate, aiResponse.Body)
+		if verbose {
+			fmt.Fprintf(out, "Applied repository template: %s\n", repoTemplatePath)
+		}
+	}
+
+	// Path rules from the config pick a template and labels for parts of a monorepo
+	pathRule := templates.MatchPathRule(cfg.Templates.PathRules, aiContext.FileChanges)
+	if pathRule != nil && verbose {
+		fmt.Fprintf(out, "Matched path rule: %s\n", pathRule.Match)
+	}
+
+	// Apply template if specified, unless the repository's own one was used or
+	// the commit messages are wanted as they are
+	if repoTemplate == "" && !opts.Fill {
+		templateManager, err := templates.NewManager()
+		if err != nil {
+			fmt.Fprintf(out, "%s Templates unavailable, using the generated content as is: %v\n", ui.Warning, err)
+		} else if templateName := opts.Template; templateName != "" {
+			enhanced, err := templates.EnhanceWithTemplate(templateManager, templateName, aiContext, aiResponse)
+			if err != nil {
+				if verbose {
+					fmt.Fprintf(out, "Warning: failed to apply template '%s': %v\n", templateName, err)
+				}
+			} else {
+				aiResponse = enhanced
+				if verbose {
+					fmt.Fprintf(out, "Applied template: %s\n", templateName)
+				}
+			}
+		} else if pathRule != nil && pathRule.Template != "" {
+			enhanced, err := templates.EnhanceWithTemplate(templateManager, pathRule.Template, aiContext, aiResponse)
+			if err != nil {
+				fmt.Fprintf(out, "%s Failed to apply template '%s' from path rule %s: %v\n", ui.Warning, pathRule.Template, pathRule.Match, err)
+			} else {
+				aiResponse = enhanced
+				if verbose {
+					fmt.Fprintf(out, "Applied template from path rule: %s\n", pathRule.Template)
+				}
+			}
+		} else if releaseTag == "" {
+			// Auto-select template based on context; release notes keep their own layout
+			autoTemplate := templates.SelectTemplateByContext(aiContext)
+			if autoTemplate != "" {
+				enhanced, err := templates.EnhanceWithTemplate(templateManager, autoTemplate, aiContext, aiResponse)
+				if err == nil {
+					aiResponse = enhanced
+					if verbose {
+						fmt.Fprintf(out, "Auto-selected template: %s\n", autoTemplate)
+					}
+				}
+			}
+		}
+	}
+
+	// The rule's labels go through the same checks as the AI's suggestions
+	if pathRule != nil {
+		aiResponse.Labels = append(aiResponse.Labels, pathRule.Labels...)
+	}
+
+	// The team's defaults for this kind of change, such as an urgent label
+	// and the on-call reviewer for hotfixes
+	changeType := templates.ResolveChangeType(aiContext)
+	if defaults, ok := cfg.TypeDefaults[changeType]; ok {
+		aiResponse.Labels = removeDuplicates(append(aiResponse.Labels, defaults.Labels...))
+		aiResponse.Reviewers = removeDuplicates(append(aiResponse.Reviewers, defaults.Reviewers...))
+		if verbose {
+			fmt.Fprintf(out, "Applied type_defaults for %s changes\n", changeType)
+		}
+	}
+
+	// Label the size for triage, unless a size label was already picked
+	sizeThresholds := cfg.Platforms.Labels.SizeThresholds
+	if sizeLabel := computeSizeLabel(aiContext.DiffSummary, sizeThresholds); sizeLabel != "" && !hasSizeLabel(aiResponse.Labels, sizeThresholds) {
+		aiResponse.Labels = append(aiResponse.Labels, sizeLabel)
+	}
+
+	// List every change since the tag, not just the commits the AI was shown
+	if releaseTag != "" {
+		commits, err := gitAnalyzer.GetCommitsSinceTag(releaseTag, 0)
+		if err != nil {
+			return nil, err
+		}
+		commits, err = git.ExcludeCommits(commits, cfg.Git.ExcludeCommitAuthors, cfg.Git.ExcludeCommitPatterns)
+		if err != nil {
+			return nil, err
+		}
+		aiResponse.Body = strings.TrimRight(aiResponse.Body, "\n") + "\n\n" + releaseNotes(releaseTag, commits)
+	}
+
+	// Titles given on the command line win; the body stays generated
+	aiResponse.Title = overrideTitle(aiResponse.Title, opts.Title, opts.TitlePrefix)
+
+	// The AI sometimes writes a sentence for a title; keep the end readable
+	title, overflow := normalizeTitle(aiResponse.Title, cfg.Platforms.TitleMaxLength)
+	if overflow != "" {
+		aiResponse.Body = "…" + overflow + "\n\n" + aiResponse.Body
+		if verbose {
+			fmt.Fprintf(out, "Shortened the title to %d characters\n", cfg.Platforms.TitleMaxLength)
+		}
+	}
+	aiResponse.Title = title
+
+	result := &CreatePRResult{Content: aiResponse}
+
+	if opts.DryRun {
+		// Markdown previews are meant to be pasted or redirected, so skip the banner
+		if opts.PreviewFormat != PreviewFormatMarkdown {
+			fmt.Fprintf(out, "%s Dry Run - PR/MR Preview\n", ui.Search)
+			fmt.Fprintln(out, "==========================")
+			if target.HeadRepo != "" {
+				fmt.Fprintf(out, "%s Head: %s:%s -> %s\n", ui.Merge, target.HeadRepo, target.HeadBranch, target.RemoteURL)
+			}
+		}
+		if err := PrintPRPreview(out, aiResponse, opts.PreviewFormat); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	// Prompts share one reader so buffered answers aren't lost between them;
+	// bufio.NewReader hands later callers the same one back
+	in := bufio.NewReader(input(opts.In))
+	opts.In = in
+
+	// Create platform client
+	platformClient, err := newPlatformClient(platform, target.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureAuthenticated(in, out, platformClient, opts.AutoLogin); err != nil {
+		return nil, err
+	}
+
+	// Filter AI-suggested labels to only those that exist in the repository,
+	// so we don't attempt to apply a label that hasn't been created yet.
+	labels, err := platforms.FilterExistingLabels(platformClient, aiResponse.Labels)
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(out, "Warning: failed to verify labels, skipping: %v\n", err)
+		}
+		labels = []string{}
+	}
+
+	reviewers := aiResponse.Reviewers
+	reviewers = append(reviewers, opts.Reviewers...)
+	if len(cfg.Platforms.GitHub.DefaultReviewers) > 0 && platform == types.PlatformGitHub {
+		reviewers = append(reviewers, cfg.Platforms.GitHub.DefaultReviewers...)
+	}
+	// Request the code owners up front, for drafts and for GitLab, which won't
+	if opts.CodeownerReviewers || useCodeowners(cfg.Platforms, platform) {
+		suggester := ownership.NewSuggester(gitAnalyzer.RepoPath(), cfg.Git.CodeownersPath)
+		owners := suggester.CodeOwners(aiContext.FileChanges)
+		if verbose {
+			fmt.Fprintf(out, "Code owners of the changed files: %s\n", strings.Join(owners, ", "))
+		}
+		reviewers = append(reviewers, owners...)
+	}
+
+	labels = removeDuplicates(labels)
+	reviewers = removeDuplicates(reviewers)
+
+	// Check for existing PR/MR
+	existingPR, err := platformClient.GetExistingPR(target.HeadBranch)
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(out, "Warning: failed to check for existing PR: %s\n", err)
+		}
+	}
+
+	// The existing PR/MR is only closed once the new one is about to be created
+	var replaced *types.PullRequest
+	if existingPR != nil && opts.Recreate {
+		if !confirmRecreate(opts, in, out, existingPR, getEntityName(platform)) {
+			fmt.Fprintf(out, "%s Cancelled\n", ui.Failure)
+			result.PullRequest = existingPR
+			result.Existing = true
+			return result, nil
+		}
+		replaced, existingPR = existingPR, nil
+	}
+
+	if existingPR != nil {
+		fmt.Fprintf(out, "%s A PR/MR already exists for branch '%s': %s\n", ui.Warning,
+			target.HeadBranch, existingPR.URL)
+		result.PullRequest = existingPR
+		result.Existing = true
+		if opts.SyncMetadata {
+			// A PR/MR keeps the size label it has rather than gain a second one
+			if hasSizeLabel(existingPR.Labels, sizeThresholds) {
+				labels = withoutSizeLabels(labels, sizeThresholds)
+			}
+			if err := syncPRMetadata(out, platformClient, existingPR, labels, reviewers); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	// Check CI results on the branch before opening the PR/MR
+	switch client := platformClient.(type) {
+	case *platforms.GitLabClient:
+		if err := checkPipelineStatus(out, client, target.HeadBranch, opts.RequirePassingCI, verbose); err != nil {
+			return nil, err
+		}
+	case *platforms.GitHubClient:
+		if err := checkRunsStatus(out, client, target, opts.RequirePassingChecks, verbose); err != nil {
+			return nil, err
+		}
+	}
+
+	// Let the user choose from the repository's real labels and reviewers
+	if opts.Interactive {
+		labels, reviewers = pickLabelsAndReviewers(in, out, platformClient, labels, reviewers)
+
+		fmt.Fprintf(out, "\n%s Title: %s\n", ui.Note, aiResponse.Title)
+		if !confirm(in, out, fmt.Sprintf("%s Create this %s?", ui.Rocket, getEntityName(platform))) {
+			fmt.Fprintf(out, "%s Cancelled\n", ui.Failure)
+			return result, nil
+		}
+	}
+
+	// The platform rejects descriptions over its limit, so cut the body short
+	note := truncatedBodyNote
+	if opts.PostDetails {
+		note = truncatedBodyDetailsNote
+	}
+	// The marker tells --amend-pr and watch which commits the body describes
+	marker := ""
+	if head, err := gitAnalyzer.HeadBranchHash(); err == nil {
+		marker = describedCommitMarker(head)
+	}
+	limit := maxBodyLength[platform]
+	if limit > 0 {
+		limit -= len(marker)
+	}
+	body, omittedBody := fitBody(aiResponse.Body, limit, fmt.Sprintf(note, getEntityName(platform)))
+	if omittedBody != "" {
+		fmt.Fprintf(out, "%s Description is too long for a %s, cutting %d bytes\n", ui.Warning, getEntityName(platform), len(omittedBody))
+	}
+	body = strings.TrimRight(body, "\n") + marker
+
+	// Create PR request
+	prRequest := &types.PullRequestRequest{
+		Title:      aiResponse.Title,
+		Body:       body,
+		HeadBranch: target.HeadBranch,
+		HeadRepo:   target.HeadRepo,
+		BaseBranch: status.BaseBranch,
+		Draft:      opts.Draft,
+		Labels:     labels,
+		Reviewers:  reviewers,
+		AutoMerge:  opts.AutoMerge,
+	}
+
+	// The platform allows one open PR/MR per branch, so close the old one first
+	if replaced != nil {
+		if err := platformClient.ClosePullRequest(replaced.Number); err != nil {
+			return nil, fmt.Errorf("failed to close #%d: %w", replaced.Number, err)
+		}
+		fmt.Fprintf(out, "%s Closed #%d: %s\n", ui.Success, replaced.Number, replaced.URL)
+	}
+
+	// Create the PR/MR
+	fmt.Fprintf(out, "%s Creating PR/MR...\n", ui.Rocket)
+	createdPR, err := platformClient.CreatePullRequest(prRequest)
+	// Only the checked-out branch is pushed for the user; another one gets the hint
+	if errors.Is(err, platforms.ErrBranchNotPushed) && target.HeadBranch == checkedOut {
+		pushed, pushErr := pushForPR(opts, out, gitAnalyzer, cfg.Git, target.HeadBranch)
+		if pushErr != nil {
+			return nil, pushErr
+		}
+		if pushed {
+			fmt.Fprintf(out, "%s Creating PR/MR again...\n", ui.Rocket)
+			createdPR, err = platformClient.CreatePullRequest(prRequest)
+		}
+	}
+	if err != nil {
+		if hint := createFailureHint(err, prRequest); hint != "" {
+			fmt.Fprintf(out, "%s %s\n", ui.Tip, hint)
+		}
+		if replaced != nil {
+			fmt.Fprintf(out, "%s #%d stays closed; reopen it if you still need it: %s\n", ui.Warning, replaced.Number, replaced.URL)
+		}
+		return nil, fmt.Errorf("failed to create PR/MR: %w", err)
+	}
+
+	fmt.Fprintf(out, "%s Successfully created %s: %s\n", ui.Success,
+		getEntityName(platform), createdPR.URL)
+	fmt.Fprintf(out, "%s Title: %s\n", ui.Note, createdPR.Title)
+	if createdPR.Draft {
+		fmt.Fprintf(out, "%s Status: Draft\n", ui.Clipboard)
+	}
+
+	// The PR/MR exists either way, so a failed comment only warns
+	if opts.PostDetails {
+		comment := detailsComment(aiContext.FileChanges, omittedBody, maxBodyLength[platform])
+		if err := platformClient.AddComment(createdPR.Number, comment); err != nil {
+			fmt.Fprintf(out, "%s Failed to post the details comment: %v\n", ui.Warning, err)
+		} else {
+			fmt.Fprintf(out, "%s Posted the file list as the first comment\n", ui.Note)
+		}
+	}
+
+	result.PullRequest = createdPR
+	return result, nil
+}
+
+// printRefinement shows the first draft next to its refined version
+func printRefinement(out io.Writer, draft, refined *ai.AIResponse) {
+	if draft.Title == refined.Title && draft.Body == refined.Body {
+		fmt.Fprintln(out, "Refinement kept the first draft unchanged")
+		return
+	}
+	fmt.Fprintf(out, "Before refinement:\n  Title: %s\n%s\n\n", draft.Title, draft.Body)
+	fmt.Fprintf(out, "After refinement:\n  Title: %s\n%s\n", refined.Title, refined.Body)
+}
+
+// syncPRMetadata adds the labels and reviewers the existing PR/MR lacks,
+// leaving the ones it already has, and reports what it added
+func syncPRMetadata(out io.Writer, client platforms.PlatformClient, pr *types.PullRequest, labels, reviewers []string) error {
+	newLabels := missingValues(pr.Labels, labels)
+	newReviewers := missingValues(pr.Reviewers, reviewers)
+	if len(newLabels) == 0 && len(newReviewers) == 0 {
+		fmt.Fprintf(out, "%s Labels and reviewers are already up to date\n", ui.Success)
+		return nil
+	}
+
+	if err := client.AddLabels(pr.Number, newLabels); err != nil {
+		return fmt.Errorf("failed to sync labels: %w", err)
+	}
+	if len(newLabels) > 0 {
+		fmt.Fprintf(out, "%s Added labels: %s\n", ui.Label, strings.Join(newLabels, ", "))
+	}
+
+	if err := client.AddReviewers(pr.Number, newReviewers); err != nil {
+		return fmt.Errorf("failed to sync reviewers: %w", err)
+	}
+	if len(newReviewers) > 0 {
+		fmt.Fprintf(out, "%s Added reviewers: %s\n", ui.People, strings.Join(newReviewers, ", "))
+	}
+	return nil
+}
+
+// missingValues returns the wanted values that aren't in existing, compared
+// case-insensitively like logins and label names on GitHub and GitLab
+func missingValues(existing, wanted []string) []string {
+	var missing []string
+	for _, value := range wanted {
+		found := false
+		for _, have := range existing {
+			if strings.EqualFold(have, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+// pushForPR pushes a branch the platform couldn't find on the remote, with
+// Push or once the user agrees, reporting whether it was pushed
+func pushForPR(opts CreatePROptions, out io.Writer, gitAnalyzer *git.Analyzer, gitCfg types.GitConfig, branch string) (bool, error) {
+	if git.IsProtectedBranch(branch, gitCfg.ProtectedBranches) {
+		return false, nil
+	}
+
+	if !opts.Push {
+		fmt.Fprintf(out, "%s %s hasn't been pushed yet\n", ui.Warning, branch)
+		if !confirmNo(bufio.NewReader(input(opts.In)), out, fmt.Sprintf("%s Push it and try again?", ui.Rocket)) {
+			return false, nil
+		}
+	}
+
+	fmt.Fprintf(out, "%s Pushing %s...\n", ui.Sync, branch)
+	if err := gitAnalyzer.Push(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// useCodeowners reports whether the platform's config asks for the code
+// owners of the changed files as reviewers
+func useCodeowners(cfg types.PlatformConfig, platform types.PlatformType) bool {
+	switch platform {
+	case types.PlatformGitHub:
+		return cfg.GitHub.UseCodeowners
+	case types.PlatformGitLab:
+		return cfg.GitLab.UseCodeowners
+	}
+	return false
+}
+
+// overrideTitle applies the --title and --title-prefix flags to a generated
+// title; a prefix the title already starts with isn't added twice
+func overrideTitle(title, override, prefix string) string {
+	if override = strings.TrimSpace(override); override != "" {
+		title = override
+	}
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" || strings.HasPrefix(title, prefix) {
+		return title
+	}
+	return prefix + " " + title
+}
+
+// normalizeTitle puts a title on one line and, when it is longer than maxLen
+// characters, cuts it at a word boundary with an ellipsis, returning the part
+// cut off as overflow. A maxLen of 0 or less leaves the length alone.
+func normalizeTitle(title string, maxLen int) (string, string) {
+	title = strings.Join(strings.Fields(title), " ")
+	runes := []rune(title)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return title, ""
+	}
+
+	// Leave room for the ellipsis, and cut at the last space unless that
+	// would lose more than half the title
+	cut := maxLen - 1
+	prefix := string(runes[:cut+1])
+	if space := strings.LastIndex(prefix, " "); space > 0 {
+		if words := utf8.RuneCountInString(prefix[:space]); words > maxLen/2 {
+			cut = words
+		}
+	}
+
+	kept := strings.TrimRight(string(runes[:cut]), " ,;:-")
+	return kept + "…", strings.TrimSpace(string(runes[cut:]))
+}
+
+// createFailureHint suggests how to fix a failed PR/MR creation, or returns
+// an empty string when the cause isn't known
+func createFailureHint(err error, req *types.PullRequestRequest) string {
+	var rateLimit *platforms.RateLimitError
+	switch {
+	case errors.Is(err, platforms.ErrPRAlreadyExists):
+		return "Use --amend-pr to add the new commits to the existing PR/MR"
+	case errors.Is(err, platforms.ErrBranchNotPushed):
+		return fmt.Sprintf("Push the branch first: git push --set-upstream origin %s", req.HeadBranch)
+	case errors.Is(err, platforms.ErrNoCommits):
+		return fmt.Sprintf("%s has no commits that aren't on %s; commit your changes first", req.HeadBranch, req.BaseBranch)
+	case errors.As(err, &rateLimit):
+		return fmt.Sprintf("Wait %s before trying again", rateLimit.RetryAfter)
+	case errors.Is(err, platforms.ErrNotAuthenticated):
+		return "Log in with gh auth login or glab auth login, or pass --auto-login"
+	case errors.Is(err, platforms.ErrNotFound):
+		return "Check that the repository exists and you can access it, or pick the target with --upstream"
+	}
+	return ""
+}
+
+// updatesMarkerPrefix marks the last commit summarized in a PR/MR description
+const updatesMarkerPrefix = "<!-- auto-pr:last-commit "
+
+// amendPR appends a summary of commits added since the existing PR/MR was
+// last described to its body
+func amendPR(opts CreatePROptions, platform types.PlatformType, status *types.GitStatus, gitAnalyzer *git.Analyzer, target *prTarget) (*CreatePRResult, error) {
+	out := output(opts.Out)
+
+	platformClient, err := newPlatformClient(platform, target.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureAuthenticated(bufio.NewReader(input(opts.In)), out, platformClient, opts.AutoLogin); err != nil {
+		return nil, err
+	}
+
+	existingPR, err := platformClient.GetExistingPR(target.HeadBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing PR/MR: %w", err)
+	}
+	if existingPR == nil {
+		return nil, fmt.Errorf("no existing PR/MR for branch '%s'. Run without --amend-pr to create one", target.HeadBranch)
+	}
+
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	commits, err := commitsSinceBase(gitAnalyzer, status.BaseBranch, cfg.Git, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CreatePRResult{PullRequest: existingPR, Existing: true}
+
+	newCommits := commitsSincePR(existingPR, commits)
+	if len(newCommits) == 0 {
+		fmt.Fprintf(out, "%s %s description is already up to date: %s\n", ui.Success, getEntityName(platform), existingPR.URL)
+		return result, nil
+	}
+
+	body := appendUpdatesSection(existingPR.Body, newCommits, time.Now())
+
+	if opts.DryRun {
+		fmt.Fprintf(out, "%s Dry Run - PR/MR Update Preview\n", ui.Search)
+		fmt.Fprintln(out, "=================================")
+		fmt.Fprintf(out, "%s URL: %s\n", ui.Link, existingPR.URL)
+		fmt.Fprintf(out, "%s Body:\n%s\n", ui.Clipboard, body)
+		return result, nil
+	}
+
+	fmt.Fprintf(out, "%s Appending %d new commit(s) to %s...\n", ui.Sync, len(newCommits), getEntityName(platform))
+	updatedPR, err := platformClient.UpdatePullRequest(existingPR.Number, &types.PullRequestUpdate{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update PR/MR: %w", err)
+	}
+
+	fmt.Fprintf(out, "%s Successfully updated %s: %s\n", ui.Success, getEntityName(platform), updatedPR.URL)
+	result.PullRequest = updatedPR
+	return result, nil
+}
+
+// describedHash matches the short or full commit hashes a PR/MR body mentions
+var describedHash = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+
+// commitsSincePR returns the commits (newest first) that the PR/MR body does not
+// describe yet: those after the last-commit marker or, when there is none or it
+// was rebased away, after the newest commit whose hash the body mentions
+func commitsSincePR(pr *types.PullRequest, commits []types.CommitInfo) []types.CommitInfo {
+	if lastHash := lastDescribedCommit(pr.Body); lastHash != "" {
+		for i, commit := range commits {
+			if commit.Hash == lastHash {
+				return commits[:i]
+			}
+		}
+	}
+
+	mentioned := describedHash.FindAllString(pr.Body, -1)
+	for i, commit := range commits {
+		for _, hash := range mentioned {
+			if strings.HasPrefix(commit.Hash, hash) {
+				return commits[:i]
+			}
+		}
+	}
+	return commits
+}
+
+// lastDescribedCommit extracts the commit hash from the last-commit marker
+func lastDescribedCommit(body string) string {
+	start := strings.LastIndex(body, updatesMarkerPrefix)
+	if start == -1 {
+		return ""
+	}
+	rest := body[start+len(updatesMarkerPrefix):]
+	end := strings.Index(rest, "-->")
+	if end == -1 {
+		return ""
+	}
+	return strings.TrimSpace(rest[:end])
+}
+
+// appendUpdatesSection appends an "Updates" section listing the new commits
+// and moves the last-commit marker to the newest one
+func appendUpdatesSection(body string, newCommits []types.CommitInfo, now time.Time) string {
+	// Drop the previous marker so only the latest one remains
+	if start := strings.LastIndex(body, updatesMarkerPrefix); start != -1 {
+		if end := strings.Index(body[start:], "-->"); end != -1 {
+			body = body[:start] + body[start+end+len("-->"):]
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(strings.TrimRight(body, "\n"))
+	fmt.Fprintf(&builder, "\n\n## Updates (%s)\n", now.Format("2006-01-02"))
+	for _, commit := range newCommits {
+		fmt.Fprintf(&builder, "- %s %s\n", git.ShortHash(commit.Hash), commit.Message)
+	}
+	fmt.Fprintf(&builder, "\n%s%s -->\n", updatesMarkerPrefix, newCommits[0].Hash)
+
+	return builder.String()
+}
+
+// describedCommitMarker ends a PR/MR body with the marker recording that it
+// describes the branch up to hash
+func describedCommitMarker(hash string) string {
+	return fmt.Sprintf("\n\n%s%s -->\n", updatesMarkerPrefix, hash)
+}
+
+// prTarget describes the repository a PR/MR is opened against and its head
+type prTarget struct {
+	RemoteURL  string
+	HeadBranch string
+	HeadRepo   string
+}
+
+// checkPRBranches rejects a PR/MR the platform would refuse: one whose base
+// branch couldn't be determined, or one from the base branch into itself
+func checkPRBranches(target *prTarget, base string) error {
+	if base == "" {
+		return fmt.Errorf("couldn't determine the base branch for %s; set the remote's default branch with: git remote set-head origin --auto", target.HeadBranch)
+	}
+	// A branch in another repository, like a fork's, may share the base's name
+	if target.HeadRepo == "" && target.HeadBranch == base {
+		return fmt.Errorf("%s is the base branch, so a PR/MR from it has nothing to merge; create a feature branch first: git switch -c feature/<name>", base)
+	}
+	return nil
+}
+
+// resolvePRTarget works out the target repository and head reference. With an
+// upstream remote the PR targets that repository and the head is taken from
+// origin (the fork); --head owner:branch names the fork owner explicitly.
+func resolvePRTarget(gitAnalyzer *git.Analyzer, status *types.GitStatus, head, upstream string) (*prTarget, error) {
+	target := &prTarget{
+		RemoteURL:  status.RemoteURL,
+		HeadBranch: status.CurrentBranch,
+	}
+
+	headOwner := ""
+	if head != "" {
+		if owner, branch, ok := strings.Cut(head, ":"); ok {
+			headOwner = owner
+			target.HeadBranch = branch
+		} else {
+			target.HeadBranch = head
+		}
+	}
+
+	if upstream == "" && headOwner == "" {
+		return target, nil
+	}
+
+	if upstream != "" {
+		upstreamURL, err := gitAnalyzer.GetRemoteURLByName(upstream)
+		if err != nil {
+			return nil, err
+		}
+		target.RemoteURL = upstreamURL
+	}
+
+	originOwner, originRepo, err := platforms.ExtractRepoInfo(status.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract fork repo info: %w", err)
+	}
+	if headOwner == "" {
+		headOwner = originOwner
+	}
+	target.HeadRepo = headOwner + "/" + originRepo
+
+	return target, nil
+}
+
+// localHeadBranch returns the branch a --head value names in this repository,
+// or "" when there is none or it names a fork's branch as owner:branch
+func localHeadBranch(head string) string {
+	if head == "" || strings.Contains(head, ":") {
+		return ""
+	}
+	return head
+}
+
+// platformClientFactory, when set, replaces the GitHub and GitLab clients so
+// tests can run whole commands against a fake platform
+var platformClientFactory func(platform types.PlatformType, remoteURL string) (platforms.PlatformClient, error)
+
+// newPlatformClient creates the platform client for the detected platform
+func newPlatformClient(platform types.PlatformType, remoteURL string) (platforms.PlatformClient, error) {
+	if platformClientFactory != nil {
+		return platformClientFactory(platform, remoteURL)
+	}
+
+	var client platforms.PlatformClient
+	var err error
+	switch platform {
+	case types.PlatformGitHub:
+		client, err = platforms.NewGitHubClient(remoteURL)
+	case types.PlatformGitLab:
+		client, err = platforms.NewGitLabClient(remoteURL)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create platform client: %w", err)
+	}
+	return client, nil
+}
+
+// ensureAuthenticated checks platform authentication. With autoLogin it offers
+// to run the CLI login inline and checks once more; otherwise it returns an
+// error naming the exact login command for the repository host.
+func ensureAuthenticated(in *bufio.Reader, out io.Writer, client platforms.PlatformClient, autoLogin bool) error {
+	if client.IsAuthenticated() {
+		return nil
+	}
+
+	if !autoLogin {
+		return client.ValidateRepository()
+	}
+
+	if !confirm(in, out, fmt.Sprintf("%s Not authenticated with the platform CLI. Log in now?", ui.Key)) {
+		return client.ValidateRepository()
+	}
+
+	if err := client.Login(); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	if !client.IsAuthenticated() {
+		return client.ValidateRepository()
+	}
+
+	fmt.Fprintf(out, "%s Authenticated\n", ui.Success)
+	return nil
+}
+
+// pickLabelsAndReviewers offers the repository's labels and reviewers with
+// the suggested ones preselected. Suggestions the platform doesn't list, such
+// as teams, stay available to pick. A list that can't be fetched keeps the
+// suggestions.
+func pickLabelsAndReviewers(in *bufio.Reader, out io.Writer, client platforms.PlatformClient, labels, reviewers []string) ([]string, []string) {
+	if available, err := client.ListLabels(); err != nil {
+		fmt.Fprintf(out, "%s Failed to list labels, keeping the suggestions: %v\n", ui.Warning, err)
+	} else {
+		labels = pickMany(in, out, fmt.Sprintf("%s Labels", ui.Label), available, labels)
+	}
+
+	if available, err := client.ListReviewers(); err != nil {
+		fmt.Fprintf(out, "%s Failed to list reviewers, keeping the suggestions: %v\n", ui.Warning, err)
+	} else {
+		options := removeDuplicates(append(append([]string{}, available...), reviewers...))
+		reviewers = pickMany(in, out, fmt.Sprintf("%s Reviewers", ui.Eyes), options, reviewers)
+	}
+
+	return labels, reviewers
+}
+
+// checkPipelineStatus warns when the latest GitLab pipeline on the branch has
+// failed, or returns an error when a passing pipeline is required
+func checkPipelineStatus(out io.Writer, client *platforms.GitLabClient, branch string, requirePassing, verbose bool) error {
+	pipeline, err := client.GetPipeline(branch)
+	if err != nil {
+		if requirePassing {
+			return fmt.Errorf("failed to check pipeline status: %w", err)
+		}
+		if verbose {
+			fmt.Fprintf(out, "Warning: failed to check pipeline status: %v\n", err)
+		}
+		return nil
+	}
+
+	if pipeline.Status != "failed" {
+		return nil
+	}
+
+	if requirePassing {
+		return fmt.Errorf("latest pipeline on branch '%s' failed: %s", branch, pipeline.URL)
+	}
+	fmt.Fprintf(out, "%s Latest pipeline on branch '%s' failed: %s\n", ui.Warning, branch, pipeline.URL)
+	return nil
+}
+
+// checkRunsStatus warns when check runs on the head branch's head commit are
+// failing, or returns an error listing them when passing checks are required
+func checkRunsStatus(out io.Writer, client *platforms.GitHubClient, target *prTarget, requirePassing, verbose bool) error {
+	branch := target.HeadBranch
+	checks, err := client.GetChecksStatus(target.HeadRepo, branch)
+	if err != nil {
+		if requirePassing {
+			return fmt.Errorf("failed to check status of check runs: %w", err)
+		}
+		if verbose {
+			fmt.Fprintf(out, "Warning: failed to check status of check runs: %v\n", err)
+		}
+		return nil
+	}
+
+	var failing []string
+	for _, check := range checks {
+		if check.IsFailing() {
+			failing = append(failing, fmt.Sprintf("%s (%s)", check.Name, check.URL))
+		}
+	}
+
+	if len(failing) == 0 {
+		return nil
+	}
+
+	if requirePassing {
+		return fmt.Errorf("failing checks on branch '%s': %s", branch, strings.Join(failing, ", "))
+	}
+	fmt.Fprintf(out, "%s Failing checks on branch '%s':\n", ui.Warning, branch)
+	for _, check := range failing {
+		fmt.Fprintf(out, "   - %s\n", check)
+	}
+	return nil
+}
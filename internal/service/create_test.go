@@ -0,0 +1,643 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/git"
+	"auto-pr/internal/platforms"
+	"auto-pr/pkg/types"
+
+	"github.com/spf13/viper"
+)
+
+func TestCommitsSincePR(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	commits := []types.CommitInfo{
+		{Hash: "cccccccc1111", Message: "third", Date: base.Add(2 * time.Hour)},
+		{Hash: "bbbbbbbb1111", Message: "second", Date: base.Add(time.Hour)},
+		{Hash: "aaaaaaaa1111", Message: "first", Date: base},
+	}
+
+	tests := []struct {
+		name string
+		pr   *types.PullRequest
+		want int
+	}{
+		{
+			name: "Marker for last described commit",
+			pr:   &types.PullRequest{Body: "Body\n\n" + updatesMarkerPrefix + "bbbbbbbb1111 -->\n"},
+			want: 1,
+		},
+		{
+			name: "No marker falls back to mentioned hashes",
+			pr:   &types.PullRequest{Body: "Body\n\n## Updates\n- bbbbbbb second\n- aaaaaaa first\n"},
+			want: 1,
+		},
+		{
+			name: "Marker rebased away falls back to mentioned hashes",
+			pr:   &types.PullRequest{Body: "- aaaaaaa first\n" + updatesMarkerPrefix + "dddddddd1111 -->\n"},
+			want: 2,
+		},
+		{
+			name: "Commits authored before the last update",
+			pr:   &types.PullRequest{Body: "Body", UpdatedAt: base.Add(3 * time.Hour).Format(time.RFC3339)},
+			want: 3,
+		},
+		{
+			name: "No marker and no update time",
+			pr:   &types.PullRequest{Body: "Body"},
+			want: 3,
+		},
+		{
+			name: "Marker for newest commit",
+			pr:   &types.PullRequest{Body: updatesMarkerPrefix + "cccccccc1111 -->"},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitsSincePR(tt.pr, commits); len(got) != tt.want {
+				t.Errorf("commitsSincePR() returned %d commits, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		wanted   []string
+		want     []string
+	}{
+		{name: "Nothing on the PR yet", existing: nil, wanted: []string{"alice", "bob"}, want: []string{"alice", "bob"}},
+		{name: "Some already there", existing: []string{"alice"}, wanted: []string{"alice", "bob"}, want: []string{"bob"}},
+		{name: "Case differs", existing: []string{"Bug"}, wanted: []string{"bug"}, want: nil},
+		{name: "Nothing wanted", existing: []string{"alice"}, wanted: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missingValues(tt.existing, tt.wanted)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("missingValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendUpdatesSection(t *testing.T) {
+	now := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	body := "## Summary\n\nOriginal\n\n" + updatesMarkerPrefix + "aaaaaaaa1111 -->\n"
+	newCommits := []types.CommitInfo{
+		{Hash: "cccccccc1111", Message: "third"},
+		{Hash: "bbbbbbbb1111", Message: "second"},
+	}
+
+	got := appendUpdatesSection(body, newCommits, now)
+
+	if strings.Count(got, updatesMarkerPrefix) != 1 {
+		t.Errorf("appendUpdatesSection() should keep exactly one marker:\n%s", got)
+	}
+	if lastDescribedCommit(got) != "cccccccc1111" {
+		t.Errorf("lastDescribedCommit() = %q, want newest commit", lastDescribedCommit(got))
+	}
+	for _, want := range []string{"## Updates (2024-05-02)", "- cccccccc third", "- bbbbbbbb second", "Original"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("appendUpdatesSection() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestOverrideTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		prefix   string
+		want     string
+	}{
+		{name: "Generated title", want: "Add login page"},
+		{name: "Override", override: "Login page", want: "Login page"},
+		{name: "Prefix", prefix: "[JIRA-123]", want: "[JIRA-123] Add login page"},
+		{name: "Override and prefix", override: "Login page", prefix: "[JIRA-123]", want: "[JIRA-123] Login page"},
+		{name: "Prefix already there", override: "[JIRA-123] Login page", prefix: "[JIRA-123]", want: "[JIRA-123] Login page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overrideTitle("Add login page", tt.override, tt.prefix); got != tt.want {
+				t.Errorf("overrideTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	pathological := "Refactor the authentication middleware so that expired sessions are refreshed transparently, " +
+		strings.Repeat("and also update every caller ", 20) + "to match"
+
+	tests := []struct {
+		name         string
+		title        string
+		maxLen       int
+		want         string
+		wantOverflow string
+	}{
+		{name: "Short title", title: "Add login page", maxLen: 72, want: "Add login page"},
+		{name: "Exactly the limit", title: strings.Repeat("a", 72), maxLen: 72, want: strings.Repeat("a", 72)},
+		{name: "Newlines and extra spaces", title: "Add login\n  page ", maxLen: 72, want: "Add login page"},
+		{name: "Cut at a word", title: "Add a login page with remember me", maxLen: 20, want: "Add a login page…", wantOverflow: "with remember me"},
+		{name: "Trailing punctuation dropped", title: "Fix crash, then add retries to uploads", maxLen: 12, want: "Fix crash…", wantOverflow: "then add retries to uploads"},
+		{name: "One long word", title: strings.Repeat("x", 30), maxLen: 20, want: strings.Repeat("x", 19) + "…", wantOverflow: strings.Repeat("x", 11)},
+		{name: "Multibyte characters", title: "Añadir página de inicio de sesión", maxLen: 20, want: "Añadir página de…", wantOverflow: "inicio de sesión"},
+		{name: "No limit", title: pathological, maxLen: -1, want: pathological},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, overflow := normalizeTitle(tt.title, tt.maxLen)
+			if got != tt.want || overflow != tt.wantOverflow {
+				t.Errorf("normalizeTitle() = %q, %q; want %q, %q", got, overflow, tt.want, tt.wantOverflow)
+			}
+		})
+	}
+
+	// A title that is really a paragraph fits the limit and loses nothing
+	got, overflow := normalizeTitle(pathological, 72)
+	if n := utf8.RuneCountInString(got); n > 72 {
+		t.Errorf("normalizeTitle() kept %d characters, want at most 72", n)
+	}
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("normalizeTitle() = %q, want it to end with an ellipsis", got)
+	}
+	if rejoined := strings.TrimSuffix(got, "…") + " " + overflow; rejoined != pathological {
+		t.Errorf("title and overflow = %q, want the whole title", rejoined)
+	}
+}
+
+func TestCheckPRBranches(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  prTarget
+		base    string
+		wantErr string
+	}{
+		{name: "Feature branch", target: prTarget{HeadBranch: "feature/login"}, base: "main"},
+		{name: "Base branch", target: prTarget{HeadBranch: "main"}, base: "main", wantErr: "create a feature branch"},
+		{name: "Unknown base", target: prTarget{HeadBranch: "feature/login"}, wantErr: "couldn't determine the base branch"},
+		{name: "Fork branch named like the base", target: prTarget{HeadBranch: "main", HeadRepo: "someone/repo"}, base: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPRBranches(&tt.target, tt.base)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkPRBranches() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkPRBranches() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateFailureHint(t *testing.T) {
+	req := &types.PullRequestRequest{HeadBranch: "feature", BaseBranch: "main"}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "Already exists", err: fmt.Errorf("failed: %w", platforms.ErrPRAlreadyExists), want: "--amend-pr"},
+		{name: "Not pushed", err: platforms.ErrBranchNotPushed, want: "git push --set-upstream origin feature"},
+		{name: "No commits", err: platforms.ErrNoCommits, want: "feature has no commits that aren't on main"},
+		{name: "Rate limited", err: &platforms.RateLimitError{RetryAfter: 2 * time.Minute}, want: "Wait 2m0s"},
+		{name: "Unknown", err: errors.New("boom"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := createFailureHint(tt.err, req)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("createFailureHint() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushForPR(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote, dir := filepath.Join(root, "remote.git"), filepath.Join(root, "work")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("init", "-q", "--bare", remote)
+	run("init", "-q", "-b", "main", dir)
+	run("-C", dir, "remote", "add", "origin", remote)
+	run("-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	run("-C", dir, "checkout", "-q", "-b", "feature/push")
+
+	gitAnalyzer, err := git.NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	gitCfg := types.GitConfig{ProtectedBranches: []string{"main"}}
+	onRemote := func(branch string) bool {
+		return run("-C", remote, "branch", "--list", branch) != ""
+	}
+
+	tests := []struct {
+		name   string
+		opts   CreatePROptions
+		branch string
+		want   bool
+	}{
+		{name: "No answer declines", opts: CreatePROptions{In: strings.NewReader("")}, branch: "feature/push"},
+		{name: "Answering no declines", opts: CreatePROptions{In: strings.NewReader("n\n")}, branch: "feature/push"},
+		{name: "Protected branch is never pushed", opts: CreatePROptions{Push: true}, branch: "main"},
+		{name: "Answering yes pushes", opts: CreatePROptions{In: strings.NewReader("y\n")}, branch: "feature/push", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushed, err := pushForPR(tt.opts, io.Discard, gitAnalyzer, gitCfg, tt.branch)
+			if err != nil {
+				t.Fatalf("pushForPR() error = %v", err)
+			}
+			if pushed != tt.want || onRemote("feature/push") != tt.want {
+				t.Errorf("pushForPR() = %v (on remote: %v), want %v", pushed, onRemote("feature/push"), tt.want)
+			}
+		})
+	}
+}
+
+// loginPlatform is a platform client that isn't logged in until Login runs
+type loginPlatform struct {
+	platforms.PlatformClient
+	loggedIn bool
+}
+
+func (p *loginPlatform) IsAuthenticated() bool { return p.loggedIn }
+
+func (p *loginPlatform) Login() error {
+	p.loggedIn = true
+	return nil
+}
+
+func (p *loginPlatform) ValidateRepository() error { return platforms.ErrNotAuthenticated }
+
+func TestEnsureAuthenticated(t *testing.T) {
+	tests := []struct {
+		name      string
+		autoLogin bool
+		answer    string
+		wantLogin bool
+	}{
+		{name: "Without auto-login", answer: "y\n"},
+		{name: "No answer logs in", autoLogin: true, answer: "", wantLogin: true},
+		{name: "Answering yes logs in", autoLogin: true, answer: "y\n", wantLogin: true},
+		{name: "Answering no declines", autoLogin: true, answer: "n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &loginPlatform{}
+			err := ensureAuthenticated(bufio.NewReader(strings.NewReader(tt.answer)), io.Discard, client, tt.autoLogin)
+			if client.loggedIn != tt.wantLogin || (err == nil) != tt.wantLogin {
+				t.Errorf("ensureAuthenticated() logged in = %v, error = %v; want login %v", client.loggedIn, err, tt.wantLogin)
+			}
+		})
+	}
+}
+
+func TestCreatePRDryRunWithMockClient(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Keep the user's config and templates out of the run
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("remote", "add", "origin", "https://github.com/acme/widgets.git")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	run("checkout", "-q", "-b", "feature/export")
+	if err := os.WriteFile(filepath.Join(dir, "export.go"), []byte("package export\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "export.go")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "feat: add export")
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "Add CSV export", Body: "Exports widgets as CSV.", Labels: []string{"enhancement"}})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	var out bytes.Buffer
+	result, err := CreatePR(CreatePROptions{RepoPath: dir, DryRun: true, Out: &out})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("CreatePR() made %d AI calls, want 1", len(calls))
+	}
+	if got := calls[0].Context.BranchInfo.Name; got != "feature/export" {
+		t.Errorf("AI context branch = %q, want feature/export", got)
+	}
+	if len(calls[0].Context.CommitHistory) != 1 || calls[0].Context.CommitHistory[0].Message != "feat: add export" {
+		t.Errorf("AI context commits = %+v, want the branch's commit", calls[0].Context.CommitHistory)
+	}
+
+	if result.PullRequest != nil {
+		t.Errorf("CreatePR() created a PR/MR in a dry run: %+v", result.PullRequest)
+	}
+	if result.Content.Title != "Add CSV export" {
+		t.Errorf("Content.Title = %q, want the mock's title", result.Content.Title)
+	}
+	for _, want := range []string{"Dry Run", "Add CSV export", "Exports widgets as CSV."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("CreatePR() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// fakePlatform keeps the PRs/MRs it is asked to create in memory, one per
+// head branch
+type fakePlatform struct {
+	platforms.PlatformClient
+	prs map[string]*types.PullRequest
+}
+
+func (p *fakePlatform) IsAuthenticated() bool { return true }
+
+func (p *fakePlatform) ListLabels() ([]string, error) { return nil, nil }
+
+func (p *fakePlatform) GetExistingPR(branch string) (*types.PullRequest, error) {
+	return p.prs[branch], nil
+}
+
+func (p *fakePlatform) CreatePullRequest(req *types.PullRequestRequest) (*types.PullRequest, error) {
+	pr := &types.PullRequest{Number: len(p.prs) + 1, Title: req.Title, Body: req.Body,
+		HeadBranch: req.HeadBranch, BaseBranch: req.BaseBranch, State: types.PRStateOpen}
+	p.prs[req.HeadBranch] = pr
+	return pr, nil
+}
+
+func (p *fakePlatform) UpdatePullRequest(number int, update *types.PullRequestUpdate) (*types.PullRequest, error) {
+	for _, pr := range p.prs {
+		if pr.Number == number {
+			pr.Body = update.Body
+			return pr, nil
+		}
+	}
+	return nil, platforms.ErrNotFound
+}
+
+func TestCreatePRThenAmend(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+	run("init", "-q", "-b", "main")
+	run("remote", "add", "origin", "https://github.com/acme/widgets.git")
+	commit("init")
+	run("checkout", "-q", "-b", "feature/export")
+	commit("feat: add export")
+	commit("feat: export headers")
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "Add CSV export", Body: "Exports widgets as CSV."})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+	platform := &fakePlatform{prs: make(map[string]*types.PullRequest)}
+	platformClientFactory = func(types.PlatformType, string) (platforms.PlatformClient, error) { return platform, nil }
+	t.Cleanup(func() { platformClientFactory = nil })
+
+	if _, err := CreatePR(CreatePROptions{RepoPath: dir, Out: io.Discard}); err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+
+	// Amending right away finds nothing new to describe
+	var out bytes.Buffer
+	if _, err := CreatePR(CreatePROptions{RepoPath: dir, AmendPR: true, Out: &out}); err != nil {
+		t.Fatalf("CreatePR() amend error = %v", err)
+	}
+	if !strings.Contains(out.String(), "already up to date") {
+		t.Errorf("amend after create didn't find the description up to date:\n%s", out.String())
+	}
+
+	commit("fix: quote fields")
+	if _, err := CreatePR(CreatePROptions{RepoPath: dir, AmendPR: true, Out: io.Discard}); err != nil {
+		t.Fatalf("CreatePR() amend error = %v", err)
+	}
+	body := platform.prs["feature/export"].Body
+	if !strings.Contains(body, "fix: quote fields") || strings.Contains(body, "export headers") {
+		t.Errorf("amended body should list only the new commit:\n%s", body)
+	}
+
+	// --head and --upstream pick the PR/MR to amend, not the checked-out branch
+	commit("docs: describe export")
+	run("checkout", "-q", "-b", "feature/other")
+	run("remote", "add", "upstream", "https://github.com/upstream/widgets.git")
+	var remoteURL string
+	platformClientFactory = func(_ types.PlatformType, url string) (platforms.PlatformClient, error) {
+		remoteURL = url
+		return platform, nil
+	}
+	if _, err := CreatePR(CreatePROptions{RepoPath: dir, AmendPR: true, Head: "acme:feature/export", Upstream: "upstream", Out: io.Discard}); err != nil {
+		t.Fatalf("CreatePR() amend with --head error = %v", err)
+	}
+	if remoteURL != "https://github.com/upstream/widgets.git" {
+		t.Errorf("amend looked up the PR/MR on %s, want the upstream repository", remoteURL)
+	}
+	if body := platform.prs["feature/export"].Body; !strings.Contains(body, "docs: describe export") {
+		t.Errorf("amend with --head didn't update feature/export's PR:\n%s", body)
+	}
+}
+
+func TestCreatePRTypeDefaults(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Keep the user's config and templates out of the run
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Cleanup(viper.Reset)
+	viper.Set("type_defaults", map[string]interface{}{
+		"docs":   map[string]interface{}{"labels": []string{"documentation"}, "reviewers": []string{"acme/docs-team"}},
+		"bugfix": map[string]interface{}{"labels": []string{"bug"}},
+	})
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("remote", "add", "origin", "https://github.com/acme/widgets.git")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	run("checkout", "-q", "-b", "docs/readme")
+	run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "docs: explain setup")
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "Explain setup", Body: "Adds setup steps.", Labels: []string{"documentation"}})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	tests := []struct {
+		name          string
+		commitType    string
+		wantLabel     string // Added by type_defaults on top of the AI's and the template's
+		otherLabel    string // From the defaults of the type that doesn't apply
+		wantReviewers []string
+	}{
+		{name: "detected type", wantLabel: "documentation", otherLabel: "bug", wantReviewers: []string{"acme/docs-team"}},
+		{name: "chosen type", commitType: "fix", wantLabel: "bug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CreatePR(CreatePROptions{RepoPath: dir, Type: tt.commitType, DryRun: true, Out: io.Discard})
+			if err != nil {
+				t.Fatalf("CreatePR() error = %v", err)
+			}
+			labels := "," + strings.Join(result.Content.Labels, ",") + ","
+			if !strings.Contains(labels, ","+tt.wantLabel+",") {
+				t.Errorf("Content.Labels = %v, want %s among them", result.Content.Labels, tt.wantLabel)
+			}
+			if tt.otherLabel != "" && strings.Contains(labels, ","+tt.otherLabel+",") {
+				t.Errorf("Content.Labels = %v, want no %s", result.Content.Labels, tt.otherLabel)
+			}
+			if strings.Count(labels, ",documentation,") > 1 {
+				t.Errorf("Content.Labels = %v, want documentation once", result.Content.Labels)
+			}
+			if strings.Join(result.Content.Reviewers, ",") != strings.Join(tt.wantReviewers, ",") {
+				t.Errorf("Content.Reviewers = %v, want %v", result.Content.Reviewers, tt.wantReviewers)
+			}
+		})
+	}
+}
+
+func TestCreatePRWithOtherHeadBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("remote", "add", "origin", "https://github.com/acme/widgets.git")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	run("branch", "feature/empty")
+	run("checkout", "-q", "-b", "feature/import")
+	if err := os.WriteFile(filepath.Join(dir, "import.go"), []byte("package widgets\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "import.go")
+	run("commit", "-q", "-m", "feat: add import")
+	// The branch being worked on has commits and changes of its own
+	run("checkout", "-q", "-b", "feature/other", "main")
+	if err := os.WriteFile(filepath.Join(dir, "other.go"), []byte("package widgets\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "other.go")
+	run("commit", "-q", "-m", "feat: add other")
+	if err := os.WriteFile(filepath.Join(dir, "wip.go"), []byte("package widgets\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "Add import", Body: "Imports widgets."})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	if _, err := CreatePR(CreatePROptions{RepoPath: dir, Head: "feature/import", DryRun: true, Out: io.Discard}); err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("CreatePR() made %d AI calls, want 1", len(calls))
+	}
+	if got := calls[0].Context.BranchInfo.Name; got != "feature/import" {
+		t.Errorf("AI context branch = %q, want feature/import", got)
+	}
+	if history := calls[0].Context.CommitHistory; len(history) != 1 || history[0].Message != "feat: add import" {
+		t.Errorf("AI context commits = %+v, want only feature/import's commit", history)
+	}
+	if changes := calls[0].Context.FileChanges; len(changes) != 1 || changes[0].Path != "import.go" {
+		t.Errorf("AI context file changes = %+v, want only import.go", changes)
+	}
+
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"missing branch", "feature/missing", "branch feature/missing not found locally"},
+		{"no commits ahead", "feature/empty", "feature/empty has no commits ahead of main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreatePR(CreatePROptions{RepoPath: dir, Head: tt.head, DryRun: true, Out: io.Discard})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CreatePR() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"auto-pr/pkg/types"
+)
+
+// maxBodyLength is the longest PR/MR description or comment each platform
+// accepts, in bytes to stay on the safe side of their character limits
+var maxBodyLength = map[types.PlatformType]int{
+	types.PlatformGitHub: 65536,
+	types.PlatformGitLab: 1048576,
+}
+
+// Notes ending a description that was cut short to fit the platform
+const (
+	truncatedBodyNote        = "\n\n_The description was too long for a %s and has been cut short._\n"
+	truncatedBodyDetailsNote = "\n\n_The description was too long for a %s; the rest is in the first comment._\n"
+)
+
+// fitBody cuts body at a line break so it fits in limit bytes together with
+// note, returning the kept part and the part that was cut. A limit of 0 means
+// no limit.
+func fitBody(body string, limit int, note string) (string, string) {
+	if limit <= 0 || len(body) <= limit {
+		return body, ""
+	}
+
+	cut := limit - len(note)
+	if cut < 0 {
+		cut = 0
+	}
+	if idx := strings.LastIndex(body[:cut], "\n"); idx > 0 {
+		cut = idx + 1
+	}
+	// Without a line break, at least don't split a character
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+
+	return strings.TrimRight(body[:cut], "\n") + note, body[cut:]
+}
+
+// detailsComment builds the comment --post-details adds after creating a
+// PR/MR: the full list of changed files and any part of the description that
+// was cut short, kept within limit bytes
+func detailsComment(changes []types.FileChange, omittedBody string, limit int) string {
+	var comment strings.Builder
+
+	comment.WriteString("## Details\n\n")
+	if omittedBody != "" {
+		// Keep at least half the comment for the file list
+		rest, _ := fitBody(strings.TrimSpace(omittedBody), limit/2, "\n\n_Cut short._")
+		comment.WriteString("### Rest of the description\n\n")
+		comment.WriteString(strings.TrimSpace(rest))
+		comment.WriteString("\n\n")
+	}
+
+	additions, deletions := 0, 0
+	for _, change := range changes {
+		additions += change.Additions
+		deletions += change.Deletions
+	}
+	fmt.Fprintf(&comment, "### Changed files (%d, +%d -%d)\n\n", len(changes), additions, deletions)
+	comment.WriteString("| File | Status | + | - |\n")
+	comment.WriteString("| --- | --- | --- | --- |\n")
+
+	for i, change := range changes {
+		row := fmt.Sprintf("| %s | %s | %d | %d |\n", markdownCell(change.Path), change.Status, change.Additions, change.Deletions)
+		// Leave room for the note on the files that didn't fit
+		if limit > 0 && comment.Len()+len(row)+100 > limit {
+			fmt.Fprintf(&comment, "\n_%d more files not listed._\n", len(changes)-i)
+			break
+		}
+		comment.WriteString(row)
+	}
+
+	return comment.String()
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestFitBody(t *testing.T) {
+	body := "## Summary\n\nFirst paragraph.\n\n## Details\n\nSecond paragraph.\n"
+	note := "\n\n(cut)\n"
+
+	tests := []struct {
+		name        string
+		limit       int
+		wantKept    string
+		wantOmitted string
+	}{
+		{name: "No limit", limit: 0, wantKept: body},
+		{name: "Fits", limit: len(body), wantKept: body},
+		{
+			name:        "Cut at a line break",
+			limit:       40,
+			wantKept:    "## Summary\n\nFirst paragraph.\n\n(cut)\n",
+			wantOmitted: "## Details\n\nSecond paragraph.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, omitted := fitBody(body, tt.limit, note)
+			if kept != tt.wantKept || omitted != tt.wantOmitted {
+				t.Errorf("fitBody() = %q, %q; want %q, %q", kept, omitted, tt.wantKept, tt.wantOmitted)
+			}
+			if tt.limit > 0 && len(kept) > tt.limit {
+				t.Errorf("fitBody() kept %d bytes, want at most %d", len(kept), tt.limit)
+			}
+		})
+	}
+}
+
+func TestFitBodyDoesNotSplitCharacters(t *testing.T) {
+	kept, omitted := fitBody(strings.Repeat("é", 10), 5, "")
+	if kept+omitted != strings.Repeat("é", 10) || !strings.HasSuffix(kept, "é") {
+		t.Errorf("fitBody() = %q, %q; want the cut between characters", kept, omitted)
+	}
+}
+
+func TestDetailsComment(t *testing.T) {
+	changes := []types.FileChange{
+		{Path: "a.go", Status: types.StatusModified, Additions: 3, Deletions: 1},
+		{Path: "b|c.go", Status: types.StatusAdded, Additions: 7},
+	}
+
+	comment := detailsComment(changes, "Rest of the body", 0)
+	for _, want := range []string{"### Rest of the description", "Rest of the body", "Changed files (2, +10 -1)", "| a.go |", `| b\|c.go |`} {
+		if !strings.Contains(comment, want) {
+			t.Errorf("detailsComment() missing %q in:\n%s", want, comment)
+		}
+	}
+
+	var many []types.FileChange
+	for i := 0; i < 1000; i++ {
+		many = append(many, types.FileChange{Path: strings.Repeat("x", 50), Status: types.StatusModified})
+	}
+	limited := detailsComment(many, "", 2000)
+	if len(limited) > 2000 || !strings.Contains(limited, "more files not listed") {
+		t.Errorf("detailsComment() = %d bytes, want at most 2000 with a note on the files left out", len(limited))
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+// validateFill rejects options that reshape or add to the content, which
+// Fill takes from the commit messages as they are
+func validateFill(opts CreatePROptions) error {
+	if !opts.Fill {
+		return nil
+	}
+
+	conflicts := []struct {
+		set  bool
+		flag string
+	}{
+		{opts.Refine, "--refine"},
+		{opts.SinceTag != "", "--since-tag"},
+		{opts.Template != "", "--template"},
+		{opts.UseRepoTemplate, "--use-repo-template"},
+		{opts.Issue != "", "--issue"},
+		{opts.AmendPR, "--amend-pr"},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return fmt.Errorf("--fill uses the commit messages as they are and can't be combined with %s", conflict.flag)
+		}
+	}
+	return nil
+}
+
+// fillFromCommits builds the PR/MR content from the commit messages alone,
+// like gh pr create --fill. commits are newest first, as git log lists them.
+// The oldest commit's subject is the title; the body is that commit's body
+// followed by the full message of each later commit, oldest first.
+func fillFromCommits(commits []types.CommitInfo) (*ai.AIResponse, error) {
+	if len(commits) == 0 {
+		return nil, errors.New("no commits to fill the PR/MR from")
+	}
+
+	first := commits[len(commits)-1]
+	var parts []string
+	if first.Body != "" {
+		parts = append(parts, first.Body)
+	}
+	for i := len(commits) - 2; i >= 0; i-- {
+		message := commits[i].Message
+		if commits[i].Body != "" {
+			message += "\n\n" + commits[i].Body
+		}
+		parts = append(parts, message)
+	}
+
+	return &ai.AIResponse{
+		Title:    first.Message,
+		Body:     strings.Join(parts, "\n\n"),
+		Provider: types.AIProviderNone,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+func TestFillFromCommits(t *testing.T) {
+	tests := []struct {
+		name      string
+		commits   []types.CommitInfo // newest first
+		wantTitle string
+		wantBody  string
+		wantErr   bool
+	}{
+		{
+			name:      "single commit",
+			commits:   []types.CommitInfo{{Message: "feat: add export", Body: "Exports widgets as CSV.\n\nRefs: #12"}},
+			wantTitle: "feat: add export",
+			wantBody:  "Exports widgets as CSV.\n\nRefs: #12",
+		},
+		{
+			name: "later commits follow oldest first",
+			commits: []types.CommitInfo{
+				{Message: "docs: document export"},
+				{Message: "fix: quote commas", Body: "Fields with commas were split."},
+				{Message: "feat: add export", Body: "Exports widgets as CSV."},
+			},
+			wantTitle: "feat: add export",
+			wantBody:  "Exports widgets as CSV.\n\nfix: quote commas\n\nFields with commas were split.\n\ndocs: document export",
+		},
+		{
+			name:      "subject-only first commit",
+			commits:   []types.CommitInfo{{Message: "fix: typo"}, {Message: "feat: add export"}},
+			wantTitle: "feat: add export",
+			wantBody:  "fix: typo",
+		},
+		{
+			name:    "no commits",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fillFromCommits(tt.commits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fillFromCommits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", got.Title, tt.wantTitle)
+			}
+			if got.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", got.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestValidateFill(t *testing.T) {
+	if err := validateFill(CreatePROptions{Fill: true, NoAI: true, Draft: true}); err != nil {
+		t.Errorf("validateFill() rejected compatible options: %v", err)
+	}
+	if err := validateFill(CreatePROptions{Refine: true}); err != nil {
+		t.Errorf("validateFill() checked options without Fill: %v", err)
+	}
+
+	err := validateFill(CreatePROptions{Fill: true, Template: "feature"})
+	if err == nil || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("validateFill() error = %v, want one naming --template", err)
+	}
+}
+
+func TestCreatePRFillSkipsAI(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Keep the user's config and templates out of the run
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(message string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", message)
+	}
+	run("init", "-q", "-b", "main")
+	run("remote", "add", "origin", "https://github.com/acme/widgets.git")
+	commit("init")
+	run("checkout", "-q", "-b", "feature/export")
+	commit("feat: add export\n\nExports widgets as CSV.")
+	commit("fix: quote commas")
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "AI title", Body: "AI body"})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	var out bytes.Buffer
+	result, err := CreatePR(CreatePROptions{RepoPath: dir, Fill: true, DryRun: true, Out: &out})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("CreatePR() made %d AI calls with Fill, want none", len(calls))
+	}
+	if result.Content.Title != "feat: add export" {
+		t.Errorf("Content.Title = %q, want the first commit's subject", result.Content.Title)
+	}
+	if want := "Exports widgets as CSV.\n\nfix: quote commas"; result.Content.Body != want {
+		t.Errorf("Content.Body = %q, want %q", result.Content.Body, want)
+	}
+}
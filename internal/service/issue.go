@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// maxIssueBodyLength caps the issue description given to the AI, leaving
+// room in the prompt for the changes themselves
+const maxIssueBodyLength = 8000
+
+// parseIssueNumber reads an issue reference given as 123, #123 or the
+// issue's URL
+func parseIssueNumber(issue string) (int, error) {
+	ref := strings.TrimSuffix(strings.TrimSpace(issue), "/")
+	if strings.Contains(ref, "/") {
+		ref = path.Base(ref)
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid issue %q, expected a number such as 123 or #123", issue)
+	}
+	return number, nil
+}
+
+// fetchIssue returns the issue the PR/MR addresses, with its description cut
+// to maxIssueBodyLength. The issue only adds context, so failing to fetch it
+// warns and returns nil.
+func fetchIssue(out io.Writer, platform types.PlatformType, remoteURL string, number int) *types.Issue {
+	platformClient, err := newPlatformClient(platform, remoteURL)
+	if err == nil {
+		var issue *types.Issue
+		if issue, err = platformClient.GetIssue(number); err == nil {
+			if len(issue.Body) > maxIssueBodyLength {
+				issue.Body = strings.ToValidUTF8(issue.Body[:maxIssueBodyLength], "") + "\n\n(issue description cut short)"
+			}
+			fmt.Fprintf(out, "%s Using issue #%d: %s\n", ui.Link, issue.Number, issue.Title)
+			return issue
+		}
+	}
+
+	fmt.Fprintf(out, "%s Couldn't fetch issue #%d, continuing without it: %v\n", ui.Warning, number, err)
+	return nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import "testing"
+
+func TestParseIssueNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		issue   string
+		want    int
+		wantErr bool
+	}{
+		{name: "number", issue: "123", want: 123},
+		{name: "hash prefix", issue: "#123", want: 123},
+		{name: "github url", issue: "https://github.com/acme/widgets/issues/42", want: 42},
+		{name: "gitlab url with trailing slash", issue: "https://gitlab.com/group/project/-/issues/7/", want: 7},
+		{name: "not a number", issue: "abc", wantErr: true},
+		{name: "zero", issue: "#0", wantErr: true},
+		{name: "negative", issue: "-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIssueNumber(tt.issue)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIssueNumber(%q) error = %v, wantErr %v", tt.issue, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseIssueNumber(%q) = %d, want %d", tt.issue, got, tt.want)
+			}
+		})
+	}
+}
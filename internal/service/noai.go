@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+// typeLabels are the labels suggested for a PR/MR whose commits are mostly
+// of a conventional commit type
+var typeLabels = map[string]string{
+	"feat": "enhancement",
+	"fix":  "bug",
+	"docs": "documentation",
+}
+
+// deterministicClient describes changes without an AI, from the commit
+// messages and file changes alone, for when no AI is available or wanted.
+// The same context always gives the same content.
+type deterministicClient struct{}
+
+// newDeterministicClient creates a client that describes changes without an AI
+func newDeterministicClient() ai.AIClient {
+	return deterministicClient{}
+}
+
+// GenerateContent builds a title, body and labels from the context; the
+// prompt is ignored
+func (deterministicClient) GenerateContent(ctx *ai.AIContext, prompt string) (*ai.AIResponse, error) {
+	return &ai.AIResponse{
+		Title:    deterministicTitle(ctx),
+		Body:     deterministicBody(ctx),
+		Labels:   deterministicLabels(ctx.CommitHistory),
+		Provider: types.AIProviderNone,
+	}, nil
+}
+
+// IsAvailable always reports true; nothing needs to be installed
+func (deterministicClient) IsAvailable() bool {
+	return true
+}
+
+// GetProvider returns types.AIProviderNone
+func (deterministicClient) GetProvider() types.AIProvider {
+	return types.AIProviderNone
+}
+
+// ValidateConfig has nothing to validate
+func (deterministicClient) ValidateConfig() error {
+	return nil
+}
+
+// deterministicTitle uses the subject of a single commit, and otherwise the
+// branch name in words, e.g. "feature/csv-export" becomes "Csv export"
+func deterministicTitle(ctx *ai.AIContext) string {
+	if len(ctx.CommitHistory) == 1 {
+		return ctx.CommitHistory[0].Message
+	}
+
+	name := strings.NewReplacer("-", " ", "_", " ").Replace(path.Base(ctx.BranchInfo.Name))
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || name == "." {
+		return fmt.Sprintf("Update %d files", len(ctx.FileChanges))
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// deterministicBody summarizes the changes, lists the commits grouped by
+// type and lists the changed files
+func deterministicBody(ctx *ai.AIContext) string {
+	var body strings.Builder
+
+	body.WriteString("## Summary\n\n")
+	summary := fmt.Sprintf("%d commits", len(ctx.CommitHistory))
+	if len(ctx.CommitHistory) == 1 {
+		summary = "1 commit"
+	}
+	if ctx.DiffSummary != "" {
+		summary += ": " + ctx.DiffSummary
+	}
+	body.WriteString(summary + ".\n")
+
+	if len(ctx.CommitHistory) > 0 {
+		body.WriteString("\n## Changes\n")
+		body.WriteString(commitSections(ctx.CommitHistory))
+	}
+
+	if len(ctx.FileChanges) > 0 {
+		body.WriteString("\n## Files Changed\n\n")
+		for _, change := range ctx.FileChanges {
+			fmt.Fprintf(&body, "- `%s` (%s, +%d -%d)\n", change.Path, change.Status, change.Additions, change.Deletions)
+		}
+	}
+
+	return body.String()
+}
+
+// deterministicLabels suggests a label for the most common conventional
+// commit type, with ties going to the type seen first (the newest commit)
+func deterministicLabels(commits []types.CommitInfo) []string {
+	counts := make(map[string]int)
+	var kinds []string
+	for _, commit := range commits {
+		kind := parseReleaseCommit(commit).kind
+		if _, ok := typeLabels[kind]; !ok {
+			continue
+		}
+		if counts[kind] == 0 {
+			kinds = append(kinds, kind)
+		}
+		counts[kind]++
+	}
+
+	best := ""
+	for _, kind := range kinds {
+		if best == "" || counts[kind] > counts[best] {
+			best = kind
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return []string{typeLabels[best]}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+func TestDeterministicClient(t *testing.T) {
+	ctx := &ai.AIContext{
+		CommitHistory: []types.CommitInfo{
+			{Hash: "aaaaaaaa1111", Message: "fix: handle empty input"},
+			{Hash: "bbbbbbbb2222", Message: "feat(export): add CSV export"},
+			{Hash: "cccccccc3333", Message: "feat: add export command"},
+		},
+		DiffSummary: "2 files changed, 40 additions, 3 deletions",
+		FileChanges: []types.FileChange{
+			{Path: "cmd/export.go", Status: types.StatusAdded, Additions: 30},
+			{Path: "internal/csv.go", Status: types.StatusModified, Additions: 10, Deletions: 3},
+		},
+		BranchInfo: types.BranchInfo{Name: "feature/csv-export"},
+	}
+
+	response, err := newDeterministicClient().GenerateContent(ctx, "ignored")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+
+	if response.Title != "Csv export" {
+		t.Errorf("Title = %q, want the branch name in words", response.Title)
+	}
+	for _, want := range []string{
+		"3 commits: 2 files changed, 40 additions, 3 deletions.",
+		"### Features\n\n- **export:** add CSV export (bbbbbbbb)\n- add export command (cccccccc)",
+		"### Bug Fixes\n\n- handle empty input (aaaaaaaa)",
+		"- `cmd/export.go` (added, +30 -0)",
+	} {
+		if !strings.Contains(response.Body, want) {
+			t.Errorf("Body missing %q:\n%s", want, response.Body)
+		}
+	}
+	if !reflect.DeepEqual(response.Labels, []string{"enhancement"}) {
+		t.Errorf("Labels = %v, want [enhancement]", response.Labels)
+	}
+	if response.Provider != types.AIProviderNone {
+		t.Errorf("Provider = %v, want %v", response.Provider, types.AIProviderNone)
+	}
+
+	again, _ := newDeterministicClient().GenerateContent(ctx, "another prompt")
+	if !reflect.DeepEqual(again, response) {
+		t.Error("GenerateContent() gave different content for the same context")
+	}
+}
+
+func TestDeterministicTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  *ai.AIContext
+		want string
+	}{
+		{
+			name: "Single commit subject",
+			ctx: &ai.AIContext{
+				CommitHistory: []types.CommitInfo{{Message: "fix: handle empty input"}},
+				BranchInfo:    types.BranchInfo{Name: "bugfix/empty"},
+			},
+			want: "fix: handle empty input",
+		},
+		{
+			name: "Branch name in words",
+			ctx:  &ai.AIContext{BranchInfo: types.BranchInfo{Name: "chore/update_go-deps"}},
+			want: "Update go deps",
+		},
+		{
+			name: "No branch name",
+			ctx:  &ai.AIContext{FileChanges: []types.FileChange{{Path: "a.go"}, {Path: "b.go"}}},
+			want: "Update 2 files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deterministicTitle(tt.ctx); got != tt.want {
+				t.Errorf("deterministicTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+
+	"auto-pr/internal/git"
+	"auto-pr/internal/platforms"
+	"auto-pr/pkg/types"
+)
+
+// OpenPROptions configures OpenPR
+type OpenPROptions struct {
+	RepoPath  string
+	Title     string
+	Body      string
+	Base      string // Branch to merge into; when empty, the one AnalyzeChanges reports
+	Draft     bool
+	Reviewers []string
+	Labels    []string
+	Client    platforms.PlatformClient // Client for the platform the repository is on
+}
+
+// OpenPRResult is the PR/MR OpenPR opened, or the one already open
+type OpenPRResult struct {
+	PR       *types.PullRequest
+	Existing bool // The branch already had an open PR/MR, so none was opened
+}
+
+// OpenPR opens a PR/MR from the current branch with a title and body written
+// elsewhere, e.g. by the assistant calling the MCP create_pr tool. Unless
+// Base is given it targets the base branch create and AnalyzeChanges infer
+// from the history, and labels the repository doesn't have are left out
+// rather than failing the call.
+func OpenPR(opts OpenPROptions) (*OpenPRResult, error) {
+	if opts.Title == "" || opts.Body == "" {
+		return nil, fmt.Errorf("title and body are required")
+	}
+
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if status.DetachedHead {
+		return nil, git.ErrDetachedHead
+	}
+	if err := resolveOpenPRBase(gitAnalyzer, status, opts.Base); err != nil {
+		return nil, err
+	}
+
+	if existing, err := opts.Client.GetExistingPR(status.CurrentBranch); err == nil && existing != nil {
+		return &OpenPRResult{PR: existing, Existing: true}, nil
+	}
+
+	labels, err := platforms.FilterExistingLabels(opts.Client, opts.Labels)
+	if err != nil {
+		labels = nil
+	}
+
+	req := &types.PullRequestRequest{
+		Title:      opts.Title,
+		Body:       opts.Body,
+		HeadBranch: status.CurrentBranch,
+		BaseBranch: status.BaseBranch,
+		Draft:      opts.Draft,
+		Reviewers:  removeDuplicates(opts.Reviewers),
+		Labels:     labels,
+	}
+	pr, err := opts.Client.CreatePullRequest(req)
+	if err != nil {
+		if hint := createFailureHint(err, req); hint != "" {
+			return nil, fmt.Errorf("failed to create PR/MR: %w. %s", err, hint)
+		}
+		return nil, fmt.Errorf("failed to create PR/MR: %w", err)
+	}
+	return &OpenPRResult{PR: pr}, nil
+}
+
+// resolveOpenPRBase points status at the given base, checking that it exists
+// and isn't the branch itself, or else at the inferred one
+func resolveOpenPRBase(gitAnalyzer *git.Analyzer, status *types.GitStatus, base string) error {
+	if base == "" {
+		inferBaseBranch(gitAnalyzer, status)
+		if status.CurrentBranch == status.BaseBranch {
+			return fmt.Errorf("%s is the base branch; open the PR/MR from a feature branch", status.BaseBranch)
+		}
+		return nil
+	}
+
+	if base == status.CurrentBranch {
+		return fmt.Errorf("%s is the PR/MR's own branch and can't be its base", base)
+	}
+	if !gitAnalyzer.BranchExists(base) {
+		return fmt.Errorf("branch %s not found locally or on origin; fetch it or check the name", base)
+	}
+	setBaseBranch(gitAnalyzer, status, base)
+	return nil
+}
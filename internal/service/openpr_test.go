@@ -0,0 +1,108 @@
+package service
+
+import (
+	"os/exec"
+	"testing"
+
+	"auto-pr/internal/platforms"
+	"auto-pr/pkg/types"
+
+	"github.com/spf13/viper"
+)
+
+// recordingPlatform is a platform client with no open PRs/MRs that records
+// the one it is asked to create
+type recordingPlatform struct {
+	platforms.PlatformClient
+	created *types.PullRequestRequest
+}
+
+func (p *recordingPlatform) GetExistingPR(string) (*types.PullRequest, error) { return nil, nil }
+
+func (p *recordingPlatform) ListLabels() ([]string, error) { return []string{"bugfix"}, nil }
+
+func (p *recordingPlatform) CreatePullRequest(req *types.PullRequestRequest) (*types.PullRequest, error) {
+	p.created = req
+	return &types.PullRequest{Number: 1, HeadBranch: req.HeadBranch, BaseBranch: req.BaseBranch}, nil
+}
+
+func TestOpenPRBaseMatchesAnalyzeChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	// release forks from develop, which forks from main; feature forks from release
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(msg string) {
+		run("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+	}
+	run("init", "-q", "-b", "main")
+	commit("init")
+	run("checkout", "-q", "-b", "develop")
+	commit("d1")
+	run("checkout", "-q", "-b", "release")
+	commit("r1")
+	run("checkout", "-q", "-b", "feature")
+	commit("fix: handle empty input")
+
+	tests := []struct {
+		name       string
+		candidates []string // git.base_branch_candidates
+		base       string   // create_pr's base argument
+		want       string
+	}{
+		{name: "Inferred from the default candidates", want: "develop"},
+		{name: "Inferred from configured candidates", candidates: []string{"release"}, want: "release"},
+		{name: "Given base", base: "main", want: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			if tt.candidates != nil {
+				viper.Set("git.base_branch_candidates", tt.candidates)
+			}
+
+			client := &recordingPlatform{}
+			result, err := OpenPR(OpenPROptions{RepoPath: dir, Title: "Fix empty input", Body: "Body", Base: tt.base, Labels: []string{"bugfix", "size/S"}, Client: client})
+			if err != nil {
+				t.Fatalf("OpenPR() error = %v", err)
+			}
+			if result.Existing || client.created == nil {
+				t.Fatal("OpenPR() didn't create a PR")
+			}
+			if got := client.created.BaseBranch; got != tt.want {
+				t.Errorf("OpenPR() base = %q, want %q", got, tt.want)
+			}
+			if labels := client.created.Labels; len(labels) != 1 || labels[0] != "bugfix" {
+				t.Errorf("OpenPR() labels = %v, want only the existing bugfix", labels)
+			}
+
+			if tt.base != "" {
+				return
+			}
+			analysis, err := AnalyzeChanges(ContextOptions{RepoPath: dir})
+			if err != nil {
+				t.Fatalf("AnalyzeChanges() error = %v", err)
+			}
+			if got := analysis.Context.BranchInfo.BaseBranch; got != client.created.BaseBranch {
+				t.Errorf("AnalyzeChanges() base = %q, OpenPR() used %q", got, client.created.BaseBranch)
+			}
+		})
+	}
+
+	for _, base := range []string{"feature", "missing"} {
+		if _, err := OpenPR(OpenPROptions{RepoPath: dir, Title: "Title", Body: "Body", Base: base, Client: &recordingPlatform{}}); err == nil {
+			t.Errorf("OpenPR() accepted base %s", base)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"auto-pr/internal/ui"
+)
+
+// input returns r, or standard input when no reader was given
+func input(r io.Reader) io.Reader {
+	if r == nil {
+		return os.Stdin
+	}
+	return r
+}
+
+// pickMany shows options as a numbered list, with the preselected ones
+// marked, and reads which to keep. An empty answer keeps the preselection,
+// "none" clears it, and otherwise numbers and ranges such as "1,3 5-7" pick
+// the options. Invalid answers are asked again.
+func pickMany(in *bufio.Reader, out io.Writer, title string, options, preselected []string) []string {
+	if len(options) == 0 {
+		return preselected
+	}
+
+	selected := make(map[string]bool, len(preselected))
+	for _, option := range preselected {
+		selected[option] = true
+	}
+
+	fmt.Fprintf(out, "\n%s:\n", title)
+	for i, option := range options {
+		marker := " "
+		if selected[option] {
+			marker = "*"
+		}
+		fmt.Fprintf(out, "  %s %2d) %s\n", marker, i+1, option)
+	}
+
+	for {
+		fmt.Fprint(out, "Select numbers (e.g. 1,3 5-7), \"none\", or Enter to keep * : ")
+		line, err := in.ReadString('\n')
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			return preselected
+		case strings.EqualFold(line, "none"):
+			return []string{}
+		}
+
+		indexes, parseErr := parseSelection(line, len(options))
+		if parseErr == nil {
+			picked := make([]string, 0, len(indexes))
+			for _, index := range indexes {
+				picked = append(picked, options[index])
+			}
+			return picked
+		}
+
+		fmt.Fprintf(out, "%s %v\n", ui.Warning, parseErr)
+		if err != nil {
+			// No more input to retry with
+			return preselected
+		}
+	}
+}
+
+// parseSelection turns an answer such as "1,3 5-7" into zero-based indexes
+// into a list of n options, in the order given and without repeats
+func parseSelection(answer string, n int) ([]int, error) {
+	fields := strings.FieldsFunc(answer, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, field := range fields {
+		start, end := field, field
+		if before, after, found := strings.Cut(field, "-"); found {
+			start, end = before, after
+		}
+
+		from, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", field)
+		}
+		to, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", field)
+		}
+		if from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("selection %q is out of range 1-%d", field, n)
+		}
+
+		for i := from - 1; i < to; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("no options selected")
+	}
+	return indexes, nil
+}
+
+// confirm asks a yes/no question, defaulting to yes
+func confirm(in *bufio.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [Y/n] ", question)
+	line, _ := in.ReadString('\n')
+	return !strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "n")
+}
+
+// confirmNo asks a yes/no question, defaulting to no, so that running
+// without an answer to give (e.g. with no terminal) declines
+func confirmNo(in *bufio.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	line, _ := in.ReadString('\n')
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "y")
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    []int
+		wantErr bool
+	}{
+		{name: "Single number", answer: "2", want: []int{1}},
+		{name: "Commas and spaces", answer: "1, 3 4", want: []int{0, 2, 3}},
+		{name: "Range", answer: "2-4", want: []int{1, 2, 3}},
+		{name: "Repeats are dropped", answer: "3,1-3", want: []int{2, 0, 1}},
+		{name: "Out of range", answer: "6", wantErr: true},
+		{name: "Zero", answer: "0", wantErr: true},
+		{name: "Reversed range", answer: "4-2", wantErr: true},
+		{name: "Not a number", answer: "alice", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSelection(tt.answer, 5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSelection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSelection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickMany(t *testing.T) {
+	options := []string{"bug", "docs", "feature"}
+	preselected := []string{"feature"}
+
+	tests := []struct {
+		name   string
+		answer string
+		want   []string
+	}{
+		{name: "Enter keeps the preselection", answer: "\n", want: []string{"feature"}},
+		{name: "None clears it", answer: "none\n", want: []string{}},
+		{name: "Numbers pick options", answer: "1,2\n", want: []string{"bug", "docs"}},
+		{name: "Invalid answer is asked again", answer: "9\n2\n", want: []string{"docs"}},
+		{name: "End of input keeps the preselection", answer: "", want: []string{"feature"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := bufio.NewReader(strings.NewReader(tt.answer))
+			got := pickMany(in, io.Discard, "Labels", options, preselected)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pickMany() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/ui"
+)
+
+// Preview formats for generated PR/MR content
+const (
+	PreviewFormatPlain    = "plain"
+	PreviewFormatMarkdown = "markdown"
+)
+
+// PrintPRPreview prints the generated PR/MR content in the given format
+func PrintPRPreview(w io.Writer, aiResponse *ai.AIResponse, format string) error {
+	preview, err := RenderPRPreview(aiResponse, format)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, preview)
+	return err
+}
+
+// RenderPRPreview renders the generated PR/MR content. The plain format is the
+// decorated terminal output; markdown renders the title as a heading, the body
+// verbatim and the metadata as a table, ready to paste or redirect to a file.
+func RenderPRPreview(aiResponse *ai.AIResponse, format string) (string, error) {
+	var builder strings.Builder
+
+	switch format {
+	case "", PreviewFormatPlain:
+		fmt.Fprintf(&builder, "%s Title: %s\n", ui.Note, aiResponse.Title)
+		fmt.Fprintf(&builder, "%s Body:\n%s\n", ui.Clipboard, aiResponse.Body)
+		if len(aiResponse.Labels) > 0 {
+			fmt.Fprintf(&builder, "%s Labels: %v\n", ui.Label, aiResponse.Labels)
+		}
+		if len(aiResponse.Reviewers) > 0 {
+			fmt.Fprintf(&builder, "%s Suggested reviewers: %v\n", ui.People, aiResponse.Reviewers)
+		}
+		fmt.Fprintf(&builder, "%s Priority: %s\n", ui.Bolt, aiResponse.Priority)
+		fmt.Fprintf(&builder, "%s Generated by: %s\n", ui.Robot, aiResponse.Provider)
+	case PreviewFormatMarkdown:
+		fmt.Fprintf(&builder, "# %s\n\n", aiResponse.Title)
+		if body := strings.TrimRight(aiResponse.Body, "\n"); body != "" {
+			fmt.Fprintf(&builder, "%s\n\n", body)
+		}
+		builder.WriteString("| Field | Value |\n")
+		builder.WriteString("| --- | --- |\n")
+		if len(aiResponse.Labels) > 0 {
+			fmt.Fprintf(&builder, "| Labels | %s |\n", markdownCell(strings.Join(aiResponse.Labels, ", ")))
+		}
+		if len(aiResponse.Reviewers) > 0 {
+			fmt.Fprintf(&builder, "| Suggested reviewers | %s |\n", markdownCell(strings.Join(aiResponse.Reviewers, ", ")))
+		}
+		fmt.Fprintf(&builder, "| Priority | %s |\n", markdownCell(aiResponse.Priority))
+		fmt.Fprintf(&builder, "| Generated by | %s |\n", markdownCell(string(aiResponse.Provider)))
+	default:
+		return "", ValidatePreviewFormat(format)
+	}
+
+	return builder.String(), nil
+}
+
+// ValidatePreviewFormat rejects unknown preview formats
+func ValidatePreviewFormat(format string) error {
+	switch format {
+	case "", PreviewFormatPlain, PreviewFormatMarkdown:
+		return nil
+	default:
+		return fmt.Errorf("invalid preview format %q, expected %s or %s", format, PreviewFormatPlain, PreviewFormatMarkdown)
+	}
+}
+
+// markdownCell escapes a value for use inside a markdown table cell
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
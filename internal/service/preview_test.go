@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+func TestRenderPRPreview(t *testing.T) {
+	response := &ai.AIResponse{
+		Title:     "Add login page",
+		Body:      "## Summary\n\nAdds a login page.\n",
+		Labels:    []string{"feature", "ui|ux"},
+		Reviewers: []string{"alice"},
+		Priority:  "medium",
+		Provider:  types.AIProviderClaude,
+	}
+
+	t.Run("Markdown", func(t *testing.T) {
+		got, err := RenderPRPreview(response, PreviewFormatMarkdown)
+		if err != nil {
+			t.Fatalf("RenderPRPreview() error = %v", err)
+		}
+
+		want := "# Add login page\n\n" +
+			"## Summary\n\nAdds a login page.\n\n" +
+			"| Field | Value |\n" +
+			"| --- | --- |\n" +
+			"| Labels | feature, ui\\|ux |\n" +
+			"| Suggested reviewers | alice |\n" +
+			"| Priority | medium |\n" +
+			"| Generated by | claude |\n"
+		if got != want {
+			t.Errorf("RenderPRPreview() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("Plain is the default", func(t *testing.T) {
+		plain, err := RenderPRPreview(response, PreviewFormatPlain)
+		if err != nil {
+			t.Fatalf("RenderPRPreview() error = %v", err)
+		}
+		defaulted, _ := RenderPRPreview(response, "")
+		if plain != defaulted {
+			t.Errorf("empty format should render as plain")
+		}
+		if !strings.HasPrefix(plain, "📝 Title: Add login page\n") {
+			t.Errorf("unexpected plain preview:\n%s", plain)
+		}
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		if _, err := RenderPRPreview(response, "html"); err == nil {
+			t.Error("RenderPRPreview() expected error for unknown format")
+		}
+	})
+}
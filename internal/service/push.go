@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"auto-pr/internal/config"
+)
+
+// Whether each command pushes when neither --push, --no-push nor
+// git.auto_push decides
+const (
+	commitPushesByDefault = false
+	shipPushesByDefault   = true
+)
+
+// decidePush resolves whether commit or ship pushes: --push or --no-push
+// when given, then git.auto_push when set, then the command's own default
+func decidePush(push, noPush bool, autoPush *bool, commandDefault bool) (bool, error) {
+	switch {
+	case push && noPush:
+		return false, errors.New("--push can't be combined with --no-push")
+	case push:
+		return true, nil
+	case noPush:
+		return false, nil
+	case autoPush != nil:
+		return *autoPush, nil
+	}
+	return commandDefault, nil
+}
+
+// resolvePush is decidePush with git.auto_push read from the configuration,
+// which is only loaded when neither flag decides
+func resolvePush(push, noPush, commandDefault bool) (bool, error) {
+	if push || noPush {
+		return decidePush(push, noPush, nil, commandDefault)
+	}
+
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return false, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return decidePush(false, false, cfg.Git.AutoPush, commandDefault)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+
+	"github.com/spf13/viper"
+)
+
+func TestDecidePush(t *testing.T) {
+	on, off := true, false
+
+	tests := []struct {
+		name           string
+		push, noPush   bool
+		autoPush       *bool
+		commandDefault bool
+		want           bool
+		wantErr        bool
+	}{
+		{name: "commit default", commandDefault: commitPushesByDefault, want: false},
+		{name: "ship default", commandDefault: shipPushesByDefault, want: true},
+		{name: "auto_push true", autoPush: &on, commandDefault: commitPushesByDefault, want: true},
+		{name: "auto_push false", autoPush: &off, commandDefault: shipPushesByDefault, want: false},
+		{name: "--push", push: true, commandDefault: commitPushesByDefault, want: true},
+		{name: "--push over auto_push false", push: true, autoPush: &off, commandDefault: shipPushesByDefault, want: true},
+		{name: "--no-push", noPush: true, commandDefault: shipPushesByDefault, want: false},
+		{name: "--no-push over auto_push true", noPush: true, autoPush: &on, commandDefault: commitPushesByDefault, want: false},
+		{name: "both flags", push: true, noPush: true, commandDefault: shipPushesByDefault, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decidePush(tt.push, tt.noPush, tt.autoPush, tt.commandDefault)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decidePush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decidePush() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitAndShipPushDefaults(t *testing.T) {
+	// The default branch is one commit ahead of origin/main with a clean tree,
+	// so both commands refuse exactly when they would push
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	if output, err := exec.Command("git", "-C", dir, "commit", "-q", "--allow-empty", "-m", "local").CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, output)
+	}
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "chore: local"})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	tests := []struct {
+		name         string
+		autoPush     string // "" leaves git.auto_push unset
+		push, noPush bool
+		commitPushes bool
+		shipPushes   bool
+	}{
+		{name: "unset", commitPushes: false, shipPushes: true},
+		{name: "auto_push true", autoPush: "true", commitPushes: true, shipPushes: true},
+		{name: "auto_push false", autoPush: "false", commitPushes: false, shipPushes: false},
+		{name: "--push with auto_push false", autoPush: "false", push: true, commitPushes: true, shipPushes: true},
+		{name: "--no-push with auto_push true", autoPush: "true", noPush: true, commitPushes: false, shipPushes: false},
+		{name: "--no-push unset", noPush: true, commitPushes: false, shipPushes: false},
+		{name: "--push unset", push: true, commitPushes: true, shipPushes: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			if tt.autoPush != "" {
+				viper.Set("git.auto_push", tt.autoPush)
+			}
+
+			_, err := Commit(CommitOptions{RepoPath: dir, Message: "local", Amend: true, Push: tt.push, NoPush: tt.noPush, DryRun: true, Out: io.Discard})
+			if pushed := err != nil && strings.Contains(err.Error(), "refusing to push"); pushed != tt.commitPushes {
+				t.Errorf("Commit() pushes = %v, want %v (error %v)", pushed, tt.commitPushes, err)
+			}
+
+			_, err = Ship(ShipOptions{RepoPath: dir, Push: tt.push, NoPush: tt.noPush, NoPR: true, DryRun: true, Out: io.Discard})
+			if pushed := err != nil && strings.Contains(err.Error(), "refusing to push"); pushed != tt.shipPushes {
+				t.Errorf("Ship() pushes = %v, want %v (error %v)", pushed, tt.shipPushes, err)
+			}
+		})
+	}
+
+	if _, err := Commit(CommitOptions{RepoPath: dir, Message: "local", Push: true, NoPush: true, Out: io.Discard}); err == nil {
+		t.Error("Commit() accepted --push with --no-push")
+	}
+}
+
+func TestShipNoPushOverridesAutoPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	remote, dir := filepath.Join(root, "remote.git"), filepath.Join(root, "work")
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("init", "-q", "--bare", remote)
+	run("init", "-q", "-b", "main", dir)
+	run("-C", dir, "remote", "add", "origin", remote)
+	run("-C", dir, "commit", "-q", "--allow-empty", "-m", "init")
+	run("-C", dir, "push", "-q", "origin", "main")
+	run("-C", dir, "checkout", "-q", "-b", "feature/x")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := ai.NewMockClient(&ai.AIResponse{Title: "feat: add file"})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+	t.Cleanup(viper.Reset)
+	viper.Set("git.auto_push", "true")
+
+	result, err := Ship(ShipOptions{RepoPath: dir, Message: "feat: add file", NoPush: true, NoPR: true, Out: io.Discard})
+	if err != nil {
+		t.Fatalf("Ship() error = %v", err)
+	}
+	if result.CommitHash == "" {
+		t.Error("Ship() didn't commit")
+	}
+	if refs := run("-C", remote, "for-each-ref", "--format=%(refname)"); strings.Contains(refs, "feature/x") {
+		t.Errorf("Ship() pushed with --no-push despite git.auto_push: %s", refs)
+	}
+}
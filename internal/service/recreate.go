@@ -0,0 +1,21 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// confirmRecreate warns that recreating pr leaves its discussion behind and,
+// unless opts.Yes, asks whether to go ahead
+func confirmRecreate(opts CreatePROptions, in *bufio.Reader, out io.Writer, pr *types.PullRequest, entity string) bool {
+	fmt.Fprintf(out, "%s --recreate closes #%d (%s) and opens a new %s in its place\n", ui.Warning, pr.Number, pr.URL, entity)
+	fmt.Fprintf(out, "%s Its comments, reviews and discussion threads stay on the closed one and don't carry over\n", ui.Warning)
+	if opts.Yes {
+		return true
+	}
+	return confirmNo(in, out, fmt.Sprintf("%s Close #%d and create a new %s?", ui.Rocket, pr.Number, entity))
+}
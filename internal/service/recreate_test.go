@@ -0,0 +1,54 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestConfirmRecreate(t *testing.T) {
+	pr := &types.PullRequest{Number: 12, URL: "https://github.com/acme/widgets/pull/12"}
+
+	tests := []struct {
+		name  string
+		yes   bool
+		input string
+		want  bool
+	}{
+		{name: "yes flag skips the prompt", yes: true, want: true},
+		{name: "confirmed", input: "y\n", want: true},
+		{name: "declined", input: "n\n", want: false},
+		{name: "defaults to no", input: "\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			in := bufio.NewReader(strings.NewReader(tt.input))
+			if got := confirmRecreate(CreatePROptions{Yes: tt.yes}, in, &out, pr, "PR"); got != tt.want {
+				t.Errorf("confirmRecreate() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "don't carry over") {
+				t.Errorf("confirmRecreate() didn't warn about the comment thread:\n%s", out.String())
+			}
+			if prompted := strings.Contains(out.String(), "[y/N]"); prompted == tt.yes {
+				t.Errorf("confirmRecreate() prompted = %v with Yes = %v", prompted, tt.yes)
+			}
+		})
+	}
+}
+
+func TestCreatePRRecreateConflicts(t *testing.T) {
+	for _, opts := range []CreatePROptions{
+		{Recreate: true, AmendPR: true},
+		{Recreate: true, SyncMetadata: true},
+	} {
+		_, err := CreatePR(opts)
+		if err == nil || !strings.Contains(err.Error(), "--recreate") {
+			t.Errorf("CreatePR(%+v) error = %v, want one about --recreate", opts, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"auto-pr/internal/git"
+	"auto-pr/pkg/types"
+)
+
+// releaseSection is a release-notes heading and the conventional commit
+// type listed under it
+type releaseSection struct {
+	kind    string
+	heading string
+}
+
+// releaseSections are listed in this order; commits of other types, or
+// without a conventional type, go under "Other Changes"
+var releaseSections = []releaseSection{
+	{kind: "feat", heading: "Features"},
+	{kind: "fix", heading: "Bug Fixes"},
+	{kind: "perf", heading: "Performance"},
+	{kind: "refactor", heading: "Refactoring"},
+	{kind: "docs", heading: "Documentation"},
+}
+
+// conventionalSubject splits "type(scope)!: description" commit subjects
+var conventionalSubject = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// releaseCommit is a commit as it appears in the release notes
+type releaseCommit struct {
+	kind        string
+	scope       string
+	description string
+	breaking    bool
+}
+
+// parseReleaseCommit reads the conventional type, scope and breaking marker
+// from a commit, keeping the whole subject as the description otherwise
+func parseReleaseCommit(commit types.CommitInfo) releaseCommit {
+	_, breakingTrailer := commit.Trailers["BREAKING-CHANGE"]
+	parsed := releaseCommit{description: commit.Message, breaking: breakingTrailer}
+
+	if match := conventionalSubject.FindStringSubmatch(commit.Message); match != nil {
+		parsed.kind = strings.ToLower(match[1])
+		parsed.scope = match[2]
+		parsed.breaking = parsed.breaking || match[3] != ""
+		parsed.description = match[4]
+	}
+	return parsed
+}
+
+// releaseNotes formats the commits since tag as markdown release notes,
+// grouped by conventional commit type with breaking changes first
+func releaseNotes(tag string, commits []types.CommitInfo) string {
+	notes := fmt.Sprintf("## Changes since %s\n", tag)
+	if len(commits) == 0 {
+		return notes + "\nNo commits since the tag.\n"
+	}
+	return notes + commitSections(commits)
+}
+
+// commitSections lists commits under "### <type>" headings by conventional
+// commit type, breaking changes first, leaving out empty sections
+func commitSections(commits []types.CommitInfo) string {
+	grouped := make(map[string][]string)
+	var breaking []string
+
+	for _, commit := range commits {
+		parsed := parseReleaseCommit(commit)
+		entry := parsed.description
+		if parsed.scope != "" {
+			entry = fmt.Sprintf("**%s:** %s", parsed.scope, entry)
+		}
+		entry = fmt.Sprintf("- %s (%s)", entry, git.ShortHash(commit.Hash))
+
+		if parsed.breaking {
+			breaking = append(breaking, entry)
+		}
+		heading := releaseHeading(parsed.kind)
+		grouped[heading] = append(grouped[heading], entry)
+	}
+
+	var sections strings.Builder
+	writeSection := func(heading string, entries []string) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&sections, "\n### %s\n\n%s\n", heading, strings.Join(entries, "\n"))
+	}
+
+	writeSection("Breaking Changes", breaking)
+	for _, section := range releaseSections {
+		writeSection(section.heading, grouped[section.heading])
+	}
+	writeSection("Other Changes", grouped["Other Changes"])
+	return sections.String()
+}
+
+// releaseHeading returns the release-notes heading for a commit type
+func releaseHeading(kind string) string {
+	for _, section := range releaseSections {
+		if section.kind == kind {
+			return section.heading
+		}
+	}
+	return "Other Changes"
+}
+
+// releasePrompt asks the AI for a release PR/MR summary; the grouped list of
+// changes is added to the description separately
+func releasePrompt(tag string) string {
+	return fmt.Sprintf("Generate a title and description for a release pull request covering every change since the %s tag, based on the provided git changes and commit history. "+
+		"Use the description for a short overview of the release: its highlights, anything users must do when upgrading, and any breaking changes. "+
+		"Don't list every commit; a list of the changes grouped by type is appended to the description automatically.", tag)
+}
+
+// sinceTagStatus returns a copy of status with the analysis based on tag, so
+// the context covers everything since it while the PR/MR still targets the
+// base branch
+func sinceTagStatus(gitAnalyzer *git.Analyzer, status *types.GitStatus, tag string) *types.GitStatus {
+	release := *status
+	release.BaseBranch = tag
+	release.CommitsAhead, release.CommitsBehind, _ = gitAnalyzer.CommitCounts(tag)
+	return &release
+}
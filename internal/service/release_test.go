@@ -0,0 +1,50 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestReleaseNotes(t *testing.T) {
+	commits := []types.CommitInfo{
+		{Hash: "aaaaaaaa1111", Message: "feat(api)!: drop the v1 endpoints"},
+		{Hash: "bbbbbbbb2222", Message: "fix: handle empty input"},
+		{Hash: "cccccccc3333", Message: "feat: add CSV export"},
+		{Hash: "dddddddd4444", Message: "Update dependencies"},
+		{Hash: "eeeeeeee5555", Message: "chore: tidy", Trailers: map[string][]string{"BREAKING-CHANGE": {"config moved"}}},
+	}
+
+	notes := releaseNotes("v1.2.0", commits)
+
+	wantInOrder := []string{
+		"## Changes since v1.2.0",
+		"### Breaking Changes",
+		"- **api:** drop the v1 endpoints (aaaaaaaa)",
+		"- tidy (eeeeeeee)",
+		"### Features",
+		"- **api:** drop the v1 endpoints (aaaaaaaa)",
+		"- add CSV export (cccccccc)",
+		"### Bug Fixes",
+		"- handle empty input (bbbbbbbb)",
+		"### Other Changes",
+		"- Update dependencies (dddddddd)",
+		"- tidy (eeeeeeee)",
+	}
+	rest := notes
+	for _, want := range wantInOrder {
+		i := strings.Index(rest, want)
+		if i < 0 {
+			t.Fatalf("releaseNotes() missing %q in order:\n%s", want, notes)
+		}
+		rest = rest[i+len(want):]
+	}
+	if strings.Contains(notes, "### Documentation") {
+		t.Errorf("releaseNotes() listed an empty section:\n%s", notes)
+	}
+
+	if empty := releaseNotes("v1.2.0", nil); !strings.Contains(empty, "No commits since the tag") {
+		t.Errorf("releaseNotes() with no commits = %q", empty)
+	}
+}
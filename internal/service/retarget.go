@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"auto-pr/internal/git"
+	"auto-pr/internal/platforms"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// RetargetOptions configures Retarget
+type RetargetOptions struct {
+	RepoPath  string
+	Base      string // Branch the PR/MR should merge into instead
+	AutoLogin bool
+	DryRun    bool
+	Out       io.Writer
+	In        io.Reader // Answers for the login prompt; defaults to standard input
+}
+
+// Retarget changes the base branch of the current branch's open PR/MR,
+// keeping its description, reviews and discussion. The new base must exist,
+// here or on origin, and differ from the branch itself. It returns the
+// updated PR/MR, or the current one when it already targets the base or in
+// dry-run mode.
+func Retarget(opts RetargetOptions) (*types.PullRequest, error) {
+	out := output(opts.Out)
+
+	if opts.Base == "" {
+		return nil, errors.New("no base branch given; pass the branch the PR/MR should target with --base")
+	}
+
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if status.DetachedHead {
+		return nil, git.ErrDetachedHead
+	}
+	if opts.Base == status.CurrentBranch {
+		return nil, fmt.Errorf("%s is the PR/MR's own branch and can't be its base", opts.Base)
+	}
+	if !gitAnalyzer.BranchExists(opts.Base) {
+		return nil, fmt.Errorf("branch %s not found locally or on origin; fetch it or check the name", opts.Base)
+	}
+
+	platform, err := platforms.DetectPlatform(status.RemoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+	platformClient, err := newPlatformClient(platform, status.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureAuthenticated(bufio.NewReader(input(opts.In)), out, platformClient, opts.AutoLogin); err != nil {
+		return nil, err
+	}
+
+	pr, err := platformClient.GetExistingPR(status.CurrentBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing PR/MR: %w", err)
+	}
+	if pr == nil {
+		return nil, fmt.Errorf("no open %s for branch %s; create one with auto-pr create", getEntityName(platform), status.CurrentBranch)
+	}
+
+	if pr.BaseBranch == opts.Base {
+		fmt.Fprintf(out, "%s #%d already targets %s\n", ui.Success, pr.Number, opts.Base)
+		fmt.Fprintf(out, "%s %s\n", ui.Link, pr.URL)
+		return pr, nil
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(out, "%s Dry run - would retarget #%d from %s to %s\n", ui.Search, pr.Number, pr.BaseBranch, opts.Base)
+		return pr, nil
+	}
+
+	updated, err := platformClient.RetargetPullRequest(pr.Number, opts.Base)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "%s Retargeted #%d from %s to %s\n", ui.Merge, updated.Number, pr.BaseBranch, updated.BaseBranch)
+	fmt.Fprintf(out, "%s %s\n", ui.Link, updated.URL)
+	return updated, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRetargetValidatesBase(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main", "develop"})
+	if output, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "feature/retarget").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v\n%s", err, output)
+	}
+
+	tests := []struct {
+		name    string
+		base    string
+		wantErr string
+	}{
+		{name: "no base", base: "", wantErr: "no base branch given"},
+		{name: "own branch", base: "feature/retarget", wantErr: "own branch"},
+		{name: "missing branch", base: "release", wantErr: "branch release not found"},
+		// The repository has no remote to reach, so a valid base only gets
+		// as far as looking up the platform
+		{name: "remote-tracking branch", base: "develop", wantErr: "platform"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Retarget(RetargetOptions{RepoPath: dir, Base: tt.base, DryRun: true, Out: io.Discard})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Retarget() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package service implements the commit, PR/MR creation and ship workflows
+// shared by the CLI commands and the MCP server
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"auto-pr/internal/config"
+	"auto-pr/internal/git"
+	"auto-pr/pkg/types"
+)
+
+// output returns w, or standard output when no writer was given
+func output(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// protectedBranchError explains why a push to a protected branch was refused
+func protectedBranchError(branch string) error {
+	return fmt.Errorf("refusing to push to protected branch '%s'; switch to a feature branch or pass --force", branch)
+}
+
+// protectedCommitError explains why a commit to a protected branch was refused
+func protectedCommitError(branch string) error {
+	return fmt.Errorf("refusing to commit to protected branch '%s'; switch to a feature branch or pass --force", branch)
+}
+
+// unmergedFilesError refuses to commit while conflicts are unresolved
+func unmergedFilesError(files []string) error {
+	return fmt.Errorf("unmerged files with conflicts: %s; resolve them and stage the result with git add first", strings.Join(files, ", "))
+}
+
+// openRepository opens the git repository at repoPath, defaulting to the
+// current directory
+func openRepository(repoPath string) (*git.Analyzer, error) {
+	if repoPath == "" {
+		repoPath = "."
+	}
+
+	gitAnalyzer, err := git.NewAnalyzer(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize git analyzer: %w", err)
+	}
+
+	if !gitAnalyzer.IsGitRepository() {
+		return nil, fmt.Errorf("not in a git repository")
+	}
+
+	// A broken config is reported by the commands that load it themselves
+	if cfg, err := config.LoadConfigWithViper(); err == nil {
+		gitAnalyzer.SetBaseBranchCandidates(cfg.Git.BaseBranchCandidates)
+	}
+
+	return gitAnalyzer, nil
+}
+
+// Helper functions
+func removeDuplicates(slice []string) []string {
+	keys := make(map[string]bool)
+	var result []string
+	for _, item := range slice {
+		if !keys[item] {
+			keys[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func getEntityName(platform types.PlatformType) string {
+	switch platform {
+	case types.PlatformGitHub:
+		return "Pull Request"
+	case types.PlatformGitLab:
+		return "Merge Request"
+	default:
+		return "PR/MR"
+	}
+}
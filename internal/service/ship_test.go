@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/git"
+	"auto-pr/pkg/types"
+)
+
+func TestMostCommonBranchPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		branches []string
+		want     string
+	}{
+		{
+			name:     "No remote branches",
+			branches: nil,
+			want:     "feature/",
+		},
+		{
+			name: "Most common prefix wins",
+			branches: []string{
+				"  origin/HEAD -> origin/main",
+				"  origin/main",
+				"  origin/fix/login",
+				"  origin/fix/crash",
+				"  origin/feature/search",
+			},
+			want: "fix/",
+		},
+		{
+			name: "Ties break alphabetically",
+			branches: []string{
+				"  origin/feat/a",
+				"  origin/chore/b",
+				"  upstream/docs/c",
+			},
+			want: "chore/",
+		},
+		{
+			name:     "Branches without a prefix",
+			branches: []string{"  origin/main", "  origin/develop"},
+			want:     "feature/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order varies between runs, so check several times
+			for i := 0; i < 20; i++ {
+				if got := mostCommonBranchPrefix(tt.branches); got != tt.want {
+					t.Fatalf("mostCommonBranchPrefix() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestShipRefusesToPushProtectedBranch(t *testing.T) {
+	// The default branch is one commit ahead of origin/main with a clean tree
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	if output, err := exec.Command("git", "-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "local").CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, output)
+	}
+
+	_, err := Ship(ShipOptions{RepoPath: dir, NoPR: true, Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "refusing to push to protected branch") {
+		t.Errorf("Ship() error = %v, want protected branch error", err)
+	}
+
+	_, err = Commit(CommitOptions{RepoPath: dir, Message: "more", Amend: true, Push: true, Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "refusing to push to protected branch") {
+		t.Errorf("Commit() error = %v, want protected branch error", err)
+	}
+}
+
+func TestShipReturnsCommitHash(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	if output, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "feature/result").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	result, err := Ship(ShipOptions{RepoPath: dir, Message: "Add new file", NoPush: true, NoPR: true, Out: io.Discard})
+	if err != nil {
+		t.Fatalf("Ship() error = %v", err)
+	}
+
+	head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CommitHash != strings.TrimSpace(string(head)) {
+		t.Errorf("Ship() CommitHash = %q, want HEAD %q", result.CommitHash, strings.TrimSpace(string(head)))
+	}
+	if result.PullRequest != nil {
+		t.Errorf("Ship() PullRequest = %+v, want nil with NoPR", result.PullRequest)
+	}
+}
+
+func TestCommitAndShipRefuseUnmergedFiles(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	run := func(args ...string) error {
+		return exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...).Run()
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "shared.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both branches change the same line, so merging them conflicts
+	if err := run("checkout", "-q", "-b", "feature/conflict"); err != nil {
+		t.Fatal(err)
+	}
+	write("feature\n")
+	if err := run("add", "shared.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("commit", "-q", "-m", "feature change"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("checkout", "-q", "-b", "feature/other", "HEAD~1"); err != nil {
+		t.Fatal(err)
+	}
+	write("other\n")
+	if err := run("add", "shared.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("commit", "-q", "-m", "other change"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("merge", "-q", "feature/conflict"); err == nil {
+		t.Fatal("git merge succeeded, want a conflict")
+	}
+
+	_, err := Commit(CommitOptions{RepoPath: dir, Message: "merge", StageAll: true, Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "unmerged files with conflicts: shared.txt") {
+		t.Errorf("Commit() error = %v, want unmerged files error", err)
+	}
+
+	_, err = Ship(ShipOptions{RepoPath: dir, Message: "merge", NoPush: true, NoPR: true, Out: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "unmerged files with conflicts: shared.txt") {
+		t.Errorf("Ship() error = %v, want unmerged files error", err)
+	}
+}
+
+func TestShipSkipsAlreadyShippedState(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	if output, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "feature/rerun").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	opts := ShipOptions{RepoPath: dir, Message: "Add new file", NoPush: true, NoPR: true, Out: io.Discard}
+	first, err := Ship(opts)
+	if err != nil {
+		t.Fatalf("first Ship() error = %v", err)
+	}
+	if first.AlreadyShipped || first.CommitHash == "" {
+		t.Fatalf("first Ship() = %+v, want a new commit", first)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", shipStateFileName)); err != nil {
+		t.Fatalf("ship state not recorded: %v", err)
+	}
+
+	second, err := Ship(opts)
+	if err != nil {
+		t.Fatalf("second Ship() error = %v", err)
+	}
+	if !second.AlreadyShipped || second.CommitHash != "" {
+		t.Errorf("second Ship() = %+v, want already shipped", second)
+	}
+
+	// Pushing is a step the first run didn't do
+	gitAnalyzer, err := git.NewAnalyzer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous := alreadyShipped(gitAnalyzer, "feature/rerun", ShipOptions{NoPR: true}); previous != nil {
+		t.Errorf("alreadyShipped() = %+v for a run that also pushes, want nil", previous)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	third, err := Ship(opts)
+	if err != nil {
+		t.Fatalf("third Ship() error = %v", err)
+	}
+	if third.AlreadyShipped || third.CommitHash == "" || third.CommitHash == first.CommitHash {
+		t.Errorf("third Ship() = %+v, want a new commit after changing a file", third)
+	}
+}
+
+func TestShipTypeWithoutPlannedMessage(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	if output, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "feature/typed").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	// The plan leaves out the commit message, so Commit generates one
+	mock := ai.NewMockClient(&ai.AIResponse{Title: `{"pr_title": "Add new file"}`})
+	defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, nil })()
+
+	if _, err := Ship(ShipOptions{RepoPath: dir, Type: "fix", NoPush: true, NoPR: true, Out: io.Discard}); err != nil {
+		t.Fatalf("Ship() error = %v", err)
+	}
+
+	subject, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%s").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(subject), "fix: ") {
+		t.Errorf("commit subject = %q, want the fix type", strings.TrimSpace(string(subject)))
+	}
+}
+
+func TestWorkflowPlanStopsOnGatherError(t *testing.T) {
+	tests := []struct {
+		name      string
+		clientErr error // error from creating the AI client
+		removeGit bool  // remove .git after opening the repository so the git calls fail
+		want      string
+	}{
+		{name: "AI client error", clientErr: errors.New("claude not found"), want: "failed to create AI client"},
+		{name: "Git error", removeGit: true, want: "failed to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepoWithRemoteBranches(t, []string{"main"})
+			gitAnalyzer, err := git.NewAnalyzer(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			status, err := gitAnalyzer.GetStatus()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.removeGit {
+				if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			mock := ai.NewMockClient(&ai.AIResponse{Title: `{"pr_title": "Title"}`})
+			defer ai.SetClientFactory(func(types.AIConfig) (ai.AIClient, error) { return mock, tt.clientErr })()
+
+			plan, err := generateComprehensiveWorkflowPlan(gitAnalyzer, status, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("generateComprehensiveWorkflowPlan() = %+v, %v, want error containing %q", plan, err, tt.want)
+			}
+			if calls := mock.Calls(); len(calls) != 0 {
+				t.Errorf("generateComprehensiveWorkflowPlan() made %d AI calls after a failure, want 0", len(calls))
+			}
+		})
+	}
+}
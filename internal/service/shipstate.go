@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"auto-pr/internal/git"
+)
+
+// shipStateFileName is the file inside .git recording the last state ship
+// finished with
+const shipStateFileName = "auto-pr-state"
+
+// shipState fingerprints a repository state (branch, HEAD and a hash of the
+// uncommitted changes) and records which steps ship completed for it
+type shipState struct {
+	Branch         string    `json:"branch"`
+	Head           string    `json:"head"`
+	DiffHash       string    `json:"diff_hash"`
+	Pushed         bool      `json:"pushed"`
+	PullRequest    bool      `json:"pull_request"`
+	PullRequestURL string    `json:"pull_request_url,omitempty"`
+	ShippedAt      time.Time `json:"shipped_at"`
+}
+
+// currentShipState fingerprints the repository, on branch, as it is now,
+// leaving the completed steps unset
+func currentShipState(gitAnalyzer *git.Analyzer, branch string) (*shipState, error) {
+	head, err := gitAnalyzer.GetHeadHash()
+	if err != nil {
+		return nil, err
+	}
+	diffHash, err := gitAnalyzer.WorkTreeHash()
+	if err != nil {
+		return nil, err
+	}
+	return &shipState{Branch: branch, Head: head, DiffHash: diffHash}, nil
+}
+
+// sameState reports whether two fingerprints describe the same branch, HEAD
+// and uncommitted changes
+func (s *shipState) sameState(other *shipState) bool {
+	return s.Branch == other.Branch && s.Head == other.Head && s.DiffHash == other.DiffHash
+}
+
+// covers reports whether a ship that ended in this state already did
+// everything opts asks for from current, making the new run a no-op
+func (s *shipState) covers(current *shipState, opts ShipOptions) bool {
+	return s.sameState(current) && (s.Pushed || opts.NoPush) && (s.PullRequest || opts.NoPR)
+}
+
+// shipStatePath returns where the ship state of the repository is kept.
+// Worktrees share it through the common git directory; the branch in the
+// fingerprint keeps their states apart.
+func shipStatePath(gitAnalyzer *git.Analyzer) (string, error) {
+	gitDir, err := gitAnalyzer.CommonGitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, shipStateFileName), nil
+}
+
+func readShipState(path string) (*shipState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state shipState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func writeShipState(path string, state *shipState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// alreadyShipped returns the recorded state when the last ship ended with the
+// repository, on branch, exactly as it is now and did all opts asks for, or
+// nil when this run has something to do
+func alreadyShipped(gitAnalyzer *git.Analyzer, branch string, opts ShipOptions) *shipState {
+	path, err := shipStatePath(gitAnalyzer)
+	if err != nil {
+		return nil
+	}
+	previous, err := readShipState(path)
+	if err != nil {
+		return nil
+	}
+	current, err := currentShipState(gitAnalyzer, branch)
+	if err != nil || !previous.covers(current, opts) {
+		return nil
+	}
+	return previous
+}
+
+// recordShipState saves the state a completed ship left the repository in,
+// with the steps it did. A failed write only means the next run can't tell
+// it is a re-run.
+func recordShipState(gitAnalyzer *git.Analyzer, branch string, opts ShipOptions, result *ShipResult) {
+	path, err := shipStatePath(gitAnalyzer)
+	if err != nil {
+		return
+	}
+	state, err := currentShipState(gitAnalyzer, branch)
+	if err != nil {
+		return
+	}
+
+	state.Pushed = !opts.NoPush
+	state.PullRequest = !opts.NoPR
+	if result.PullRequest != nil {
+		state.PullRequestURL = result.PullRequest.URL
+	}
+	state.ShippedAt = time.Now()
+	_ = writeShipState(path, state)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// diffSummaryCounts reads the line counts from a diff summary such as
+// "3 files changed, 40 additions, 12 deletions"
+var diffSummaryCounts = regexp.MustCompile(`(\d+) additions, (\d+) deletions`)
+
+// computeSizeLabel returns the size label for the changed lines in a diff
+// summary: that of the first threshold the count stays below, or "" when
+// the summary has no counts or no threshold fits
+func computeSizeLabel(summary string, thresholds []types.SizeThreshold) string {
+	match := diffSummaryCounts.FindStringSubmatch(summary)
+	if match == nil {
+		return ""
+	}
+	additions, _ := strconv.Atoi(match[1])
+	deletions, _ := strconv.Atoi(match[2])
+
+	for _, threshold := range thresholds {
+		if threshold.Below == 0 || additions+deletions < threshold.Below {
+			return threshold.Label
+		}
+	}
+	return ""
+}
+
+// hasSizeLabel reports whether labels include any of the size labels
+func hasSizeLabel(labels []string, thresholds []types.SizeThreshold) bool {
+	return len(withoutSizeLabels(labels, thresholds)) < len(labels)
+}
+
+// withoutSizeLabels returns labels without the size labels, compared
+// case-insensitively like label names on GitHub and GitLab
+func withoutSizeLabels(labels []string, thresholds []types.SizeThreshold) []string {
+	var kept []string
+	for _, label := range labels {
+		isSize := false
+		for _, threshold := range thresholds {
+			if strings.EqualFold(label, threshold.Label) {
+				isSize = true
+				break
+			}
+		}
+		if !isSize {
+			kept = append(kept, label)
+		}
+	}
+	return kept
+}
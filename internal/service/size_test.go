@@ -0,0 +1,53 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestComputeSizeLabel(t *testing.T) {
+	thresholds := []types.SizeThreshold{
+		{Label: "size/S", Below: 50},
+		{Label: "size/M", Below: 250},
+		{Label: "size/L"},
+	}
+
+	tests := []struct {
+		name       string
+		summary    string
+		thresholds []types.SizeThreshold
+		want       string
+	}{
+		{name: "Small", summary: "2 files changed, 30 additions, 10 deletions", thresholds: thresholds, want: "size/S"},
+		{name: "At a threshold", summary: "3 files changed, 40 additions, 10 deletions", thresholds: thresholds, want: "size/M"},
+		{name: "Large", summary: "9 files changed, 300 additions, 0 deletions (2 generated files not listed)", thresholds: thresholds, want: "size/L"},
+		{name: "No catch-all", summary: "9 files changed, 300 additions, 0 deletions", thresholds: thresholds[:2], want: ""},
+		{name: "Turned off", summary: "2 files changed, 30 additions, 10 deletions", want: ""},
+		{name: "No counts", summary: "changes", thresholds: thresholds, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeSizeLabel(tt.summary, tt.thresholds); got != tt.want {
+				t.Errorf("computeSizeLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutSizeLabels(t *testing.T) {
+	thresholds := []types.SizeThreshold{{Label: "size/S", Below: 50}, {Label: "size/L"}}
+	labels := []string{"bug", "Size/S", "backend"}
+
+	if !hasSizeLabel(labels, thresholds) {
+		t.Error("hasSizeLabel() = false, want true")
+	}
+	if got, want := withoutSizeLabels(labels, thresholds), []string{"bug", "backend"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("withoutSizeLabels() = %v, want %v", got, want)
+	}
+	if hasSizeLabel([]string{"bug"}, thresholds) {
+		t.Error("hasSizeLabel() = true for labels without a size label")
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/platforms"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// splitField is the extra response field the AI puts its suggested PRs in
+const splitField = "splits"
+
+// SplitOptions configures SuggestSplit
+type SplitOptions struct {
+	RepoPath string
+	Paths    []string // Limit the analysis to these paths
+	Verbose  bool
+	Out      io.Writer
+}
+
+// FileCluster is a group of changed files in the same directory or module
+type FileCluster struct {
+	Name      string
+	Files     []types.FileChange
+	Additions int
+	Deletions int
+}
+
+// SuggestedPR is one of the smaller PRs/MRs the AI proposes splitting into
+type SuggestedPR struct {
+	Title  string   `json:"title"`
+	Reason string   `json:"reason"`
+	Files  []string `json:"files"`
+}
+
+// SplitSuggestion is the advice SuggestSplit gives on splitting a branch
+type SplitSuggestion struct {
+	Clusters     []FileCluster
+	PullRequests []SuggestedPR // In the order to merge them
+	Unassigned   []string      // Changed files no suggested PR/MR includes
+	Advice       string
+}
+
+// SuggestSplit clusters the branch's changed files by directory and asks the
+// AI how to split the branch into smaller PRs/MRs, printing the suggestion.
+// It only advises: no branches, commits or PRs/MRs are created.
+func SuggestSplit(opts SplitOptions) (*SplitSuggestion, error) {
+	out := output(opts.Out)
+
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	inferBaseBranch(gitAnalyzer, status)
+
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// The platform only words the prompt, so an unknown remote is fine
+	platform, err := platforms.DetectPlatform(gitAnalyzer.GetRemoteURL())
+	if err != nil {
+		platform = ""
+	}
+
+	aiContext, err := buildPRContext(gitAnalyzer, status, platform, cfg.Git, cfg.Git.CommitLimit, opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := &SplitSuggestion{Clusters: clusterFiles(aiContext.FileChanges)}
+	if len(aiContext.FileChanges) < 2 {
+		fmt.Fprintf(out, "%s %s changes %d file(s) since %s, nothing to split\n", ui.Success,
+			status.CurrentBranch, len(aiContext.FileChanges), status.BaseBranch)
+		return suggestion, nil
+	}
+
+	aiClient, err := ai.NewClient(cfg.AI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	if opts.Verbose {
+		fmt.Fprintf(out, "Clustered %d files into %d groups\n", len(aiContext.FileChanges), len(suggestion.Clusters))
+	}
+
+	aiContext.ExtraFields = map[string]string{
+		splitField: `array of the suggested PRs in the order to merge them, each an object with "title", "reason" (why these changes belong together) and "files" (the changed file paths it includes)`,
+	}
+
+	fmt.Fprintf(out, "%s Asking AI how to split %s...\n", ui.Robot, status.CurrentBranch)
+	response, err := aiClient.GenerateContent(aiContext, splitPrompt(suggestion.Clusters))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate split suggestion: %w", err)
+	}
+
+	suggestion.Advice = strings.TrimSpace(response.Body)
+	suggestion.PullRequests, suggestion.Unassigned = decodeSplits(response.Extra[splitField], aiContext.FileChanges)
+
+	fmt.Fprintln(out)
+	printSplitSuggestion(out, status.CurrentBranch, status.BaseBranch, suggestion)
+	return suggestion, nil
+}
+
+// clusterFiles groups changed files by their directory, up to two levels
+// deep (e.g. internal/service), largest groups first
+func clusterFiles(changes []types.FileChange) []FileCluster {
+	byName := make(map[string]*FileCluster)
+	var names []string
+	for _, change := range changes {
+		name := clusterName(change.Path)
+		cluster, ok := byName[name]
+		if !ok {
+			cluster = &FileCluster{Name: name}
+			byName[name] = cluster
+			names = append(names, name)
+		}
+		cluster.Files = append(cluster.Files, change)
+		cluster.Additions += change.Additions
+		cluster.Deletions += change.Deletions
+	}
+
+	clusters := make([]FileCluster, 0, len(names))
+	for _, name := range names {
+		clusters = append(clusters, *byName[name])
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Files) != len(clusters[j].Files) {
+			return len(clusters[i].Files) > len(clusters[j].Files)
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
+	return clusters
+}
+
+// clusterName returns the cluster a file belongs to: its directory cut to two
+// levels, or "(root)" for files at the top of the repository
+func clusterName(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return "(root)"
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, "/")
+}
+
+// splitPrompt asks the AI for reviewable PR boundaries, given the clusters
+func splitPrompt(clusters []FileCluster) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("This branch is too large to review as one pull request. ")
+	prompt.WriteString("Propose how to split it into smaller pull requests that can each be reviewed and merged on their own, ")
+	prompt.WriteString("grouping changes by purpose rather than strictly by directory. ")
+	prompt.WriteString("Keep changes that depend on each other together, and order the PRs so each builds on the ones before it. ")
+	prompt.WriteString("Put every changed file in exactly one PR. Suggest a single PR if the changes don't split well.\n\n")
+
+	prompt.WriteString("The changed files, clustered by directory:\n")
+	for _, cluster := range clusters {
+		fmt.Fprintf(&prompt, "- %s: %d files, +%d -%d\n", cluster.Name, len(cluster.Files), cluster.Additions, cluster.Deletions)
+	}
+
+	prompt.WriteString("\nUse \"title\" for a short name for the split and \"body\" for brief advice on carrying it out.")
+	return prompt.String()
+}
+
+// decodeSplits reads the suggested PRs from the AI's response field, keeping
+// only files that are actually changed, and returns the changed files none
+// of them includes
+func decodeSplits(field interface{}, changes []types.FileChange) ([]SuggestedPR, []string) {
+	var suggested []SuggestedPR
+	if field != nil {
+		// The field arrives as generic JSON; re-encode it to decode the structs
+		if data, err := json.Marshal(field); err == nil {
+			_ = json.Unmarshal(data, &suggested)
+		}
+	}
+
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		changed[change.Path] = true
+	}
+
+	assigned := make(map[string]bool)
+	var prs []SuggestedPR
+	for _, pr := range suggested {
+		var files []string
+		for _, file := range pr.Files {
+			if changed[file] && !assigned[file] {
+				assigned[file] = true
+				files = append(files, file)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		pr.Files = files
+		prs = append(prs, pr)
+	}
+
+	var unassigned []string
+	for _, change := range changes {
+		if !assigned[change.Path] {
+			unassigned = append(unassigned, change.Path)
+		}
+	}
+	return prs, unassigned
+}
+
+// printSplitSuggestion writes the clusters and suggested PRs as a report
+func printSplitSuggestion(w io.Writer, branch, base string, suggestion *SplitSuggestion) {
+	fmt.Fprintf(w, "%s Split suggestion for %s\n", ui.Clipboard, branch)
+	fmt.Fprintln(w, "==========================")
+
+	fmt.Fprintf(w, "\n%s Changed files by directory (%d groups):\n", ui.Folder, len(suggestion.Clusters))
+	for _, cluster := range suggestion.Clusters {
+		fmt.Fprintf(w, "   %s: %d files, +%d -%d\n", cluster.Name, len(cluster.Files), cluster.Additions, cluster.Deletions)
+	}
+
+	switch len(suggestion.PullRequests) {
+	case 0:
+		fmt.Fprintf(w, "\n%s The AI didn't suggest any split\n", ui.Warning)
+	case 1:
+		fmt.Fprintf(w, "\n%s Keep this as a single PR/MR: %s\n", ui.Success, suggestion.PullRequests[0].Title)
+	default:
+		fmt.Fprintf(w, "\n%s Suggested PRs/MRs, in merge order:\n", ui.Merge)
+		for i, pr := range suggestion.PullRequests {
+			fmt.Fprintf(w, "\n   %d. %s\n", i+1, pr.Title)
+			if pr.Reason != "" {
+				fmt.Fprintf(w, "      Why: %s\n", pr.Reason)
+			}
+			for _, file := range pr.Files {
+				fmt.Fprintf(w, "      - %s\n", file)
+			}
+		}
+	}
+
+	if len(suggestion.Unassigned) > 0 {
+		fmt.Fprintf(w, "\n%s Not in any suggested PR/MR:\n", ui.Warning)
+		for _, file := range suggestion.Unassigned {
+			fmt.Fprintf(w, "   - %s\n", file)
+		}
+	}
+
+	if suggestion.Advice != "" {
+		fmt.Fprintf(w, "\n%s Advice:\n%s\n", ui.Tip, suggestion.Advice)
+	}
+
+	if len(suggestion.PullRequests) > 1 {
+		fmt.Fprintf(w, "\n%s To split, create a branch per PR/MR from %s and check out its files:\n", ui.Tip, base)
+		fmt.Fprintf(w, "   git switch -c <new-branch> %s && git checkout %s -- <files>\n", base, branch)
+	}
+}
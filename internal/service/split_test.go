@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestClusterFiles(t *testing.T) {
+	changes := []types.FileChange{
+		{Path: "internal/service/create.go", Additions: 10, Deletions: 2},
+		{Path: "internal/service/split.go", Additions: 200},
+		{Path: "internal/service/deep/nested.go", Additions: 5},
+		{Path: "cmd/create.go", Additions: 3, Deletions: 1},
+		{Path: "README.md", Additions: 4},
+	}
+
+	clusters := clusterFiles(changes)
+
+	var names []string
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	want := []string{"internal/service", "(root)", "cmd"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("clusterFiles() names = %v, want %v", names, want)
+	}
+	if got := clusters[0]; len(got.Files) != 3 || got.Additions != 215 || got.Deletions != 2 {
+		t.Errorf("clusterFiles() first cluster = %d files, +%d -%d; want 3 files, +215 -2", len(got.Files), got.Additions, got.Deletions)
+	}
+}
+
+func TestDecodeSplits(t *testing.T) {
+	changes := []types.FileChange{{Path: "a.go"}, {Path: "b.go"}, {Path: "c.go"}}
+
+	// The shape the field has after the AI's JSON response is decoded
+	field := []interface{}{
+		map[string]interface{}{"title": "Add a", "reason": "New feature", "files": []interface{}{"a.go", "missing.go"}},
+		map[string]interface{}{"title": "Duplicate only", "files": []interface{}{"a.go"}},
+		map[string]interface{}{"title": "Add b", "files": []interface{}{"b.go"}},
+	}
+
+	prs, unassigned := decodeSplits(field, changes)
+
+	if len(prs) != 2 || prs[0].Title != "Add a" || prs[1].Title != "Add b" {
+		t.Fatalf("decodeSplits() = %+v, want the PRs for a.go and b.go", prs)
+	}
+	if !reflect.DeepEqual(prs[0].Files, []string{"a.go"}) {
+		t.Errorf("decodeSplits() files = %v, want only changed files", prs[0].Files)
+	}
+	if !reflect.DeepEqual(unassigned, []string{"c.go"}) {
+		t.Errorf("decodeSplits() unassigned = %v, want [c.go]", unassigned)
+	}
+
+	if prs, unassigned := decodeSplits(nil, changes); len(prs) != 0 || len(unassigned) != 3 {
+		t.Errorf("decodeSplits(nil) = %v, %v; want no PRs and every file unassigned", prs, unassigned)
+	}
+}
+
+func TestPrintSplitSuggestion(t *testing.T) {
+	suggestion := &SplitSuggestion{
+		Clusters: clusterFiles([]types.FileChange{{Path: "api/a.go"}, {Path: "web/b.ts"}, {Path: "web/c.ts"}}),
+		PullRequests: []SuggestedPR{
+			{Title: "Add API endpoint", Reason: "Backend first", Files: []string{"api/a.go"}},
+			{Title: "Use endpoint in UI", Files: []string{"web/b.ts"}},
+		},
+		Unassigned: []string{"web/c.ts"},
+		Advice:     "Merge the API change first.",
+	}
+
+	var buf bytes.Buffer
+	printSplitSuggestion(&buf, "feature", "main", suggestion)
+	report := buf.String()
+
+	for _, want := range []string{"web: 2 files", "1. Add API endpoint", "Why: Backend first", "2. Use endpoint in UI", "Not in any suggested PR/MR", "web/c.ts", "Merge the API change first.", "git switch -c <new-branch> main"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("printSplitSuggestion() missing %q in:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+
+	"auto-pr/internal/git"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+)
+
+// maxStackDepth bounds the walk down a stack in case the parents loop
+const maxStackDepth = 20
+
+// stackParents returns the branches below branch in its stack, nearest to
+// base first, not including base itself
+func stackParents(gitAnalyzer *git.Analyzer, branch, base string) ([]string, error) {
+	var parents []string
+	seen := map[string]bool{branch: true}
+
+	for current := branch; len(parents) < maxStackDepth; {
+		parent, err := gitAnalyzer.StackParent(current, base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the parent of %s: %w", current, err)
+		}
+		if parent == base || seen[parent] {
+			break
+		}
+		seen[parent] = true
+		parents = append([]string{parent}, parents...)
+		current = parent
+	}
+
+	return parents, nil
+}
+
+// createStackParents creates a PR/MR for every branch below the current one
+// in its stack, each targeting its own parent, then switches back
+func createStackParents(opts CreatePROptions, gitAnalyzer *git.Analyzer, status *types.GitStatus) (err error) {
+	out := output(opts.Out)
+
+	parents, err := stackParents(gitAnalyzer, status.CurrentBranch, status.BaseBranch)
+	if err != nil {
+		return err
+	}
+	if len(parents) == 0 {
+		return nil
+	}
+
+	// Switching branches would carry local changes along or fail outright
+	if status.HasChanges {
+		return fmt.Errorf("commit or stash your changes before creating PRs/MRs for the whole stack")
+	}
+
+	defer func() {
+		if checkoutErr := gitAnalyzer.Checkout(status.CurrentBranch); checkoutErr != nil && err == nil {
+			err = checkoutErr
+		}
+	}()
+
+	parentOpts := opts
+	parentOpts.Chain = false
+	parentOpts.Stacked = true
+	// A title names one PR/MR; a prefix such as a ticket key fits them all
+	parentOpts.Title = ""
+
+	for _, parent := range parents {
+		fmt.Fprintf(out, "\n%s Stack: %s\n", ui.Branch, parent)
+		if err := gitAnalyzer.Checkout(parent); err != nil {
+			return err
+		}
+		if _, err := CreatePR(parentOpts); err != nil {
+			return fmt.Errorf("failed to create PR/MR for %s: %w", parent, err)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%s Stack: %s\n", ui.Branch, status.CurrentBranch)
+	return nil
+}
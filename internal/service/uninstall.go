@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"auto-pr/internal/config"
+	"auto-pr/internal/ui"
+)
+
+// UninstallOptions configures Uninstall
+type UninstallOptions struct {
+	Yes    bool // Remove without asking for confirmation
+	DryRun bool
+	In     io.Reader // Answers for the confirmation; defaults to standard input
+	Out    io.Writer
+}
+
+// UninstallResult lists the directories Uninstall removed
+type UninstallResult struct {
+	Removed []string
+}
+
+// Uninstall removes auto-pr's config, templates and other user data: every
+// directory config.AppDirs finds, after listing them and, without Yes,
+// asking for confirmation. It prints what is left to remove by hand.
+func Uninstall(opts UninstallOptions) (*UninstallResult, error) {
+	out := output(opts.Out)
+	result := &UninstallResult{}
+
+	dirs, err := config.AppDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dirs) == 0 {
+		fmt.Fprintf(out, "%s No auto-pr configuration or data found\n", ui.Empty)
+	} else {
+		fmt.Fprintf(out, "%s This removes:\n", ui.Folder)
+		for _, dir := range dirs {
+			fmt.Fprintf(out, "   %s\n", dir)
+			for _, entry := range dirEntries(dir) {
+				fmt.Fprintf(out, "      %s\n", entry)
+			}
+		}
+
+		switch {
+		case opts.DryRun:
+			fmt.Fprintf(out, "%s Dry run - nothing removed\n", ui.Search)
+			return result, nil
+		case !opts.Yes && !confirmNo(bufio.NewReader(input(opts.In)), out, fmt.Sprintf("%s Remove them?", ui.Warning)):
+			fmt.Fprintf(out, "%s Cancelled\n", ui.Failure)
+			return result, nil
+		}
+
+		for _, dir := range dirs {
+			if err := config.RemoveAppDir(dir); err != nil {
+				return result, fmt.Errorf("failed to remove %s: %w", dir, err)
+			}
+			result.Removed = append(result.Removed, dir)
+		}
+		fmt.Fprintf(out, "%s Removed auto-pr's configuration and data\n", ui.Success)
+	}
+
+	printManualUninstallSteps(out)
+	return result, nil
+}
+
+// dirEntries lists the names in dir, marking subdirectories with a slash
+func dirEntries(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// printManualUninstallSteps says what auto-pr leaves for the user to remove
+func printManualUninstallSteps(out io.Writer) {
+	fmt.Fprintf(out, "\n%s To finish uninstalling:\n", ui.Tip)
+	if binary, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+			binary = resolved
+		}
+		fmt.Fprintf(out, "   - Remove the binary: rm %s\n", binary)
+	} else {
+		fmt.Fprintln(out, "   - Remove the auto-pr binary from your PATH")
+	}
+	fmt.Fprintln(out, "   - Remove any prepare-commit-msg hook that runs auto-pr commit --hook")
+	fmt.Fprintln(out, "   - Repositories keep small .git/auto-pr-cache and .git/auto-pr-state files, which are safe to delete")
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUninstall(t *testing.T) {
+	tests := []struct {
+		name        string
+		yes         bool
+		dryRun      bool
+		answer      string
+		wantRemoved bool
+	}{
+		{name: "confirmed", answer: "y\n", wantRemoved: true},
+		{name: "declined", answer: "\n"},
+		{name: "yes flag", yes: true, wantRemoved: true},
+		{name: "dry run", yes: true, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", "")
+			t.Setenv("XDG_DATA_HOME", "")
+
+			appDir := filepath.Join(home, ".auto-pr")
+			if err := os.MkdirAll(filepath.Join(appDir, "templates"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(appDir, "config.yaml"), []byte("ai:\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			// Files beside the auto-pr directory are never touched
+			keep := filepath.Join(home, ".gitconfig")
+			if err := os.WriteFile(keep, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			result, err := Uninstall(UninstallOptions{Yes: tt.yes, DryRun: tt.dryRun, In: strings.NewReader(tt.answer), Out: &out})
+			if err != nil {
+				t.Fatalf("Uninstall() error = %v", err)
+			}
+
+			for _, want := range []string{appDir, "config.yaml", "templates/"} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Uninstall() output doesn't list %q:\n%s", want, out.String())
+				}
+			}
+
+			_, statErr := os.Stat(appDir)
+			if removed := os.IsNotExist(statErr); removed != tt.wantRemoved {
+				t.Errorf("Uninstall() removed %s = %v, want %v", appDir, removed, tt.wantRemoved)
+			}
+			if tt.wantRemoved && (len(result.Removed) != 1 || result.Removed[0] != appDir) {
+				t.Errorf("Uninstall() Removed = %v, want [%s]", result.Removed, appDir)
+			}
+			if _, err := os.Stat(keep); err != nil {
+				t.Errorf("Uninstall() removed %s: %v", keep, err)
+			}
+		})
+	}
+}
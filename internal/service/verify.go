@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"auto-pr/internal/ui"
+)
+
+// testOutputLines is how much of a failing test run's output is shown
+const testOutputLines = 40
+
+// projectTestCommands are the test commands used for each kind of project
+// when git.test_command isn't set, checked in order
+var projectTestCommands = []struct {
+	marker  string
+	command string
+}{
+	{"go.mod", "go test ./..."},
+	{"Cargo.toml", "cargo test"},
+	{"package.json", "npm test"},
+	{"pyproject.toml", "python -m pytest"},
+	{"Makefile", "make test"},
+}
+
+// testCommand returns the configured test command, or the one for the
+// project type, or an empty string when neither is known
+func testCommand(repoPath, configured string) string {
+	if configured != "" {
+		return configured
+	}
+
+	for _, project := range projectTestCommands {
+		if _, err := os.Stat(filepath.Join(repoPath, project.marker)); err == nil {
+			return project.command
+		}
+	}
+	return ""
+}
+
+// runLocalTests runs the test command in the repository and reports whether
+// it passed, showing the end of its output when it fails
+func runLocalTests(out io.Writer, repoPath, command string) bool {
+	fmt.Fprintf(out, "%s Running tests: %s\n", ui.Gear, command)
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		fmt.Fprintf(out, "%s Tests passed\n", ui.Success)
+		return true
+	}
+
+	fmt.Fprintf(out, "%s Tests failed: %v\n", ui.Failure, err)
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	if len(lines) > testOutputLines {
+		fmt.Fprintf(out, "   ... (%d earlier lines omitted)\n", len(lines)-testOutputLines)
+		lines = lines[len(lines)-testOutputLines:]
+	}
+	for _, line := range lines {
+		fmt.Fprintf(out, "   %s\n", line)
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTestCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		configured string
+		want       string
+	}{
+		{name: "Configured command wins", files: []string{"go.mod"}, configured: "make check", want: "make check"},
+		{name: "Go module", files: []string{"go.mod", "Makefile"}, want: "go test ./..."},
+		{name: "Node project", files: []string{"package.json"}, want: "npm test"},
+		{name: "Unknown project", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, file), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := testCommand(dir, tt.configured); got != tt.want {
+				t.Errorf("testCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunLocalTests(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	var out bytes.Buffer
+	if !runLocalTests(&out, t.TempDir(), "true") {
+		t.Errorf("runLocalTests(true) = false, output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if runLocalTests(&out, t.TempDir(), "echo 'FAIL: TestThing'; exit 1") {
+		t.Error("runLocalTests() = true for a failing command")
+	}
+	if !strings.Contains(out.String(), "FAIL: TestThing") {
+		t.Errorf("runLocalTests() output = %q, want the test output", out.String())
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"auto-pr/internal/ai"
+	"auto-pr/internal/config"
+	"auto-pr/internal/git"
+	"auto-pr/internal/platforms"
+	"auto-pr/internal/ui"
+	"auto-pr/pkg/types"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Defaults for WatchOptions
+const (
+	DefaultWatchDebounce    = 10 * time.Second
+	DefaultWatchMinInterval = 2 * time.Minute
+)
+
+// watchSkipDirs are directories too large or too generated to be worth
+// watching; changes there never affect the PR/MR description
+var watchSkipDirs = map[string]bool{"node_modules": true, "vendor": true, ".venv": true, "target": true}
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	RepoPath    string
+	Debounce    time.Duration // Quiet period after the last change before refreshing
+	MinInterval time.Duration // Minimum time between two refreshes
+	Once        bool          // Refresh once and return instead of watching
+	AutoLogin   bool
+	DryRun      bool // Print the new description instead of updating the PR/MR
+	Verbose     bool
+	Out         io.Writer
+	In          io.Reader // Answers for the login prompt; defaults to standard input
+}
+
+// Watch keeps the description of the current branch's draft PR/MR in step
+// with the branch: whenever the repository has been quiet for the debounce
+// period after a change, and HEAD has moved since the description was last
+// generated, it regenerates the description and updates the PR/MR. It only
+// reads the repository and never commits or pushes, and it stops once the
+// PR/MR is no longer an open draft. It runs until ctx is done.
+func Watch(ctx context.Context, opts WatchOptions) error {
+	out := output(opts.Out)
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultWatchDebounce
+	}
+	if opts.MinInterval <= 0 {
+		opts.MinInterval = DefaultWatchMinInterval
+	}
+
+	refresher, err := newDraftRefresher(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := refresher.refresh(); err != nil || opts.Once {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start watching files: %w", err)
+	}
+	defer watcher.Close()
+
+	repoPath := refresher.gitAnalyzer.RepoPath()
+	ignored := make(map[string]bool)
+	for _, dir := range refresher.gitAnalyzer.IgnoredDirs() {
+		ignored[filepath.Join(repoPath, dir)] = true
+	}
+	if err := addWatchDirs(watcher, repoPath, ignored); err != nil {
+		return err
+	}
+	tracker := &headTracker{gitDir: filepath.Join(repoPath, ".git"), gitAnalyzer: refresher.gitAnalyzer}
+	tracker.head, _ = refresher.gitAnalyzer.GetHeadHash()
+
+	fmt.Fprintf(out, "%s Watching %s for changes (Ctrl+C to stop)\n", ui.Search, repoPath)
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !tracker.changed(event.Name) {
+					continue
+				}
+				// Follow new directories so files created in them are seen too
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() &&
+						!skipWatchDir(repoPath, event.Name) && !refresher.gitAnalyzer.IsIgnored(event.Name) {
+						_ = addWatchDirs(watcher, event.Name, ignored)
+					}
+				}
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if opts.Verbose {
+					fmt.Fprintf(out, "%s Watch error: %v\n", ui.Warning, err)
+				}
+			}
+		}
+	}()
+
+	return watchLoop(ctx, changes, opts.Debounce, opts.MinInterval, refresher.refresh)
+}
+
+// watchLoop calls refresh once changes have been quiet for debounce, at most
+// once per minInterval, until ctx is done or refresh fails
+func watchLoop(ctx context.Context, changes <-chan struct{}, debounce, minInterval time.Duration, refresh func() error) error {
+	var (
+		timer       *time.Timer
+		fire        <-chan time.Time
+		lastRefresh = time.Now()
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		case <-changes:
+			// Each change restarts the quiet period
+			wait := debounce
+			if untilAllowed := minInterval - time.Since(lastRefresh); untilAllowed > wait {
+				wait = untilAllowed
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(wait)
+			fire = timer.C
+		case <-fire:
+			fire = nil
+			lastRefresh = time.Now()
+			if err := refresh(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// addWatchDirs watches root and the directories below it, including the .git
+// directory itself (where commits and checkouts show up) but not its contents,
+// and leaving out the ignored ones
+func addWatchDirs(watcher *fsnotify.Watcher, root string, ignored map[string]bool) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if path != root && (skipWatchDir(root, path) || ignored[path]) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		if entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// skipWatchDir reports whether a directory is left unwatched
+func skipWatchDir(root, path string) bool {
+	name := filepath.Base(path)
+	if watchSkipDirs[name] {
+		return true
+	}
+	// Only the repository's own .git directory is watched, not nested ones
+	return strings.Contains(filepath.ToSlash(strings.TrimPrefix(path, root)), "/.git/")
+}
+
+// headTracker tells the events in the .git directory that come from HEAD
+// moving, with a commit or checkout, from the index and lock file writes of
+// every git status, which would otherwise have each refresh set off the next
+type headTracker struct {
+	gitDir      string
+	gitAnalyzer *git.Analyzer
+	head        string // HEAD when it was last seen to move
+}
+
+// changed reports whether the event at path may call for a refresh: any
+// change in the work tree, or one in the .git directory that moved HEAD
+func (h *headTracker) changed(path string) bool {
+	if filepath.Dir(path) != h.gitDir {
+		return true
+	}
+	if strings.HasSuffix(path, ".lock") {
+		return false
+	}
+	head, err := h.gitAnalyzer.GetHeadHash()
+	if err != nil || head == h.head {
+		return false
+	}
+	h.head = head
+	return true
+}
+
+// draftRefresher regenerates the description of the branch's draft PR/MR
+type draftRefresher struct {
+	opts           WatchOptions
+	out            io.Writer
+	gitAnalyzer    *git.Analyzer
+	platform       types.PlatformType
+	platformClient platforms.PlatformClient
+	aiClient       ai.AIClient
+	cfg            *types.Config
+	branch         string
+	described      string // HEAD when the description was last generated
+}
+
+// newDraftRefresher sets up the clients Watch needs, failing early when the
+// branch has no open draft PR/MR to keep up to date
+func newDraftRefresher(opts WatchOptions) (*draftRefresher, error) {
+	out := output(opts.Out)
+
+	gitAnalyzer, err := openRepository(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	platform, err := platforms.DetectPlatform(gitAnalyzer.GetRemoteURL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	status, err := gitAnalyzer.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if status.DetachedHead {
+		return nil, git.ErrDetachedHead
+	}
+
+	cfg, err := config.LoadConfigWithViper()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	aiClient, err := ai.NewClient(cfg.AI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	platformClient, err := newPlatformClient(platform, status.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureAuthenticated(bufio.NewReader(input(opts.In)), out, platformClient, opts.AutoLogin); err != nil {
+		return nil, err
+	}
+
+	refresher := &draftRefresher{
+		opts:           opts,
+		out:            out,
+		gitAnalyzer:    gitAnalyzer,
+		platform:       platform,
+		platformClient: platformClient,
+		aiClient:       aiClient,
+		cfg:            cfg,
+		branch:         status.CurrentBranch,
+	}
+	if _, err := refresher.draft(); err != nil {
+		return nil, err
+	}
+	return refresher, nil
+}
+
+// draft returns the branch's PR/MR, or an error unless it is an open draft;
+// it is looked up every time since it may have been marked ready meanwhile
+func (r *draftRefresher) draft() (*types.PullRequest, error) {
+	pr, err := r.platformClient.GetExistingPR(r.branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing PR/MR: %w", err)
+	}
+	if pr == nil {
+		return nil, fmt.Errorf("no open PR/MR for branch '%s'. Create a draft first with: auto-pr create --draft", r.branch)
+	}
+	if !pr.Draft || pr.State != types.PRStateOpen {
+		return nil, fmt.Errorf("%s is not an open draft, so watch won't change it: %s", getEntityName(r.platform), pr.URL)
+	}
+	return pr, nil
+}
+
+// refresh regenerates and updates the draft's description when HEAD has
+// moved since it was last generated
+func (r *draftRefresher) refresh() error {
+	// Stop on a branch switch rather than describe another branch in this PR/MR
+	status, err := r.gitAnalyzer.GetStatus()
+	if err != nil {
+		return fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if status.CurrentBranch != r.branch {
+		return fmt.Errorf("switched from %s to %s, stopping", r.branch, status.CurrentBranch)
+	}
+
+	pr, err := r.draft()
+	if err != nil {
+		return err
+	}
+
+	head, err := r.gitAnalyzer.GetHeadHash()
+	if err != nil {
+		return err
+	}
+	if head == r.described || head == lastDescribedCommit(pr.Body) {
+		if r.opts.Verbose {
+			fmt.Fprintf(r.out, "%s Description already covers %s\n", ui.Success, git.ShortHash(head))
+		}
+		return nil
+	}
+
+	setBaseBranch(r.gitAnalyzer, status, pr.BaseBranch)
+	aiContext, err := buildPRContext(r.gitAnalyzer, status, r.platform, r.cfg.Git, r.cfg.Git.CommitLimit, nil)
+	if err != nil {
+		return err
+	}
+	aiContext.ExtraFields = r.cfg.AI.ExtraFields
+
+	fmt.Fprintf(r.out, "%s Regenerating the description for %s...\n", ui.Robot, git.ShortHash(head))
+	response, err := r.aiClient.GenerateContent(aiContext,
+		"Generate a comprehensive pull request description based on the provided git changes and commit history. The PR is a draft still in progress.")
+	if err != nil {
+		return fmt.Errorf("failed to generate AI content: %w", err)
+	}
+
+	// The marker records which commit the description covers
+	body := strings.TrimRight(response.Body, "\n") + describedCommitMarker(head)
+
+	if r.opts.DryRun {
+		fmt.Fprintf(r.out, "%s Dry run - would update %s with:\n%s\n", ui.Search, pr.URL, body)
+		r.described = head
+		return nil
+	}
+
+	if _, err := r.platformClient.UpdatePullRequest(pr.Number, &types.PullRequestUpdate{Body: body}); err != nil {
+		return fmt.Errorf("failed to update PR/MR: %w", err)
+	}
+	r.described = head
+	fmt.Fprintf(r.out, "%s Updated %s description: %s\n", ui.Success, getEntityName(r.platform), pr.URL)
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"auto-pr/internal/git"
+)
+
+func TestWatchLoopDebounces(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{})
+	var refreshes atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		done <- watchLoop(ctx, changes, 50*time.Millisecond, 0, func() error {
+			refreshes.Add(1)
+			return nil
+		})
+	}()
+
+	// A burst of changes within the debounce period is one refresh
+	for i := 0; i < 5; i++ {
+		changes <- struct{}{}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("refreshes after a burst = %d, want 1", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watchLoop() error = %v, want nil when cancelled", err)
+	}
+}
+
+func TestWatchLoopMinInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{})
+	var refreshes atomic.Int32
+	go func() {
+		_ = watchLoop(ctx, changes, 10*time.Millisecond, time.Hour, func() error {
+			refreshes.Add(1)
+			return nil
+		})
+	}()
+
+	// The loop starts counting the interval from its start
+	changes <- struct{}{}
+	time.Sleep(100 * time.Millisecond)
+	if got := refreshes.Load(); got != 0 {
+		t.Errorf("refreshes within the minimum interval = %d, want 0", got)
+	}
+}
+
+func TestWatchLoopStopsOnRefreshError(t *testing.T) {
+	changes := make(chan struct{}, 1)
+	changes <- struct{}{}
+	stop := errors.New("no longer a draft")
+
+	err := watchLoop(context.Background(), changes, time.Millisecond, 0, func() error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("watchLoop() error = %v, want %v", err, stop)
+	}
+}
+
+func TestSkipWatchDir(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Source directory", path: "/repo/internal/service", want: false},
+		{name: "Repository .git", path: "/repo/.git", want: false},
+		{name: "Inside .git", path: "/repo/.git/objects", want: true},
+		{name: "Dependencies", path: "/repo/web/node_modules", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skipWatchDir(root, filepath.FromSlash(tt.path)); got != tt.want {
+				t.Errorf("skipWatchDir(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadTrackerIgnoresIndexWrites(t *testing.T) {
+	dir := newRepoWithRemoteBranches(t, []string{"main"})
+	gitAnalyzer, err := git.NewAnalyzer(dir)
+	if err != nil {
+		t.Fatalf("NewAnalyzer() error = %v", err)
+	}
+	tracker := &headTracker{gitDir: filepath.Join(dir, ".git"), gitAnalyzer: gitAnalyzer}
+	tracker.head, _ = gitAnalyzer.GetHeadHash()
+
+	// What the git status of a refresh writes doesn't count as a change
+	if _, err := gitAnalyzer.GetStatus(); err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	for _, name := range []string{"index.lock", "index"} {
+		if tracker.changed(filepath.Join(dir, ".git", name)) {
+			t.Errorf("changed(.git/%s) = true without HEAD moving", name)
+		}
+	}
+	if !tracker.changed(filepath.Join(dir, "main.go")) {
+		t.Error("changed(main.go) = false for a work tree change")
+	}
+
+	// A commit does, once
+	if output, err := exec.Command("git", "-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "next").CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, output)
+	}
+	if !tracker.changed(filepath.Join(dir, ".git", "index")) {
+		t.Error("changed(.git/index) = false after a commit")
+	}
+	if tracker.changed(filepath.Join(dir, ".git", "COMMIT_EDITMSG")) {
+		t.Error("changed() = true twice for the same commit")
+	}
+}
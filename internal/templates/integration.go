@@ -5,13 +5,14 @@ import (
 	"strings"
 
 	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
 )
 
 // BuildTemplateContext creates a template context from AI context and response
 func BuildTemplateContext(aiCtx *ai.AIContext, aiResp *ai.AIResponse) *TemplateContext {
 	ctx := &TemplateContext{
 		Title:       aiResp.Title,
-		Type:        detectChangeType(aiCtx),
+		Type:        DetectChangeType(aiCtx),
 		Summary:     extractSummary(aiResp.Body),
 		Description: aiResp.Body,
 		Custom:      make(map[string]interface{}),
@@ -49,10 +50,41 @@ func BuildTemplateContext(aiCtx *ai.AIContext, aiResp *ai.AIResponse) *TemplateC
 	ctx.Custom["reviewers"] = aiResp.Reviewers
 	ctx.Custom["priority"] = aiResp.Priority
 	ctx.Custom["confidence"] = aiResp.Confidence
+	ctx.Custom["trailers"] = aggregateTrailers(aiCtx.CommitHistory)
+
+	// Extra response fields, e.g. {{.Custom.risk}}, never replace the above
+	for key, value := range aiResp.Extra {
+		if _, exists := ctx.Custom[key]; !exists {
+			ctx.Custom[key] = value
+		}
+	}
 
 	return ctx
 }
 
+// aggregateTrailers merges the trailers of all commits, so templates can use
+// e.g. {{index .Custom.trailers "Refs"}}. Values repeated across commits are
+// listed once, in commit order.
+func aggregateTrailers(commits []types.CommitInfo) map[string][]string {
+	trailers := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	for _, commit := range commits {
+		for key, values := range commit.Trailers {
+			for _, value := range values {
+				id := key + "\x00" + value
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				trailers[key] = append(trailers[key], value)
+			}
+		}
+	}
+
+	return trailers
+}
+
 // EnhanceWithTemplate enhances AI response using a template
 func EnhanceWithTemplate(manager *Manager, templateName string, aiCtx *ai.AIContext, aiResp *ai.AIResponse) (*ai.AIResponse, error) {
 	// Build template context
@@ -74,13 +106,15 @@ func EnhanceWithTemplate(manager *Manager, templateName string, aiCtx *ai.AICont
 		Confidence: aiResp.Confidence,
 		Provider:   aiResp.Provider,
 		TokensUsed: aiResp.TokensUsed,
+		Extra:      aiResp.Extra,
 	}
 
 	return enhanced, nil
 }
 
-// detectChangeType attempts to detect the type of change
-func detectChangeType(ctx *ai.AIContext) string {
+// DetectChangeType guesses the kind of change (feature, bugfix, docs, ...)
+// from the commit messages and changed files
+func DetectChangeType(ctx *ai.AIContext) string {
 	// Check commit messages
 	for _, commit := range ctx.CommitHistory {
 		msg := strings.ToLower(commit.Message)
@@ -107,6 +141,11 @@ func detectChangeType(ctx *ai.AIContext) string {
 		}
 	}
 
+	// Uncommitted work has no messages to go on, so read the changes closely
+	if len(ctx.CommitHistory) == 0 {
+		return changeTypeFromFiles(ctx.FileChanges)
+	}
+
 	// Check file changes
 	hasTests := false
 	hasDocs := false
@@ -138,6 +177,91 @@ func detectChangeType(ctx *ai.AIContext) string {
 	return "feature" // default
 }
 
+// smallFixLines is the most lines a change to existing code can touch and
+// still be taken for a bug fix rather than a feature
+const smallFixLines = 20
+
+// depFiles are the dependency manifests and lock files of common ecosystems
+var depFiles = map[string]bool{
+	"go.mod": true, "go.sum": true,
+	"package.json": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"requirements.txt": true, "pipfile": true, "pipfile.lock": true, "poetry.lock": true,
+	"cargo.toml": true, "cargo.lock": true, "gemfile": true, "gemfile.lock": true,
+}
+
+// changeTypeFromFiles infers the type of change from the changed files alone:
+// changes only to tests, docs or dependencies take that type; otherwise new
+// source files make a feature, changes that only remove, rename or shrink
+// code a refactor, and small edits to existing code a bug fix
+func changeTypeFromFiles(changes []types.FileChange) string {
+	var code []types.FileChange
+	kinds := make(map[string]bool)
+	for _, fc := range changes {
+		kind := classifyChangedFile(fc.Path)
+		kinds[kind] = true
+		if kind == "code" {
+			code = append(code, fc)
+		}
+	}
+
+	if len(code) == 0 {
+		// Mixed changes take the kind that matters most to reviewers
+		switch {
+		case kinds["deps"]:
+			return "deps"
+		case kinds["test"]:
+			return "test"
+		case kinds["docs"]:
+			return "docs"
+		}
+		return "feature" // default
+	}
+
+	additions, deletions := 0, 0
+	restructured := true
+	for _, fc := range code {
+		if fc.Status == types.StatusAdded {
+			return "feature"
+		}
+		if fc.Status != types.StatusDeleted && fc.Status != types.StatusRenamed {
+			restructured = false
+		}
+		additions += fc.Additions
+		deletions += fc.Deletions
+	}
+
+	if restructured || deletions > additions {
+		return "refactor"
+	}
+	if additions+deletions <= smallFixLines {
+		return "bugfix"
+	}
+	return "feature"
+}
+
+// classifyChangedFile sorts a changed file into "test", "docs", "deps" or
+// "code"
+func classifyChangedFile(filePath string) string {
+	lower := strings.ToLower(filePath)
+	name := lower[strings.LastIndex(lower, "/")+1:]
+
+	switch {
+	case depFiles[name]:
+		return "deps"
+	case strings.HasSuffix(name, "_test.go"), strings.HasPrefix(name, "test_"), strings.HasSuffix(name, "_test.py"),
+		strings.Contains(name, ".test."), strings.Contains(name, ".spec."),
+		strings.HasPrefix(lower, "test/"), strings.HasPrefix(lower, "tests/"),
+		strings.Contains(lower, "/test/"), strings.Contains(lower, "/tests/"),
+		strings.Contains(lower, "testdata/"), strings.Contains(lower, "__tests__/"):
+		return "test"
+	case strings.HasSuffix(name, ".md"), strings.HasSuffix(name, ".rst"), strings.HasSuffix(name, ".adoc"),
+		strings.HasPrefix(name, "readme"), strings.HasPrefix(name, "changelog"),
+		strings.HasPrefix(lower, "docs/"), strings.Contains(lower, "/docs/"):
+		return "docs"
+	}
+	return "code"
+}
+
 // extractSummary extracts a summary from the body
 func extractSummary(body string) string {
 	// Look for summary section
@@ -255,9 +379,31 @@ func enhanceLabels(templateName string, existing []string) []string {
 	return labels
 }
 
+// commitTypeTemplates maps conventional commit types to the templates for
+// them; types without their own template fall back to detection
+var commitTypeTemplates = map[string]string{
+	"feat":     "feature",
+	"fix":      "bugfix",
+	"docs":     "docs",
+	"test":     "test",
+	"refactor": "refactor",
+	"build":    "deps",
+}
+
+// ResolveChangeType returns the kind of change ctx describes: the one the
+// conventional commit type the user chose stands for, otherwise the one
+// DetectChangeType finds
+func ResolveChangeType(ctx *ai.AIContext) string {
+	// A type the user chose beats guessing from the changes
+	if changeType, ok := commitTypeTemplates[ctx.ChangeType]; ok {
+		return changeType
+	}
+	return DetectChangeType(ctx)
+}
+
 // SelectTemplateByContext automatically selects a template based on context
 func SelectTemplateByContext(ctx *ai.AIContext) string {
-	changeType := detectChangeType(ctx)
+	changeType := ResolveChangeType(ctx)
 
 	// Map change types to template names
 	templateMap := map[string]string{
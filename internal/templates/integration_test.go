@@ -0,0 +1,148 @@
+package templates
+
+import (
+	"reflect"
+	"testing"
+
+	"auto-pr/internal/ai"
+	"auto-pr/pkg/types"
+)
+
+func TestBuildTemplateContextExtraFields(t *testing.T) {
+	resp := &ai.AIResponse{
+		Title:  "Fix crash",
+		Labels: []string{"bug"},
+		Extra: map[string]interface{}{
+			"risk":   "low",
+			"labels": "not a label list",
+		},
+	}
+
+	ctx := BuildTemplateContext(&ai.AIContext{}, resp)
+	if got := ctx.Custom["risk"]; got != "low" {
+		t.Errorf("Custom[risk] = %v, want low", got)
+	}
+	if got := ctx.Custom["labels"]; !reflect.DeepEqual(got, []string{"bug"}) {
+		t.Errorf("Custom[labels] = %v, want the response labels", got)
+	}
+}
+
+func TestDetectChangeTypeWithoutCommits(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []types.FileChange
+		want    string
+	}{
+		{
+			name: "Only tests",
+			changes: []types.FileChange{
+				{Path: "internal/git/diff_test.go", Status: types.StatusModified, Additions: 40},
+				{Path: "internal/git/testdata/sample.diff", Status: types.StatusAdded, Additions: 12},
+			},
+			want: "test",
+		},
+		{
+			name: "Only docs",
+			changes: []types.FileChange{
+				{Path: "README.md", Status: types.StatusModified, Additions: 5},
+				{Path: "docs/setup.rst", Status: types.StatusAdded, Additions: 30},
+			},
+			want: "docs",
+		},
+		{
+			name: "Tests and docs",
+			changes: []types.FileChange{
+				{Path: "web/src/app.spec.ts", Status: types.StatusModified, Additions: 10},
+				{Path: "CHANGELOG.md", Status: types.StatusModified, Additions: 2},
+			},
+			want: "test",
+		},
+		{
+			name: "Dependency bump",
+			changes: []types.FileChange{
+				{Path: "go.mod", Status: types.StatusModified, Additions: 1, Deletions: 1},
+				{Path: "go.sum", Status: types.StatusModified, Additions: 2, Deletions: 2},
+			},
+			want: "deps",
+		},
+		{
+			name: "New source file",
+			changes: []types.FileChange{
+				{Path: "internal/service/release.go", Status: types.StatusAdded, Additions: 120},
+				{Path: "internal/service/release_test.go", Status: types.StatusAdded, Additions: 50},
+			},
+			want: "feature",
+		},
+		{
+			name: "Small edit to existing code",
+			changes: []types.FileChange{
+				{Path: "internal/git/diff.go", Status: types.StatusModified, Additions: 3, Deletions: 1},
+				{Path: "internal/git/diff_test.go", Status: types.StatusModified, Additions: 15},
+			},
+			want: "bugfix",
+		},
+		{
+			name: "Large edit to existing code",
+			changes: []types.FileChange{
+				{Path: "cmd/create.go", Status: types.StatusModified, Additions: 80, Deletions: 10},
+			},
+			want: "feature",
+		},
+		{
+			name: "Code mostly removed",
+			changes: []types.FileChange{
+				{Path: "internal/ai/client.go", Status: types.StatusModified, Additions: 20, Deletions: 90},
+			},
+			want: "refactor",
+		},
+		{
+			name: "Code only moved",
+			changes: []types.FileChange{
+				{Path: "internal/util/strings.go", Status: types.StatusRenamed, Additions: 2, Deletions: 2},
+				{Path: "internal/old/helpers.go", Status: types.StatusDeleted, Deletions: 15},
+			},
+			want: "refactor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectChangeType(&ai.AIContext{FileChanges: tt.changes}); got != tt.want {
+				t.Errorf("DetectChangeType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectChangeTypePrefersCommits(t *testing.T) {
+	ctx := &ai.AIContext{
+		CommitHistory: []types.CommitInfo{{Message: "fix: handle empty input"}},
+		FileChanges:   []types.FileChange{{Path: "internal/service/release.go", Status: types.StatusAdded, Additions: 120}},
+	}
+	if got := DetectChangeType(ctx); got != "bugfix" {
+		t.Errorf("DetectChangeType() = %v, want the type from the commit message", got)
+	}
+}
+
+func TestSelectTemplateByContextChangeType(t *testing.T) {
+	docsOnly := []types.FileChange{{Path: "README.md", Status: types.StatusModified, Additions: 3}}
+
+	tests := []struct {
+		name       string
+		changeType string
+		want       string
+	}{
+		{name: "Detected", want: "docs"},
+		{name: "Chosen type wins", changeType: "fix", want: "bugfix"},
+		{name: "Type without a template", changeType: "chore", want: "docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &ai.AIContext{FileChanges: docsOnly, ChangeType: tt.changeType}
+			if got := SelectTemplateByContext(ctx); got != tt.want {
+				t.Errorf("SelectTemplateByContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
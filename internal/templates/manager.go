@@ -9,6 +9,7 @@ import (
 	"text/template"
 	"time"
 
+	"auto-pr/internal/config"
 	"auto-pr/pkg/types"
 )
 
@@ -29,17 +30,23 @@ type Template struct {
 	IsBuiltIn   bool
 }
 
-// NewManager creates a new template manager
-func NewManager() *Manager {
-	homeDir, _ := os.UserHomeDir()
-	customDir := filepath.Join(homeDir, ".auto-pr", "templates")
+// NewManager creates a new template manager, creating the custom templates
+// directory if needed
+func NewManager() (*Manager, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	customDir := filepath.Join(dataDir, "templates")
 
 	// Ensure custom templates directory exists
-	_ = os.MkdirAll(customDir, 0755)
+	if err := os.MkdirAll(customDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create templates directory %s: %w", customDir, err)
+	}
 
 	return &Manager{
 		customDir: customDir,
-	}
+	}, nil
 }
 
 // ListBuiltInTemplates returns all built-in templates
@@ -0,0 +1,65 @@
+package templates
+
+import (
+	"path"
+	"strings"
+
+	"auto-pr/pkg/types"
+)
+
+// MatchPathRule returns the first rule whose pattern matches one of the
+// changed files, or nil when none does. Rules are tried in the order they
+// are configured, so more specific ones belong first.
+func MatchPathRule(rules []types.PathRule, changes []types.FileChange) *types.PathRule {
+	for i := range rules {
+		for _, change := range changes {
+			if MatchPath(rules[i].Match, change.Path) {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// MatchPath matches a file path against a path rule pattern. A pattern
+// without a slash matches the base name at any depth ("*.sql"), any other
+// is matched against the whole path with "**" spanning directories
+// ("services/payments/**"), and a trailing slash matches everything below
+// that directory.
+func MatchPath(pattern, file string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "/")
+	if pattern == "" {
+		return false
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(file))
+		return matched
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a "**"
+// segment matches any number of directories
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], name[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"testing"
+
+	"auto-pr/pkg/types"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		file    string
+		want    bool
+	}{
+		{name: "Directory with **", pattern: "services/payments/**", file: "services/payments/api/handler.go", want: true},
+		{name: "Other directory", pattern: "services/payments/**", file: "services/billing/handler.go", want: false},
+		{name: "Directory with trailing slash", pattern: "services/payments/", file: "services/payments/main.go", want: true},
+		{name: "** in the middle", pattern: "services/**/migrations/*.sql", file: "services/payments/db/migrations/001.sql", want: true},
+		{name: "Base name at any depth", pattern: "*.sql", file: "services/payments/schema.sql", want: true},
+		{name: "Leading slash", pattern: "/docs/*.md", file: "docs/guide.md", want: true},
+		{name: "Single star stays in one directory", pattern: "services/*", file: "services/payments/main.go", want: false},
+		{name: "Empty pattern", pattern: "", file: "main.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchPath(tt.pattern, tt.file); got != tt.want {
+				t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPathRule(t *testing.T) {
+	rules := []types.PathRule{
+		{Match: "services/payments/**", Template: "payments", Labels: []string{"team-payments"}},
+		{Match: "services/**", Labels: []string{"services"}},
+	}
+
+	tests := []struct {
+		name    string
+		changes []types.FileChange
+		want    string
+	}{
+		{
+			name:    "First matching rule wins",
+			changes: []types.FileChange{{Path: "README.md"}, {Path: "services/payments/charge.go"}},
+			want:    "services/payments/**",
+		},
+		{
+			name:    "Broader rule",
+			changes: []types.FileChange{{Path: "services/billing/invoice.go"}},
+			want:    "services/**",
+		},
+		{
+			name:    "No rule matches",
+			changes: []types.FileChange{{Path: "README.md"}},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchPathRule(rules, tt.changes)
+			if (got == nil && tt.want != "") || (got != nil && got.Match != tt.want) {
+				t.Errorf("MatchPathRule() = %+v, want rule %q", got, tt.want)
+			}
+		})
+	}
+}
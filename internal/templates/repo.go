@@ -0,0 +1,220 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultRepoTemplatePaths are the locations GitHub and GitLab look for a
+// repository's own PR/MR description template, in order
+var DefaultRepoTemplatePaths = []string{
+	".github/PULL_REQUEST_TEMPLATE.md",
+	".github/pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	".gitlab/merge_request_templates/Default.md",
+	".gitlab/merge_request_templates/default.md",
+}
+
+// FindRepoTemplate returns the path, relative to the repository, and content
+// of the repository's PR/MR template, or empty strings when it has none
+func FindRepoTemplate(repoPath string) (string, string) {
+	for _, path := range DefaultRepoTemplatePaths {
+		content, err := os.ReadFile(filepath.Join(repoPath, path))
+		if err == nil && strings.TrimSpace(string(content)) != "" {
+			return path, string(content)
+		}
+	}
+	return "", ""
+}
+
+// markdownSection is a heading and the lines under it, up to the next heading
+type markdownSection struct {
+	heading string // Full heading line, empty for text before the first heading
+	lines   []string
+}
+
+var (
+	headingPattern   = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	checklistPattern = regexp.MustCompile(`^\s*[-*]\s+\[[ xX]\]\s+`)
+	commentPattern   = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// descriptionHeadings name template sections that take the generated
+// description when the body has no section of the same name
+var descriptionHeadings = []string{"description", "summary", "what", "overview", "changes"}
+
+// FillRepoTemplate fills a repository PR/MR template with a generated body.
+// Each template section takes the body section with a matching heading, or
+// the body's opening text for a description-like section, keeping the
+// template's checklist items; sections the body has nothing for are kept as
+// written. Body sections the template lacks are appended at the end.
+func FillRepoTemplate(template, body string) string {
+	templateSections := splitMarkdownSections(template)
+	bodySections := splitMarkdownSections(body)
+
+	// A template without headings is just a checklist or boilerplate
+	if len(templateSections) == 1 && templateSections[0].heading == "" {
+		checklist := checklistLines(templateSections[0].lines)
+		if len(checklist) == 0 {
+			return strings.TrimSpace(body) + "\n\n" + strings.TrimSpace(stripComments(template)) + "\n"
+		}
+		return strings.TrimSpace(body) + "\n\n" + strings.Join(checklist, "\n") + "\n"
+	}
+
+	used := make(map[int]bool)
+	intro := -1
+	if len(bodySections) > 0 && bodySections[0].heading == "" && strings.TrimSpace(strings.Join(bodySections[0].lines, "\n")) != "" {
+		intro = 0
+	}
+
+	var out []string
+	for _, section := range templateSections {
+		if section.heading == "" {
+			// Text above the first heading is guidance for the author
+			if text := strings.TrimSpace(stripComments(strings.Join(section.lines, "\n"))); text != "" {
+				out = append(out, text, "")
+			}
+			continue
+		}
+
+		match := -1
+		for i, candidate := range bodySections {
+			if !used[i] && candidate.heading != "" && headingsMatch(section.heading, candidate.heading) {
+				match = i
+				break
+			}
+		}
+		if match < 0 && intro >= 0 && !used[intro] && isDescriptionHeading(section.heading) {
+			match = intro
+		}
+
+		out = append(out, section.heading)
+		if match < 0 {
+			out = append(out, trimBlankLines(section.lines)...)
+			out = append(out, "")
+			continue
+		}
+
+		used[match] = true
+		out = append(out, "")
+		out = append(out, trimBlankLines(bodySections[match].lines)...)
+		if checklist := checklistLines(section.lines); len(checklist) > 0 {
+			out = append(out, "")
+			out = append(out, checklist...)
+		}
+		out = append(out, "")
+	}
+
+	for i, section := range bodySections {
+		if used[i] || (section.heading == "" && i == intro) {
+			continue
+		}
+		if section.heading != "" {
+			out = append(out, section.heading, "")
+		}
+		out = append(out, trimBlankLines(section.lines)...)
+		out = append(out, "")
+	}
+
+	return strings.TrimSpace(strings.Join(out, "\n")) + "\n"
+}
+
+// splitMarkdownSections splits markdown at its headings, ignoring lines that
+// look like headings inside fenced code blocks
+func splitMarkdownSections(markdown string) []markdownSection {
+	sections := []markdownSection{{}}
+	inFence := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence && headingPattern.MatchString(line) {
+			sections = append(sections, markdownSection{heading: strings.TrimSpace(line)})
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.lines = append(last.lines, line)
+	}
+
+	// Drop an empty preamble so a body starting with a heading has none
+	if strings.TrimSpace(strings.Join(sections[0].lines, "\n")) == "" && len(sections) > 1 {
+		sections = sections[1:]
+	}
+	return sections
+}
+
+// headingText returns a heading's text, lowercased and without markup
+func headingText(heading string) string {
+	if match := headingPattern.FindStringSubmatch(heading); match != nil {
+		heading = match[1]
+	}
+	heading = strings.ToLower(heading)
+	return strings.TrimSpace(strings.Trim(heading, "*_:` "))
+}
+
+// headingsMatch reports whether two headings name the same section, such as
+// "Testing" and "Test plan"
+func headingsMatch(a, b string) bool {
+	a, b = headingText(a), headingText(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b || strings.Contains(a, b) || strings.Contains(b, a) {
+		return true
+	}
+
+	// Compare the first word's stem, e.g. "testing" and "tests"
+	stem := func(s string) string {
+		word := strings.Fields(s)[0]
+		if len(word) > 4 {
+			word = word[:4]
+		}
+		return word
+	}
+	return stem(a) == stem(b)
+}
+
+// isDescriptionHeading reports whether a template section is where the main
+// description goes
+func isDescriptionHeading(heading string) bool {
+	text := headingText(heading)
+	for _, name := range descriptionHeadings {
+		if strings.Contains(text, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checklistLines returns the task list items among lines
+func checklistLines(lines []string) []string {
+	var checklist []string
+	for _, line := range lines {
+		if checklistPattern.MatchString(line) {
+			checklist = append(checklist, strings.TrimRight(line, " "))
+		}
+	}
+	return checklist
+}
+
+// stripComments removes HTML comments, which templates use for instructions
+func stripComments(text string) string {
+	return commentPattern.ReplaceAllString(text, "")
+}
+
+// trimBlankLines drops leading and trailing blank lines
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindRepoTemplate(t *testing.T) {
+	repo := t.TempDir()
+	if path, content := FindRepoTemplate(repo); path != "" || content != "" {
+		t.Errorf("FindRepoTemplate() = %q, %q, want none", path, content)
+	}
+
+	for _, path := range []string{"docs/pull_request_template.md", ".github/PULL_REQUEST_TEMPLATE.md"} {
+		if err := os.MkdirAll(filepath.Join(repo, filepath.Dir(path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, path), []byte("## "+path+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, content := FindRepoTemplate(repo)
+	if path != ".github/PULL_REQUEST_TEMPLATE.md" || content != "## .github/PULL_REQUEST_TEMPLATE.md\n" {
+		t.Errorf("FindRepoTemplate() = %q, %q, want the .github template", path, content)
+	}
+}
+
+func TestFillRepoTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		body     string
+		want     string
+	}{
+		{
+			name: "Fills matching sections and keeps checklists",
+			template: `<!-- Thanks for contributing! -->
+## Description
+<!-- What does this change? -->
+
+## Testing
+- [ ] Unit tests pass
+- [ ] Manually verified
+
+## Related issues
+Closes #
+`,
+			body: `Adds retry support to the uploader.
+
+## Test plan
+- Added unit tests for backoff
+`,
+			want: `## Description
+
+Adds retry support to the uploader.
+
+## Testing
+
+- Added unit tests for backoff
+
+- [ ] Unit tests pass
+- [ ] Manually verified
+
+## Related issues
+Closes #
+`,
+		},
+		{
+			name:     "Appends sections the template lacks",
+			template: "## Summary\n\n## Checklist\n- [ ] Docs updated\n",
+			body:     "## Summary\nFixes the parser.\n\n## Notes\nNo behavior change.\n",
+			want:     "## Summary\n\nFixes the parser.\n\n## Checklist\n- [ ] Docs updated\n\n## Notes\n\nNo behavior change.\n",
+		},
+		{
+			name:     "Template without headings is appended as a checklist",
+			template: "<!-- Please check -->\n- [ ] Tests added\n- [x] Changelog updated\n",
+			body:     "Fixes the parser.\n",
+			want:     "Fixes the parser.\n\n- [ ] Tests added\n- [x] Changelog updated\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FillRepoTemplate(tt.template, tt.body); got != tt.want {
+				t.Errorf("FillRepoTemplate() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
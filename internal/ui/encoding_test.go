@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mojibakeLeads are the characters UTF-8 lead bytes of common symbols turn
+// into when the text is decoded as Windows-1252 and encoded again, e.g. "✅"
+// becoming "âœ…"
+var mojibakeLeads = []rune{'Â', 'â', 'ð'}
+
+// isContinuationChar reports whether r is what a UTF-8 continuation byte
+// (0x80-0xBF) decodes to in Windows-1252 or Latin-1
+func isContinuationChar(r rune) bool {
+	if r >= 0x80 && r <= 0xBF {
+		return true
+	}
+	return strings.ContainsRune("€‚ƒ„…†‡ˆ‰Š‹ŒŽ‘’“”•–—˜™š›œžŸ", r)
+}
+
+// findMojibake returns the first double-encoded sequence in line, if any
+func findMojibake(line string) (string, bool) {
+	runes := []rune(line)
+	for i := 0; i+1 < len(runes); i++ {
+		for _, lead := range mojibakeLeads {
+			if runes[i] == lead && isContinuationChar(runes[i+1]) {
+				return string(runes[i : i+2]), true
+			}
+		}
+	}
+	return "", false
+}
+
+func TestFindMojibake(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{line: `fmt.Println("✅ Created")`, want: false},
+		{line: `fmt.Println("âœ… Created")`, want: true},
+		{line: `fmt.Println("Configuration is valid âœ“")`, want: true},
+		{line: `fmt.Println("ðŸš€ Shipping")`, want: true},
+		{line: `fmt.Println("Â© 2024")`, want: true},
+		{line: `// la tâche est finie`, want: false},
+	}
+
+	for _, tt := range tests {
+		if _, got := findMojibake(tt.line); got != tt.want {
+			t.Errorf("findMojibake(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// TestSourceHasNoMojibake guards against double-encoded UTF-8 creeping back
+// into source files, which shows up as garbage in place of emoji and symbols
+func TestSourceHasNoMojibake(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		t.Skipf("module root not found at %s", root)
+	}
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// This file spells out the sequences it looks for
+		if filepath.Base(path) == "encoding_test.go" {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".go", ".md", ".tmpl", ".yaml", ".yml":
+		default:
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			if seq, found := findMojibake(scanner.Text()); found {
+				rel, _ := filepath.Rel(root, path)
+				t.Errorf("%s:%d: double-encoded UTF-8 %q", rel, lineNum, seq)
+			}
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
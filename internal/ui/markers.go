@@ -0,0 +1,75 @@
+// Package ui holds the decorative markers printed in command output, so they
+// can be switched to plain ASCII for CI logs and terminals that can't render
+// emoji
+package ui
+
+import "sync/atomic"
+
+// plain is set when markers should render as ASCII
+var plain atomic.Bool
+
+// SetPlain switches every marker between emoji and plain ASCII
+func SetPlain(enabled bool) {
+	plain.Store(enabled)
+}
+
+// Plain reports whether markers render as plain ASCII
+func Plain() bool {
+	return plain.Load()
+}
+
+// Marker is a decorative prefix with an emoji and a plain ASCII form. It
+// implements fmt.Stringer, so it can be passed straight to the fmt functions.
+type Marker struct {
+	emoji string
+	ascii string
+}
+
+// String returns the form selected by SetPlain
+func (m Marker) String() string {
+	if Plain() {
+		return m.ascii
+	}
+	return m.emoji
+}
+
+// Outcome markers. Emoji drawn with a variation selector carry an extra space
+// because most terminals give them a single cell but draw them two wide.
+var (
+	Success   = Marker{"✅", "[ok]"}
+	Failure   = Marker{"❌", "[error]"}
+	Warning   = Marker{"⚠️ ", "[warn]"}
+	Unknown   = Marker{"❓", "[?]"}
+	Check     = Marker{"✓", "[ok]"}
+	Tip       = Marker{"💡", "[tip]"}
+	Celebrate = Marker{"🎉", "[done]"}
+)
+
+// Decorative markers for steps, sections and labelled values
+var (
+	Search    = Marker{"🔍", "*"}
+	Sync      = Marker{"🔄", "*"}
+	Robot     = Marker{"🤖", "*"}
+	Brain     = Marker{"🧠", "*"}
+	Save      = Marker{"💾", "*"}
+	Rocket    = Marker{"🚀", "*"}
+	Key       = Marker{"🔑", "*"}
+	Package   = Marker{"📦", "*"}
+	Globe     = Marker{"🌐", "*"}
+	Merge     = Marker{"🔀", "*"}
+	Branch    = Marker{"🌿", "*"}
+	Empty     = Marker{"📭", "*"}
+	Note      = Marker{"📝", "*"}
+	Clipboard = Marker{"📋", "*"}
+	Link      = Marker{"🔗", "*"}
+	Folder    = Marker{"📁", "*"}
+	File      = Marker{"📄", "*"}
+	Chart     = Marker{"📊", "*"}
+	Books     = Marker{"📚", "*"}
+	Gear      = Marker{"⚙️ ", "*"}
+	Building  = Marker{"🏗️ ", "*"}
+	Label     = Marker{"🏷️ ", "*"}
+	People    = Marker{"👥", "*"}
+	Eyes      = Marker{"👀", "*"}
+	Bolt      = Marker{"⚡", "*"}
+)
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMarkerString(t *testing.T) {
+	t.Cleanup(func() { SetPlain(false) })
+
+	tests := []struct {
+		name  string
+		plain bool
+		want  string
+	}{
+		{name: "Emoji by default", plain: false, want: "✅ Changes staged"},
+		{name: "Plain ASCII", plain: true, want: "[ok] Changes staged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPlain(tt.plain)
+			if got := fmt.Sprintf("%s Changes staged", Success); got != tt.want {
+				t.Errorf("Sprintf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,21 @@ package types
 
 // Config represents the application configuration
 type Config struct {
+	// Version is the schema version of the config file, used by config migrate
+	Version   int            `yaml:"version,omitempty"`
 	AI        AIConfig       `yaml:"ai"`
 	Platforms PlatformConfig `yaml:"platforms"`
 	Templates TemplateConfig `yaml:"templates"`
 	Git       GitConfig      `yaml:"git"`
+	// TypeDefaults add labels and reviewers to the PRs/MRs of a change type
+	// (feature, bugfix, hotfix, refactor, docs, test or deps)
+	TypeDefaults map[string]TypeDefault `yaml:"type_defaults,omitempty"`
+}
+
+// TypeDefault is what create adds to every PR/MR of one change type
+type TypeDefault struct {
+	Labels    []string `yaml:"labels,omitempty"`
+	Reviewers []string `yaml:"reviewers,omitempty"`
 }
 
 // AIConfig contains AI service configuration
@@ -15,6 +26,11 @@ type AIConfig struct {
 	MaxTokens   int          `yaml:"max_tokens"`
 	Temperature float32      `yaml:"temperature"`
 	Claude      ClaudeConfig `yaml:"claude,omitempty"`
+	// ExtraFields asks the AI for additional response fields, mapped to a
+	// description of each; templates read them as {{.Custom.<name>}}
+	ExtraFields map[string]string `yaml:"extra_fields,omitempty"`
+	// RefineIterations caps the critique-and-improve rounds run by --refine
+	RefineIterations int `yaml:"refine_iterations,omitempty"`
 }
 
 // AIProvider represents different AI service providers
@@ -22,6 +38,8 @@ type AIProvider string
 
 const (
 	AIProviderClaude AIProvider = "claude"
+	// AIProviderNone describes changes deterministically, without an AI
+	AIProviderNone AIProvider = "none"
 )
 
 // ClaudeConfig contains Claude-specific configuration
@@ -32,11 +50,30 @@ type ClaudeConfig struct {
 	UseSession bool   `yaml:"use_session,omitempty"`
 }
 
-
 // PlatformConfig contains platform-specific settings
 type PlatformConfig struct {
 	GitHub GitHubConfig `yaml:"github"`
 	GitLab GitLabConfig `yaml:"gitlab"`
+	Labels LabelConfig  `yaml:"labels"`
+	// TitleMaxLength caps PR/MR titles in characters; longer titles are cut
+	// at a word with an ellipsis and the rest moves to the description. A
+	// negative value turns the limit off.
+	TitleMaxLength int `yaml:"title_max_length"`
+}
+
+// LabelConfig contains the labels added to every PR/MR on either platform
+type LabelConfig struct {
+	// SizeThresholds label PRs/MRs by their changed lines: the first
+	// threshold the count stays below applies. An explicit empty list turns
+	// size labels off.
+	SizeThresholds []SizeThreshold `yaml:"size_thresholds"`
+}
+
+// SizeThreshold is a size label and the changed lines a PR/MR must stay
+// below to get it; a threshold without Below takes every size
+type SizeThreshold struct {
+	Label string `yaml:"label"`
+	Below int    `yaml:"below,omitempty"`
 }
 
 // GitHubConfig contains GitHub-specific settings
@@ -46,6 +83,8 @@ type GitHubConfig struct {
 	Draft            bool     `yaml:"draft"`
 	AutoMerge        bool     `yaml:"auto_merge"`
 	DeleteBranch     bool     `yaml:"delete_branch"`
+	// UseCodeowners adds the CODEOWNERS owners of the changed files as reviewers
+	UseCodeowners bool `yaml:"use_codeowners,omitempty"`
 }
 
 // GitLabConfig contains GitLab-specific settings
@@ -53,6 +92,8 @@ type GitLabConfig struct {
 	DefaultAssignee           string `yaml:"default_assignee"`
 	MergeWhenPipelineSucceeds bool   `yaml:"merge_when_pipeline_succeeds"`
 	RemoveSourceBranch        bool   `yaml:"remove_source_branch"`
+	// UseCodeowners adds the CODEOWNERS owners of the changed files as assignees
+	UseCodeowners bool `yaml:"use_codeowners,omitempty"`
 }
 
 // TemplateConfig contains template-related settings
@@ -60,14 +101,55 @@ type TemplateConfig struct {
 	Feature           string `yaml:"feature"`
 	Bugfix            string `yaml:"bugfix"`
 	CustomTemplateDir string `yaml:"custom_templates_dir"`
+	// PathRules pick the template and add labels for PRs/MRs changing files
+	// under certain paths; the first rule matching a changed file wins
+	PathRules []PathRule `yaml:"path_rules,omitempty"`
+}
+
+// PathRule applies a template and labels when a changed file matches Match,
+// a glob such as "services/payments/**"
+type PathRule struct {
+	Match    string   `yaml:"match"`
+	Template string   `yaml:"template,omitempty"`
+	Labels   []string `yaml:"labels,omitempty"`
 }
 
 // GitConfig contains git-related settings
 type GitConfig struct {
-	CommitLimit    int      `yaml:"commit_limit"`
-	DiffContext    int      `yaml:"diff_context"`
-	IgnorePatterns []string `yaml:"ignore_patterns"`
-	MaxDiffSize    int      `yaml:"max_diff_size"`
+	CommitLimit     int      `yaml:"commit_limit"`
+	DiffContext     int      `yaml:"diff_context"`
+	IgnorePatterns  []string `yaml:"ignore_patterns"`
+	MaxDiffSize     int      `yaml:"max_diff_size"`
+	DetailedCommits bool     `yaml:"detailed_commits"`
+	CodeownersPath  string   `yaml:"codeowners_path"`
+	// ProtectedBranches are branch names or globs that ship and commit --push
+	// refuse to push to directly
+	ProtectedBranches []string `yaml:"protected_branches"`
+	// BaseBranchCandidates are the base branches tried, in order, when the
+	// remote doesn't name its default branch
+	BaseBranchCandidates []string `yaml:"base_branch_candidates"`
+	// TestCommand is the shell command ship --draft-until-ci runs to verify
+	// changes; when empty it is picked from the project type
+	TestCommand string `yaml:"test_command"`
+	// ExcludeCommitAuthors and ExcludeCommitPatterns leave commits out of the
+	// history given to the AI: authors whose "Name <email>" contains an entry,
+	// and subjects matching a regular expression
+	ExcludeCommitAuthors  []string `yaml:"exclude_commit_authors"`
+	ExcludeCommitPatterns []string `yaml:"exclude_commit_patterns"`
+	// ContextExcludePatterns are files, such as lockfiles, whose changes are
+	// committed as usual but whose diffs are left out of what the AI reads;
+	// patterns match like IgnorePatterns
+	ContextExcludePatterns []string `yaml:"context_exclude_patterns"`
+	// IncludeGenerated keeps files marked linguist-generated in .gitattributes
+	// in the file changes given to the AI
+	IncludeGenerated bool `yaml:"include_generated"`
+	// CommitPromptTemplate replaces the built-in commit message prompt: a
+	// file path or the template itself, with {{.Diff}}, {{.Files}},
+	// {{.Summary}} and {{.Branch}} placeholders
+	CommitPromptTemplate string `yaml:"commit_prompt_template,omitempty"`
+	// AutoPush says whether commit and ship push when neither --push nor
+	// --no-push is given; unset, commit doesn't push and ship does
+	AutoPush *bool `yaml:"auto_push,omitempty"`
 }
 
 // PlatformType represents different git platforms
@@ -6,25 +6,46 @@ import "time"
 type GitStatus struct {
 	IsGitRepo      bool
 	CurrentBranch  string
+	DetachedHead   bool   // HEAD points at a commit rather than a branch
+	HeadCommit     string // Commit hash HEAD points at when detached
 	BaseBranch     string
 	RemoteURL      string
 	HasChanges     bool
 	StagedFiles    []string
 	UnstagedFiles  []string
 	UntrackedFiles []string
-	CommitsAhead   int
-	CommitsBehind  int
+	// UnmergedFiles have conflicts left by a merge, rebase or cherry-pick to
+	// resolve; they aren't listed as staged or unstaged
+	UnmergedFiles []string
+	// Entries describes every changed path, with rename sources and conflicts
+	Entries       []StatusEntry
+	CommitsAhead  int
+	CommitsBehind int
+}
+
+// StatusEntry describes one changed path in the index or work tree
+type StatusEntry struct {
+	Path      string
+	OrigPath  string       // Path before a rename or copy
+	Staged    ChangeStatus // Change in the index, empty when there is none
+	Unstaged  ChangeStatus // Change in the work tree, empty when there is none
+	Score     int          // Similarity percentage of a rename or copy
+	Conflict  bool         // Unmerged path left by a merge, rebase or cherry-pick
+	Submodule bool
 }
 
 // CommitInfo represents information about a single commit
 type CommitInfo struct {
 	Hash    string
 	Message string
+	Body    string // Message after the subject line, trailers included
 	Author  string
 	Email   string
 	Date    time.Time
 	Files   []string
 	Diff    string
+	// Trailers maps trailer keys such as "Refs" or "Reviewed-by" to their values
+	Trailers map[string][]string
 }
 
 // FileChange represents a change to a single file
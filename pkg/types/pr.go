@@ -19,6 +19,16 @@ type PullRequest struct {
 	UpdatedAt  string
 }
 
+// Issue is an issue or work item that a pull request addresses
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	State  string
+	URL    string
+	Labels []string
+}
+
 // PRState represents the state of a pull request
 type PRState string
 
@@ -34,6 +44,7 @@ type PullRequestRequest struct {
 	Title            string
 	Body             string
 	HeadBranch       string
+	HeadRepo         string // owner/repo of a fork holding HeadBranch; empty for same-repo PRs
 	BaseBranch       string
 	Draft            bool
 	Reviewers        []string
@@ -44,6 +55,13 @@ type PullRequestRequest struct {
 	DeleteHeadBranch bool
 }
 
+// PullRequestUpdate represents a request to update an existing pull request.
+// Empty fields are left unchanged.
+type PullRequestUpdate struct {
+	Title string
+	Body  string
+}
+
 // PRTemplate represents a template for generating pull requests
 type PRTemplate struct {
 	Name        string